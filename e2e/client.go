@@ -4,26 +4,46 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
 
 	"agent-dev-environment/src/api/v1"
+	archive_models "agent-dev-environment/src/api/v1/filesystem/archive"
+	batch_models "agent-dev-environment/src/api/v1/filesystem/batch"
+	changes_models "agent-dev-environment/src/api/v1/filesystem/changes"
 	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
 	chdir_models "agent-dev-environment/src/api/v1/filesystem/chdir"
+	codemod_models "agent-dev-environment/src/api/v1/filesystem/codemod"
+	copy_models "agent-dev-environment/src/api/v1/filesystem/copy"
 	getwd_models "agent-dev-environment/src/api/v1/filesystem/getwd"
+	link_models "agent-dev-environment/src/api/v1/filesystem/link"
 	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
 	ls_models "agent-dev-environment/src/api/v1/filesystem/ls"
 	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
 	move_models "agent-dev-environment/src/api/v1/filesystem/move"
+	raw_models "agent-dev-environment/src/api/v1/filesystem/raw"
 	read_models "agent-dev-environment/src/api/v1/filesystem/read"
 	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
 	search_models "agent-dev-environment/src/api/v1/filesystem/search"
+	search_index_models "agent-dev-environment/src/api/v1/filesystem/search_index"
+	trash_models "agent-dev-environment/src/api/v1/filesystem/trash"
+	tree_models "agent-dev-environment/src/api/v1/filesystem/tree"
+	usage_models "agent-dev-environment/src/api/v1/filesystem/usage"
+	watch_models "agent-dev-environment/src/api/v1/filesystem/watch"
+	journal_models "agent-dev-environment/src/api/v1/journal"
+	overview_models "agent-dev-environment/src/api/v1/project/overview"
+	scratch_models "agent-dev-environment/src/api/v1/scratch"
 	run_models "agent-dev-environment/src/api/v1/shell/run"
+	snapshot_models "agent-dev-environment/src/api/v1/snapshots"
+	symbols_models "agent-dev-environment/src/api/v1/symbols/search"
 )
 
 type Client struct {
 	BaseURL string
 	HTTP    *http.Client
+	Header  http.Header // Sent with every request
 }
 
 type APIError struct {
@@ -58,6 +78,10 @@ func (c *Client) Getwd() (*getwd_models.Response, error) {
 	return call[v1.EmptyResponse, getwd_models.Response](c, "POST", "/api/v1/filesystem/getwd", v1.EmptyResponse{})
 }
 
+func (c *Client) Usage() (*usage_models.Response, error) {
+	return call[v1.EmptyResponse, usage_models.Response](c, "POST", "/api/v1/filesystem/usage", v1.EmptyResponse{})
+}
+
 func (c *Client) Mkdir(req mkdir_models.Request) (*v1.EmptyResponse, error) {
 	return call[mkdir_models.Request, v1.EmptyResponse](c, "POST", "/api/v1/filesystem/mkdir", req)
 }
@@ -66,34 +90,250 @@ func (c *Client) ReadFile(req read_models.Request) (*read_models.Response, error
 	return call[read_models.Request, read_models.Response](c, "POST", "/api/v1/filesystem/read", req)
 }
 
-func (c *Client) DeleteFile(req delete_models.Request) (*v1.EmptyResponse, error) {
-	return call[delete_models.Request, v1.EmptyResponse](c, "POST", "/api/v1/filesystem/delete", req)
+func (c *Client) DeleteFile(req delete_models.Request) (*delete_models.Response, error) {
+	return call[delete_models.Request, delete_models.Response](c, "POST", "/api/v1/filesystem/delete", req)
+}
+
+func (c *Client) TrashList() (*trash_models.ListResponse, error) {
+	return call[v1.EmptyResponse, trash_models.ListResponse](c, "POST", "/api/v1/filesystem/trash/list", v1.EmptyResponse{})
+}
+
+func (c *Client) TrashRestore(req trash_models.RestoreRequest) (*trash_models.RestoreResponse, error) {
+	return call[trash_models.RestoreRequest, trash_models.RestoreResponse](c, "POST", "/api/v1/filesystem/trash/restore", req)
+}
+
+func (c *Client) TrashPurge(req trash_models.PurgeRequest) (*trash_models.PurgeResponse, error) {
+	return call[trash_models.PurgeRequest, trash_models.PurgeResponse](c, "POST", "/api/v1/filesystem/trash/purge", req)
+}
+
+func (c *Client) MoveFile(req move_models.Request) (*move_models.Response, error) {
+	return call[move_models.Request, move_models.Response](c, "POST", "/api/v1/filesystem/move", req)
+}
+
+func (c *Client) CopyFile(req copy_models.Request) (*copy_models.Response, error) {
+	return call[copy_models.Request, copy_models.Response](c, "POST", "/api/v1/filesystem/copy", req)
+}
+
+func (c *Client) Batch(req batch_models.Request) (*batch_models.Response, error) {
+	return call[batch_models.Request, batch_models.Response](c, "POST", "/api/v1/filesystem/batch", req)
+}
+
+func (c *Client) Changes(req changes_models.Request) (*changes_models.Response, error) {
+	return call[changes_models.Request, changes_models.Response](c, "POST", "/api/v1/filesystem/changes", req)
+}
+
+func (c *Client) LinkCreate(req link_models.CreateRequest) (*link_models.Link, error) {
+	return call[link_models.CreateRequest, link_models.Link](c, "POST", "/api/v1/filesystem/link/create", req)
 }
 
-func (c *Client) MoveFile(req move_models.Request) (*v1.EmptyResponse, error) {
-	return call[move_models.Request, v1.EmptyResponse](c, "POST", "/api/v1/filesystem/move", req)
+func (c *Client) LinkRead(req link_models.ReadRequest) (*link_models.Link, error) {
+	return call[link_models.ReadRequest, link_models.Link](c, "POST", "/api/v1/filesystem/link/read", req)
 }
 
-func (c *Client) ListFiles(req ls_models.Request) (*v1.CommandResponse, error) {
-	return call[ls_models.Request, v1.CommandResponse](c, "POST", "/api/v1/filesystem/ls", req)
+func (c *Client) ListFiles(req ls_models.Request) (*ls_models.Response, error) {
+	return call[ls_models.Request, ls_models.Response](c, "POST", "/api/v1/filesystem/ls", req)
 }
 
 func (c *Client) Search(req search_models.Request) (*v1.CommandResponse, error) {
 	return call[search_models.Request, v1.CommandResponse](c, "POST", "/api/v1/filesystem/search", req)
 }
 
+func (c *Client) SearchIndexStatus() (*search_index_models.StatusResponse, error) {
+	return call[v1.EmptyResponse, search_index_models.StatusResponse](c, "POST", "/api/v1/filesystem/search_index/status", v1.EmptyResponse{})
+}
+
 func (c *Client) Replace(req replace_models.Request) (*replace_models.Response, error) {
 	return call[replace_models.Request, replace_models.Response](c, "POST", "/api/v1/filesystem/replace", req)
 }
 
-func (c *Client) RunShell(req run_models.Request) (*v1.CommandResponse, error) {
-	return call[run_models.Request, v1.CommandResponse](c, "POST", "/api/v1/shell/run", req)
+func (c *Client) Tree(req tree_models.Request) (*tree_models.Response, error) {
+	return call[tree_models.Request, tree_models.Response](c, "POST", "/api/v1/filesystem/tree", req)
+}
+
+func (c *Client) CodemodPreview(req codemod_models.PreviewRequest) (*codemod_models.PreviewResponse, error) {
+	return call[codemod_models.PreviewRequest, codemod_models.PreviewResponse](c, "POST", "/api/v1/filesystem/codemod/preview", req)
+}
+
+func (c *Client) CodemodApply(req codemod_models.ApplyRequest) (*codemod_models.ApplyResponse, error) {
+	return call[codemod_models.ApplyRequest, codemod_models.ApplyResponse](c, "POST", "/api/v1/filesystem/codemod/apply", req)
+}
+
+func (c *Client) JournalList(req journal_models.ListRequest) (*journal_models.ListResponse, error) {
+	return call[journal_models.ListRequest, journal_models.ListResponse](c, "POST", "/api/v1/journal/list", req)
+}
+
+func (c *Client) JournalUndo(req journal_models.UndoRequest) (*journal_models.UndoResponse, error) {
+	return call[journal_models.UndoRequest, journal_models.UndoResponse](c, "POST", "/api/v1/journal/undo", req)
+}
+
+func (c *Client) SnapshotCreate(req snapshot_models.CreateRequest) (*snapshot_models.Snapshot, error) {
+	return call[snapshot_models.CreateRequest, snapshot_models.Snapshot](c, "POST", "/api/v1/snapshots/create", req)
+}
+
+func (c *Client) SnapshotList(req snapshot_models.ListRequest) (*snapshot_models.ListResponse, error) {
+	return call[snapshot_models.ListRequest, snapshot_models.ListResponse](c, "POST", "/api/v1/snapshots/list", req)
+}
+
+func (c *Client) SnapshotRestore(req snapshot_models.RestoreRequest) (*snapshot_models.RestoreResponse, error) {
+	return call[snapshot_models.RestoreRequest, snapshot_models.RestoreResponse](c, "POST", "/api/v1/snapshots/restore", req)
+}
+
+func (c *Client) SnapshotDelete(req snapshot_models.DeleteRequest) (*v1.EmptyResponse, error) {
+	return call[snapshot_models.DeleteRequest, v1.EmptyResponse](c, "POST", "/api/v1/snapshots/delete", req)
+}
+
+func (c *Client) ScratchCreate(req scratch_models.CreateRequest) (*scratch_models.Dir, error) {
+	return call[scratch_models.CreateRequest, scratch_models.Dir](c, "POST", "/api/v1/scratch/create", req)
+}
+
+func (c *Client) ScratchList() (*scratch_models.ListResponse, error) {
+	return call[v1.EmptyResponse, scratch_models.ListResponse](c, "POST", "/api/v1/scratch/list", v1.EmptyResponse{})
+}
+
+func (c *Client) ScratchDelete(req scratch_models.DeleteRequest) (*v1.EmptyResponse, error) {
+	return call[scratch_models.DeleteRequest, v1.EmptyResponse](c, "POST", "/api/v1/scratch/delete", req)
+}
+
+func (c *Client) EndSession() (*v1.EmptyResponse, error) {
+	return call[v1.EmptyResponse, v1.EmptyResponse](c, "POST", "/api/v1/session/end", v1.EmptyResponse{})
+}
+
+func (c *Client) SearchSymbols(req symbols_models.Request) (*symbols_models.Response, error) {
+	return call[symbols_models.Request, symbols_models.Response](c, "POST", "/api/v1/symbols/search", req)
+}
+
+func (c *Client) ProjectOverview(req overview_models.Request) (*overview_models.Response, error) {
+	return call[overview_models.Request, overview_models.Response](c, "POST", "/api/v1/project/overview", req)
+}
+
+func (c *Client) RunShell(req run_models.Request) (*run_models.Response, error) {
+	return call[run_models.Request, run_models.Response](c, "POST", "/api/v1/shell/run", req)
 }
 
 func (c *Client) ReloadEnv() (*v1.CommandResponse, error) {
 	return call[v1.EmptyResponse, v1.CommandResponse](c, "POST", "/api/v1/shell/reload_env", v1.EmptyResponse{})
 }
 
+func (c *Client) ArchiveExport(req archive_models.ExportRequest) ([]byte, error) {
+	_, body, err := c.stream("GET", "/api/v1/filesystem/archive/export", req, nil)
+	return body, err
+}
+
+func (c *Client) ArchiveImport(req archive_models.ImportRequest, archive []byte) (*archive_models.ImportResponse, error) {
+	_, body, err := c.stream("POST", "/api/v1/filesystem/archive/import", req, bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	var res archive_models.ImportResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// RawDownload returns the response along with its body, so callers can
+// inspect status codes and headers such as Content-Range.
+func (c *Client) RawDownload(req raw_models.DownloadRequest) (*http.Response, []byte, error) {
+	return c.stream("GET", "/api/v1/filesystem/raw", req, nil)
+}
+
+func (c *Client) RawUpload(req raw_models.UploadRequest, data []byte) (*raw_models.UploadResponse, error) {
+	_, body, err := c.stream("PUT", "/api/v1/filesystem/raw", req, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var res raw_models.UploadResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) Watch(req watch_models.Request) (*EventStream, error) {
+	resp, err := c.open("GET", "/api/v1/filesystem/watch", req, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newEventStream(resp), nil
+}
+
+// stream calls an endpoint that takes its parameters from the query string
+// and returns the raw response body.
+func (c *Client) stream(method, path string, params any, body io.Reader) (*http.Response, []byte, error) {
+	resp, err := c.open(method, path, params, body)
+	if err != nil {
+		return resp, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	return resp, data, err
+}
+
+// open is stream without reading the body, which the caller must close.
+func (c *Client) open(method, path string, params any, body io.Reader) (*http.Response, error) {
+	query, err := queryOf(params)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, c.BaseURL+path+"?"+query.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return resp, decodeError(resp)
+	}
+	return resp, nil
+}
+
+// queryOf encodes the non-empty fields of a request as query parameters
+// named by their json tags.
+func queryOf(params any) (url.Values, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	for key, value := range fields {
+		if values, ok := value.([]any); ok {
+			for _, v := range values {
+				query.Add(key, fmt.Sprint(v))
+			}
+		} else if value != nil && value != "" {
+			query.Set(key, fmt.Sprint(value))
+		}
+	}
+	return query, nil
+}
+
+func decodeError(resp *http.Response) error {
+	var errRes v1.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errRes); err != nil {
+		return &APIError{
+			Status:  resp.StatusCode,
+			Message: fmt.Sprintf("could not decode error response: %v", err),
+		}
+	}
+	return &APIError{
+		Status:  resp.StatusCode,
+		Message: errRes.Error,
+	}
+}
+
 func call[Req any, Res any](c *Client, method, path string, payload Req) (*Res, error) {
 	url := c.BaseURL + path
 	body, err := json.Marshal(payload)
@@ -106,6 +346,9 @@ func call[Req any, Res any](c *Client, method, path string, payload Req) (*Res,
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
 
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
@@ -114,17 +357,7 @@ func call[Req any, Res any](c *Client, method, path string, payload Req) (*Res,
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		var errRes v1.ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errRes); err != nil {
-			return nil, &APIError{
-				Status:  resp.StatusCode,
-				Message: fmt.Sprintf("could not decode error response: %v", err),
-			}
-		}
-		return nil, &APIError{
-			Status:  resp.StatusCode,
-			Message: errRes.Error,
-		}
+		return nil, decodeError(resp)
 	}
 
 	var res Res
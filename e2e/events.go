@@ -0,0 +1,62 @@
+package e2e
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// StreamEvent is one server-sent event.
+type StreamEvent struct {
+	Name string
+	Data string
+}
+
+// EventStream reads the server-sent events of a streaming endpoint in the
+// background.
+type EventStream struct {
+	resp   *http.Response
+	events chan StreamEvent
+}
+
+func newEventStream(resp *http.Response) *EventStream {
+	s := &EventStream{resp: resp, events: make(chan StreamEvent, 256)}
+	go s.read()
+	return s
+}
+
+func (s *EventStream) read() {
+	defer close(s.events)
+	scanner := bufio.NewScanner(s.resp.Body)
+	var event StreamEvent
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event.Name != "" || event.Data != "" {
+				s.events <- event
+			}
+			event = StreamEvent{}
+		case strings.HasPrefix(line, "event: "):
+			event.Name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.Data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// Next returns the next event, or false if none arrives within timeout or
+// the stream ended.
+func (s *EventStream) Next(timeout time.Duration) (StreamEvent, bool) {
+	select {
+	case event, ok := <-s.events:
+		return event, ok
+	case <-time.After(timeout):
+		return StreamEvent{}, false
+	}
+}
+
+func (s *EventStream) Close() error {
+	return s.resp.Body.Close()
+}
@@ -0,0 +1,284 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	archive_models "agent-dev-environment/src/api/v1/filesystem/archive"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	link_models "agent-dev-environment/src/api/v1/filesystem/link"
+	ls_models "agent-dev-environment/src/api/v1/filesystem/ls"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	journal_models "agent-dev-environment/src/api/v1/journal"
+)
+
+type entry struct {
+	name string
+	body string
+	link string // Symlink target
+}
+
+func tarGz(t *testing.T, entries ...entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+		if e.link != "" {
+			hdr = &tar.Header{Name: e.name, Mode: 0o777, Typeflag: tar.TypeSymlink, Linkname: e.link}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("failed to build archive: %v", err)
+		}
+		tw.Write([]byte(e.body))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func zipNames(t *testing.T, data []byte) []string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("failed to open exported zip: %v", err)
+	}
+	names := []string{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestArchive_RoundTrip(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_archive_round_trip")
+	source := filepath.Join(testDir, "source")
+	destination := filepath.Join(testDir, "destination")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(source, "README.md"), Content: "# Project"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(source, "src", "main.go"), Content: "package main"})
+	client.LinkCreate(link_models.CreateRequest{Path: filepath.Join(source, "main.go"), Target: "src/main.go"})
+
+	// -------------------------------------- Act --------------------------------------
+	data, err := client.ArchiveExport(archive_models.ExportRequest{Path: source})
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	res, err := client.ArchiveImport(archive_models.ImportRequest{Path: destination}, data)
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Files != 2 || res.Dirs != 1 || res.Symlinks != 1 || res.Bytes != int64(len("# Project")+len("package main")) {
+		t.Errorf("unexpected import stats: %+v", res)
+	}
+
+	read, err := client.ReadFile(read_models.Request{Path: filepath.Join(destination, "main.go")})
+	if err != nil || read.Content != "package main" || read.SymlinkTarget != "src/main.go" {
+		t.Errorf("expected the symlink to be restored, got %+v, %v", read, err)
+	}
+	ls, err := client.ListFiles(ls_models.Request{Path: destination})
+	if err != nil || len(ls.Entries) != 3 {
+		t.Errorf("expected only the archived entries, got %+v, %v", ls, err)
+	}
+}
+
+func TestArchive_ExportZipExcludingIgnored(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_archive_export_zip")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, ".gitignore"), Content: "build/\n"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "main.go"), Content: "package main"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "build", "app"), Content: "binary"})
+
+	// -------------------------------------- Act --------------------------------------
+	data, err := client.ArchiveExport(archive_models.ExportRequest{Path: testDir, Format: "zip", ExcludeIgnored: true})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	names := zipNames(t, data)
+	if len(names) != 2 || names[0] != ".gitignore" || names[1] != "main.go" {
+		t.Errorf("expected ignored files to be left out, got %v", names)
+	}
+
+	copied := filepath.Join(testDir, "copied")
+	if _, err := client.ArchiveImport(archive_models.ImportRequest{Path: copied, Format: "zip"}, data); err != nil {
+		t.Fatalf("failed to import the zip: %v", err)
+	}
+	read, err := client.ReadFile(read_models.Request{Path: filepath.Join(copied, "main.go")})
+	if err != nil || read.Content != "package main" {
+		t.Errorf("expected the zip to unpack, got %v, %v", read, err)
+	}
+}
+
+func TestArchive_ExportNotFound(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.ArchiveExport(archive_models.ExportRequest{Path: filepath.Join(e2e.TestDir, "test_archive_missing")})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusNotFound, "Path not found")
+}
+
+func TestArchive_ImportZipSlip(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_archive_zip_slip")
+	destination := filepath.Join(testDir, "destination")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	data := tarGz(t, entry{name: "ok.txt", body: "fine"}, entry{name: "../evil.txt", body: "escaped"})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.ArchiveImport(archive_models.ImportRequest{Path: destination}, data)
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusBadRequest, `Archive entry "../evil.txt" leads outside the target directory`)
+
+	if _, err := client.ListFiles(ls_models.Request{Path: destination}); err == nil {
+		t.Error("expected nothing to be unpacked")
+	}
+	if _, err := client.ReadFile(read_models.Request{Path: filepath.Join(testDir, "evil.txt")}); err == nil {
+		t.Error("expected the escaping entry not to be written")
+	}
+}
+
+func TestArchive_ImportSymlinkEscape(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_archive_symlink_escape")
+	destination := filepath.Join(testDir, "destination")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	cases := map[string][]entry{
+		"write through link": {{name: "out", link: "../outside"}, {name: "out/file.txt", body: "escaped"}},
+		"chained links":      {{name: "here", link: "."}, {name: "up", link: "here/.."}},
+		"absolute target":    {{name: "etc", link: "/etc"}},
+	}
+
+	for name, entries := range cases {
+		t.Run(name, func(t *testing.T) {
+			// -------------------------------------- Act --------------------------------------
+			_, err := client.ArchiveImport(archive_models.ImportRequest{Path: destination}, tarGz(t, entries...))
+
+			// ------------------------------------ Assert -------------------------------------
+			var apiErr *e2e.APIError
+			if !errors.As(err, &apiErr) || apiErr.Status != http.StatusBadRequest {
+				t.Fatalf("expected the archive to be rejected, got %v", err)
+			}
+			if _, err := client.ListFiles(ls_models.Request{Path: testDir}); err == nil {
+				t.Error("expected nothing to be unpacked")
+			}
+		})
+	}
+}
+
+func TestArchive_ImportConflict(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_archive_conflict")
+	existing := filepath.Join(testDir, "config.yaml")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: existing, Content: "old"})
+	data := tarGz(t, entry{name: "new.txt", body: "new"}, entry{name: "config.yaml", body: "replaced"})
+
+	// -------------------------------------- Act --------------------------------------
+	_, conflict := client.ArchiveImport(archive_models.ImportRequest{Path: testDir}, data)
+	_, err := client.ArchiveImport(archive_models.ImportRequest{Path: testDir, Overwrite: true}, data)
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, conflict, http.StatusConflict, `Path "`+existing+`" already exists`)
+
+	if err != nil {
+		t.Fatalf("expected the overwriting import to succeed, got %v", err)
+	}
+	read, err := client.ReadFile(read_models.Request{Path: existing})
+	if err != nil || read.Content != "replaced" {
+		t.Errorf("expected the file to be replaced, got %v, %v", read, err)
+	}
+}
+
+func TestArchive_ImportCanBeUndone(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_archive_undo")
+	existing := filepath.Join(testDir, "config.yaml")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: existing, Content: "old"})
+	data := tarGz(t, entry{name: "config.yaml", body: "replaced"}, entry{name: "sub/new.txt", body: "new"})
+	if _, err := client.ArchiveImport(archive_models.ImportRequest{Path: testDir, Overwrite: true}, data); err != nil {
+		t.Fatalf("Failed to arrange: %v", err)
+	}
+	history, err := client.JournalList(journal_models.ListRequest{Path: testDir, Limit: 1})
+	if err != nil || len(history.Entries) != 1 || history.Entries[0].Op != "import" {
+		t.Fatalf("Failed to arrange: expected an import entry, got %+v, %v", history, err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.JournalUndo(journal_models.UndoRequest{ID: history.Entries[0].ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected the import to be undone, got %v", err)
+	}
+	read, err := client.ReadFile(read_models.Request{Path: existing})
+	if err != nil || read.Content != "old" {
+		t.Errorf("expected the overwritten file to come back, got %v, %v", read, err)
+	}
+	if _, err := client.ListFiles(ls_models.Request{Path: filepath.Join(testDir, "sub")}); err == nil {
+		t.Error("expected the imported directory to be removed")
+	}
+}
+
+func TestArchive_ImportTooLarge(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_archive_too_large")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// Compresses to a few kilobytes, like an archive bomb
+	data := tarGz(t, entry{name: "zeros.bin", body: string(make([]byte, 11<<20))})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.ArchiveImport(archive_models.ImportRequest{Path: testDir}, data)
+
+	// ------------------------------------ Assert -------------------------------------
+	if err == nil {
+		t.Skip("server allows archives over 10 MiB; scripts/run-e2e.sh sets AGENT_DEV_ENVIRONMENT_ARCHIVE_MAX_BYTES")
+	}
+	e2e.AssertError(t, err, http.StatusRequestEntityTooLarge, "Archive exceeds the limit of 10485760 bytes")
+
+	if _, err := client.ListFiles(ls_models.Request{Path: testDir}); err == nil {
+		t.Error("expected nothing to be unpacked")
+	}
+}
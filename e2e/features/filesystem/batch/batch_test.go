@@ -0,0 +1,168 @@
+package batch_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	batch_models "agent-dev-environment/src/api/v1/filesystem/batch"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	ls_models "agent-dev-environment/src/api/v1/filesystem/ls"
+	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
+	move_models "agent-dev-environment/src/api/v1/filesystem/move"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
+)
+
+func operation(t *testing.T, op string, req any) batch_models.Operation {
+	t.Helper()
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("failed to encode %s request: %v", op, err)
+	}
+	return batch_models.Operation{Op: op, Request: body}
+}
+
+func statuses(res *batch_models.Response) []string {
+	result := []string{}
+	for _, r := range res.Results {
+		result = append(result, r.Status)
+	}
+	return result
+}
+
+func TestBatch_Scaffold(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_batch_scaffold")
+	main := filepath.Join(testDir, "cmd", "main.go")
+	draft := filepath.Join(testDir, "draft.go")
+	handler := filepath.Join(testDir, "internal", "handler.go")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// -------------------------------------- Act --------------------------------------
+	res, err := client.Batch(batch_models.Request{Operations: []batch_models.Operation{
+		operation(t, "mkdir", mkdir_models.Request{Path: filepath.Join(testDir, "internal")}),
+		operation(t, "create_file", create_models.Request{Path: main, Content: "package main\n"}),
+		operation(t, "create_file", create_models.Request{Path: draft, Content: "package draft\n"}),
+		operation(t, "replace", replace_models.Request{Path: draft, OldString: "package draft", NewString: "package internal"}),
+		operation(t, "move", move_models.Request{Source: draft, Destination: handler}),
+	}})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Succeeded != 5 || res.Failed != 0 || res.Skipped != 0 {
+		t.Fatalf("expected every operation to succeed, got %+v", res)
+	}
+
+	var moved move_models.Response
+	if err := json.Unmarshal(res.Results[4].Response, &moved); err != nil || moved.Destination != handler {
+		t.Errorf("expected the move's own response, got %s", res.Results[4].Response)
+	}
+
+	read, err := client.ReadFile(read_models.Request{Path: handler})
+	if err != nil || read.Content != "package internal" {
+		t.Errorf("expected the operations to apply in order, got %v, %v", read, err)
+	}
+	if _, err := client.ReadFile(read_models.Request{Path: main}); err != nil {
+		t.Errorf("expected %s to be created, got %v", main, err)
+	}
+}
+
+func TestBatch_StopsOnFirstError(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_batch_stop")
+	file := filepath.Join(testDir, "file.txt")
+	later := filepath.Join(testDir, "later")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// -------------------------------------- Act --------------------------------------
+	res, err := client.Batch(batch_models.Request{Operations: []batch_models.Operation{
+		operation(t, "create_file", create_models.Request{Path: file, Content: "first"}),
+		operation(t, "create_file", create_models.Request{Path: file, Content: "second"}),
+		operation(t, "mkdir", mkdir_models.Request{Path: later}),
+	}})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	got := statuses(res)
+	if len(got) != 3 || got[0] != "ok" || got[1] != "error" || got[2] != "skipped" {
+		t.Fatalf("expected ok, error, skipped, got %v", got)
+	}
+	if res.Results[1].Code != http.StatusConflict || res.Results[1].Error != "File already exists" {
+		t.Errorf("expected the create_file error, got %+v", res.Results[1])
+	}
+
+	ls, err := client.ListFiles(ls_models.Request{Path: testDir})
+	if err != nil || len(ls.Entries) != 1 {
+		t.Errorf("expected only the first file, got %v, %v", ls, err)
+	}
+}
+
+func TestBatch_ContinueOnError(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_batch_continue")
+	later := filepath.Join(testDir, "later")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// -------------------------------------- Act --------------------------------------
+	res, err := client.Batch(batch_models.Request{ContinueOnError: true, Operations: []batch_models.Operation{
+		operation(t, "create_file", create_models.Request{Path: ""}),
+		operation(t, "replace", replace_models.Request{Path: filepath.Join(testDir, "missing.txt"), OldString: "a"}),
+		operation(t, "mkdir", mkdir_models.Request{Path: later}),
+	}})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Succeeded != 1 || res.Failed != 2 || res.Skipped != 0 {
+		t.Fatalf("expected two failures and one success, got %+v", res)
+	}
+	if res.Results[0].Code != http.StatusBadRequest || res.Results[0].Error != "Path is required" {
+		t.Errorf("expected the operation to be validated, got %+v", res.Results[0])
+	}
+	if res.Results[1].Code != http.StatusNotFound || res.Results[1].Error != "File not found" {
+		t.Errorf("expected the replace error, got %+v", res.Results[1])
+	}
+
+	if _, err := client.ListFiles(ls_models.Request{Path: later}); err != nil {
+		t.Errorf("expected the operation after the failures to run, got %v", err)
+	}
+}
+
+func TestBatch_UnknownOp(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.Batch(batch_models.Request{Operations: []batch_models.Operation{
+		{Op: "chmod", Request: json.RawMessage(`{}`)},
+	}})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusBadRequest, `Operation 0: unknown op "chmod"`)
+}
+
+func TestBatch_Empty(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.Batch(batch_models.Request{})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusBadRequest, "Operations are required")
+}
@@ -0,0 +1,127 @@
+package changes_test
+
+import (
+	"net/http"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	changes_models "agent-dev-environment/src/api/v1/filesystem/changes"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
+	run_models "agent-dev-environment/src/api/v1/shell/run"
+)
+
+func assertPaths(t *testing.T, kind string, got []string, want ...string) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %s %v, got %v", kind, want, got)
+	}
+}
+
+func TestChanges_API(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_changes_api")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "edited.txt"), Content: "v1"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "removed.txt"), Content: "bye"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "untouched.txt"), Content: "same"})
+	start, err := client.Changes(changes_models.Request{Path: testDir})
+	if err != nil {
+		t.Fatalf("failed to start tracking: %v", err)
+	}
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "src", "added.txt"), Content: "new"})
+	client.Replace(replace_models.Request{Path: filepath.Join(testDir, "edited.txt"), OldString: "v1", NewString: "v2"})
+	client.DeleteFile(delete_models.Request{Path: filepath.Join(testDir, "removed.txt")})
+
+	// -------------------------------------- Act --------------------------------------
+	res, err := client.Changes(changes_models.Request{Path: testDir, Cursor: start.Cursor})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(start.Created)+len(start.Modified)+len(start.Deleted) != 0 {
+		t.Errorf("expected no changes without a cursor, got %+v", start)
+	}
+	assertPaths(t, "created", res.Created, filepath.Join(testDir, "src", "added.txt"))
+	assertPaths(t, "modified", res.Modified, filepath.Join(testDir, "edited.txt"))
+	assertPaths(t, "deleted", res.Deleted, filepath.Join(testDir, "removed.txt"))
+
+	again, err := client.Changes(changes_models.Request{Path: testDir, Cursor: res.Cursor})
+	if err != nil || len(again.Created)+len(again.Modified)+len(again.Deleted) != 0 {
+		t.Errorf("expected no changes since the new cursor, got %+v, %v", again, err)
+	}
+}
+
+func TestChanges_ShellRun(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_changes_shell_run")
+	edited := filepath.Join(testDir, "edited.txt")
+	removed := filepath.Join(testDir, "removed.txt")
+	added := filepath.Join(testDir, "added.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: edited, Content: "v1"})
+	client.CreateFile(create_models.Request{Path: removed, Content: "bye"})
+	start, err := client.Changes(changes_models.Request{Path: testDir})
+	if err != nil {
+		t.Fatalf("failed to start tracking: %v", err)
+	}
+
+	// Same size and, on coarse clocks, possibly the same modification time
+	client.RunShell(run_models.Request{Command: "sed", Args: []string{"-i", "s/v1/v2/", edited}})
+	client.RunShell(run_models.Request{Command: "rm", Args: []string{removed}})
+	client.RunShell(run_models.Request{Command: "touch", Args: []string{added}})
+
+	// -------------------------------------- Act --------------------------------------
+	res, err := client.Changes(changes_models.Request{Path: testDir, Cursor: start.Cursor})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertPaths(t, "created", res.Created, added)
+	assertPaths(t, "modified", res.Modified, edited)
+	assertPaths(t, "deleted", res.Deleted, removed)
+}
+
+func TestChanges_UnknownCursor(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.Changes(changes_models.Request{Path: e2e.TestDir, Cursor: "20000101T000000.000000000-00000000"})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusGone, "Cursor not found or expired; start again without a cursor")
+}
+
+func TestChanges_CursorForDifferentPath(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_changes_different_path")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "a", "file.txt"), Content: "a"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "b", "file.txt"), Content: "b"})
+	start, err := client.Changes(changes_models.Request{Path: filepath.Join(testDir, "a")})
+	if err != nil {
+		t.Fatalf("failed to start tracking: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.Changes(changes_models.Request{Path: filepath.Join(testDir, "b"), Cursor: start.Cursor})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusBadRequest, "Cursor was issued for a different path")
+}
@@ -2,7 +2,6 @@ package chdir
 
 import (
 	"path/filepath"
-	"strings"
 	"testing"
 
 	"agent-dev-environment/e2e"
@@ -13,6 +12,15 @@ import (
 	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
 )
 
+func containsEntry(entries []ls_models.Entry, name string) bool {
+	for _, entry := range entries {
+		if entry.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func TestChdir_ChangesWorkingDirectory(t *testing.T) {
 	// ------------------------------------ Arrange ------------------------------------
 	client := e2e.NewClient()
@@ -60,8 +68,8 @@ func TestChdir_ChangesWorkingDirectory(t *testing.T) {
 		t.Fatalf("failed to list current directory: %v", err)
 	}
 
-	if !strings.Contains(lsRes.CommandOutput, markerFile) {
-		t.Errorf("expected output to contain %q, got: %s", markerFile, lsRes.CommandOutput)
+	if !containsEntry(lsRes.Entries, markerFile) {
+		t.Errorf("expected listing to contain %q, got: %+v", markerFile, lsRes.Entries)
 	}
 }
 
@@ -105,7 +113,7 @@ func TestChdir_AffectsSubsequentFileCreation(t *testing.T) {
 		t.Fatalf("failed to list current directory: %v", err)
 	}
 
-	if !strings.Contains(lsRes.CommandOutput, fileName) {
+	if !containsEntry(lsRes.Entries, fileName) {
 		t.Errorf("expected file %q to be listed in current directory, but it wasn't", fileName)
 	}
 }
@@ -0,0 +1,91 @@
+package chdir
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	chdir_models "agent-dev-environment/src/api/v1/filesystem/chdir"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	run_models "agent-dev-environment/src/api/v1/shell/run"
+)
+
+func sessionClient(id string) *e2e.Client {
+	client := e2e.NewClient()
+	client.Header = http.Header{"X-Session-ID": {id}}
+	return client
+}
+
+func TestChdir_SessionsKeepSeparateDirectories(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	first := sessionClient("chdir-session-first")
+	second := sessionClient("chdir-session-second")
+	testDir := filepath.Join(e2e.TestDir, "chdir_sessions_test")
+	firstDir := filepath.Join(testDir, "first")
+	secondDir := filepath.Join(testDir, "second")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.Mkdir(mkdir_models.Request{Path: firstDir})
+	client.Mkdir(mkdir_models.Request{Path: secondDir})
+	initialWd, err := client.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get initial working directory: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, errFirst := first.Chdir(chdir_models.Request{Path: firstDir})
+	_, errSecond := second.Chdir(chdir_models.Request{Path: secondDir})
+	_, errCreate := first.CreateFile(create_models.Request{Path: "note.txt", Content: "first"})
+
+	// ------------------------------------ Assert -------------------------------------
+	if errFirst != nil || errSecond != nil || errCreate != nil {
+		t.Fatalf("expected no errors, got %v, %v, %v", errFirst, errSecond, errCreate)
+	}
+
+	for _, tc := range []struct {
+		client *e2e.Client
+		want   string
+	}{{first, firstDir}, {second, secondDir}, {client, initialWd.Path}} {
+		wd, err := tc.client.Getwd()
+		if err != nil || wd.Path != tc.want {
+			t.Errorf("expected working directory %q, got %v, %v", tc.want, wd, err)
+		}
+	}
+
+	read, err := client.ReadFile(read_models.Request{Path: filepath.Join(firstDir, "note.txt")})
+	if err != nil || read.Content != "first" {
+		t.Errorf("expected relative path to resolve in the session's directory, got %v, %v", read, err)
+	}
+	if _, err := second.ReadFile(read_models.Request{Path: "note.txt"}); err == nil {
+		t.Error("expected note.txt not to exist in the other session's directory")
+	}
+}
+
+func TestChdir_RunUsesSessionDirectory(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := sessionClient("chdir-session-run")
+	testDir := filepath.Join(e2e.TestDir, "chdir_session_run_test")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "marker.txt"), Content: "here"})
+	client.Chdir(chdir_models.Request{Path: testDir})
+
+	// -------------------------------------- Act --------------------------------------
+	res, err := client.RunShell(run_models.Request{Command: "ls", Args: []string{}})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(res.Stdout, "marker.txt") {
+		t.Errorf("expected ls to run in the session's directory, got %q", res.Stdout)
+	}
+}
@@ -0,0 +1,319 @@
+package codemod
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	codemod_models "agent-dev-environment/src/api/v1/filesystem/codemod"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	ls_models "agent-dev-environment/src/api/v1/filesystem/ls"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
+	journal_models "agent-dev-environment/src/api/v1/journal"
+)
+
+func readContent(t *testing.T, client *e2e.Client, path string) string {
+	t.Helper()
+	resp, err := client.ReadFile(read_models.Request{Path: path})
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return resp.Content
+}
+
+func TestCodemod_PreviewAndApplyAll(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_codemod_apply_all")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	mainFile := filepath.Join(testDir, "main.go")
+	utilFile := filepath.Join(testDir, "pkg", "util.go")
+	client.CreateFile(create_models.Request{
+		Path:    mainFile,
+		Content: "package main\n\nfunc main() {\n\toldName(1)\n}\n",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    utilFile,
+		Content: "package pkg\n\nfunc oldName(n int) int {\n\treturn n\n}\n",
+	})
+
+	// -------------------------------------- Act --------------------------------------
+	preview, err := client.CodemodPreview(codemod_models.PreviewRequest{
+		Path:        testDir,
+		Pattern:     `old(Name)`,
+		Regex:       true,
+		Replacement: "new$1",
+	})
+	if err != nil {
+		t.Fatalf("expected no preview error, got %v", err)
+	}
+
+	applied, err := client.CodemodApply(codemod_models.ApplyRequest{
+		PreviewID: preview.PreviewID,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no apply error, got %v", err)
+	}
+
+	if preview.TotalChanges != 2 || preview.FilesAffected != 2 {
+		t.Errorf("expected 2 changes in 2 files, got %d in %d", preview.TotalChanges, preview.FilesAffected)
+	}
+	first := preview.Changes[0]
+	if first.Path != mainFile || first.Line != 4 || first.Column != 2 {
+		t.Errorf("expected first change at %s:4:2, got %s:%d:%d", mainFile, first.Path, first.Line, first.Column)
+	}
+	if first.Before != "\toldName(1)" || first.After != "\tnewName(1)" {
+		t.Errorf("unexpected before/after: %q -> %q", first.Before, first.After)
+	}
+	if !reflect.DeepEqual(first.ContextBefore, []string{"", "func main() {"}) || !reflect.DeepEqual(first.ContextAfter, []string{"}", ""}) {
+		t.Errorf("unexpected context: %q / %q", first.ContextBefore, first.ContextAfter)
+	}
+
+	if applied.Applied != 2 {
+		t.Errorf("expected 2 applied changes, got %d", applied.Applied)
+	}
+	if got := readContent(t, client, utilFile); got != "package pkg\n\nfunc newName(n int) int {\n\treturn n\n}" {
+		t.Errorf("unexpected content after apply: %q", got)
+	}
+}
+
+func TestCodemod_ApplySelectedChanges(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_codemod_apply_selected")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	testFile := filepath.Join(testDir, "list.txt")
+	client.CreateFile(create_models.Request{
+		Path:    testFile,
+		Content: "apple\nbanana\napple\n",
+	})
+
+	preview, err := client.CodemodPreview(codemod_models.PreviewRequest{
+		Path:        testDir,
+		Pattern:     "apple",
+		Replacement: "cherry",
+	})
+	if err != nil {
+		t.Fatalf("expected no preview error, got %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.CodemodApply(codemod_models.ApplyRequest{
+		PreviewID: preview.PreviewID,
+		ChangeIDs: []string{preview.Changes[1].ID},
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no apply error, got %v", err)
+	}
+
+	if got := readContent(t, client, testFile); got != "apple\nbanana\ncherry" {
+		t.Errorf("expected only the second match replaced, got %q", got)
+	}
+}
+
+func TestCodemod_ScopeGlobs(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_codemod_scope")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "a.go"), Content: "token\n"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "a.md"), Content: "token\n"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "vendor", "b.go"), Content: "token\n"})
+
+	// -------------------------------------- Act --------------------------------------
+	preview, err := client.CodemodPreview(codemod_models.PreviewRequest{
+		Path:        testDir,
+		Pattern:     "token",
+		Replacement: "renamed",
+		Include:     []string{"*.go"},
+		Exclude:     []string{"vendor/**"},
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(preview.Changes) != 1 || preview.Changes[0].Path != filepath.Join(testDir, "a.go") {
+		t.Errorf("expected a single change in a.go, got %+v", preview.Changes)
+	}
+}
+
+func TestCodemod_ConflictWhenFileChanged(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_codemod_conflict")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	testFile := filepath.Join(testDir, "file.txt")
+	client.CreateFile(create_models.Request{
+		Path:    testFile,
+		Content: "value = 1\nother = 2\n",
+	})
+
+	preview, err := client.CodemodPreview(codemod_models.PreviewRequest{
+		Path:        testDir,
+		Pattern:     "value",
+		Replacement: "setting",
+	})
+	if err != nil {
+		t.Fatalf("expected no preview error, got %v", err)
+	}
+
+	client.Replace(replace_models.Request{
+		Path:      testFile,
+		OldString: "other = 2",
+		NewString: "other = 3",
+	})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.CodemodApply(codemod_models.ApplyRequest{
+		PreviewID: preview.PreviewID,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, 409, "File changed since preview: "+testFile)
+
+	if got := readContent(t, client, testFile); got != "value = 1\nother = 3" {
+		t.Errorf("expected file to be left untouched, got %q", got)
+	}
+}
+
+func TestCodemod_ApplyCanBeUndone(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_codemod_undo")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	first := filepath.Join(testDir, "a.txt")
+	second := filepath.Join(testDir, "b.txt")
+	client.CreateFile(create_models.Request{Path: first, Content: "value = 1"})
+	client.CreateFile(create_models.Request{Path: second, Content: "value = 2"})
+
+	preview, err := client.CodemodPreview(codemod_models.PreviewRequest{
+		Path:        testDir,
+		Pattern:     "value",
+		Replacement: "setting",
+	})
+	if err != nil {
+		t.Fatalf("Failed to arrange: %v", err)
+	}
+	if _, err := client.CodemodApply(codemod_models.ApplyRequest{PreviewID: preview.PreviewID}); err != nil {
+		t.Fatalf("Failed to arrange: %v", err)
+	}
+	history, err := client.JournalList(journal_models.ListRequest{Path: testDir, Limit: 1})
+	if err != nil || len(history.Entries) != 1 || history.Entries[0].Op != "codemod" || len(history.Entries[0].Changes) != 2 {
+		t.Fatalf("Failed to arrange: expected a codemod entry for two files, got %+v, %v", history, err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.JournalUndo(journal_models.UndoRequest{ID: history.Entries[0].ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected the codemod to be undone, got %v", err)
+	}
+	if got := readContent(t, client, first); got != "value = 1" {
+		t.Errorf("expected %s to be restored, got %q", first, got)
+	}
+	if got := readContent(t, client, second); got != "value = 2" {
+		t.Errorf("expected %s to be restored, got %q", second, got)
+	}
+}
+
+func TestCodemod_ApplyReplacesFilesWhole(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_codemod_atomic")
+	testFile := filepath.Join(testDir, "file.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: testFile, Content: "value = 1"})
+	preview, err := client.CodemodPreview(codemod_models.PreviewRequest{
+		Path:        testDir,
+		Pattern:     "value",
+		Replacement: "setting",
+	})
+	if err != nil {
+		t.Fatalf("Failed to arrange: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.CodemodApply(codemod_models.ApplyRequest{PreviewID: preview.PreviewID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no apply error, got %v", err)
+	}
+	ls, err := client.ListFiles(ls_models.Request{Path: testDir, ShowHidden: true})
+	if err != nil || len(ls.Entries) != 1 || ls.Entries[0].Name != "file.txt" {
+		t.Errorf("expected only the rewritten file to be left, got %+v, %v", ls, err)
+	}
+	if got := readContent(t, client, testFile); got != "setting = 1" {
+		t.Errorf("expected the file to be rewritten, got %q", got)
+	}
+}
+
+func TestCodemod_PreviewNotFound(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.CodemodApply(codemod_models.ApplyRequest{
+		PreviewID: "does-not-exist",
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, 404, "Preview not found or expired")
+}
+
+func TestCodemod_InvalidRegex(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.CodemodPreview(codemod_models.PreviewRequest{
+		Path:    e2e.TestDir,
+		Pattern: "(unclosed",
+		Regex:   true,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, 400, "Invalid pattern: error parsing regexp: missing closing ): `(unclosed`")
+}
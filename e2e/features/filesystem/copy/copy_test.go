@@ -0,0 +1,272 @@
+package copy_test
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	copy_models "agent-dev-environment/src/api/v1/filesystem/copy"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	link_models "agent-dev-environment/src/api/v1/filesystem/link"
+	ls_models "agent-dev-environment/src/api/v1/filesystem/ls"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	journal_models "agent-dev-environment/src/api/v1/journal"
+	run_models "agent-dev-environment/src/api/v1/shell/run"
+)
+
+func TestCopy_CopiesTreeWithExcludes(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_copy_tree")
+	source := filepath.Join(testDir, "template")
+	destination := filepath.Join(testDir, "instance")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(source, "a.txt"), Content: "alpha"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(source, "sub", "b.txt"), Content: "beta!"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(source, "sub", "debug.log"), Content: "noise"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(source, "node_modules", "dep.js"), Content: "dep"})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.CopyFile(copy_models.Request{
+		Source:      source,
+		Destination: destination,
+		Exclude:     []string{"node_modules", "*.log"},
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if resp.FilesCopied != 2 || resp.BytesCopied != 10 || resp.Excluded != 2 {
+		t.Errorf("expected 2 files, 10 bytes and 2 excluded, got %+v", resp)
+	}
+
+	read, err := client.ReadFile(read_models.Request{Path: filepath.Join(destination, "sub", "b.txt")})
+	if err != nil {
+		t.Fatalf("failed to read copied file: %v", err)
+	}
+	if read.Content != "beta!" {
+		t.Errorf("expected copied content %q, got %q", "beta!", read.Content)
+	}
+
+	listing, err := client.ListFiles(ls_models.Request{Path: destination, Recursive: true})
+	if err != nil {
+		t.Fatalf("failed to list destination: %v", err)
+	}
+	for _, entry := range listing.Entries {
+		if entry.Name == "node_modules" || entry.Name == "sub/debug.log" {
+			t.Errorf("expected %s to be excluded", entry.Name)
+		}
+	}
+
+	// The source is left untouched
+	if _, err := client.ReadFile(read_models.Request{Path: filepath.Join(source, "a.txt")}); err != nil {
+		t.Errorf("expected source to remain, got %v", err)
+	}
+}
+
+func TestCopy_PreservesTimes(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	source := filepath.Join(e2e.TestDir, "test_copy_times_source.txt")
+	destination := filepath.Join(e2e.TestDir, "test_copy_times_dest.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: source})
+	defer client.DeleteFile(delete_models.Request{Path: destination})
+
+	client.CreateFile(create_models.Request{Path: source, Content: "old"})
+	client.RunShell(run_models.Request{Command: "touch", Args: []string{"-d", "2020-01-02T03:04:05Z", source}})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.CopyFile(copy_models.Request{
+		Source:        source,
+		Destination:   destination,
+		PreserveTimes: true,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	listing, err := client.ListFiles(ls_models.Request{Path: destination})
+	if err != nil {
+		t.Fatalf("failed to list destination: %v", err)
+	}
+	if len(listing.Entries) != 1 || listing.Entries[0].ModifiedAt.Year() != 2020 {
+		t.Errorf("expected modification time from 2020, got %+v", listing.Entries)
+	}
+}
+
+func TestCopy_DestinationAlreadyExists(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	source := filepath.Join(e2e.TestDir, "test_copy_conflict_source.txt")
+	destination := filepath.Join(e2e.TestDir, "test_copy_conflict_dest.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: source})
+	defer client.DeleteFile(delete_models.Request{Path: destination})
+
+	client.CreateFile(create_models.Request{Path: source, Content: "new"})
+	client.CreateFile(create_models.Request{Path: destination, Content: "existing"})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.CopyFile(copy_models.Request{Source: source, Destination: destination})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusConflict, "Destination path already exists")
+}
+
+func TestCopy_OverwriteReplacesDestination(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	source := filepath.Join(e2e.TestDir, "test_copy_overwrite_source.txt")
+	destination := filepath.Join(e2e.TestDir, "test_copy_overwrite_dest.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: source})
+	defer client.DeleteFile(delete_models.Request{Path: destination})
+
+	client.CreateFile(create_models.Request{Path: source, Content: "new"})
+	client.CreateFile(create_models.Request{Path: destination, Content: "existing content"})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.CopyFile(copy_models.Request{Source: source, Destination: destination, Overwrite: true})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	read, err := client.ReadFile(read_models.Request{Path: destination})
+	if err != nil {
+		t.Fatalf("failed to read destination: %v", err)
+	}
+	if read.Content != "new" {
+		t.Errorf("expected destination content %q, got %q", "new", read.Content)
+	}
+}
+
+func TestCopy_IntoItself(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	source := filepath.Join(e2e.TestDir, "test_copy_into_itself")
+
+	defer client.DeleteFile(delete_models.Request{Path: source, Recursive: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(source, "file.txt"), Content: "x"})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.CopyFile(copy_models.Request{Source: source, Destination: filepath.Join(source, "nested")})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusBadRequest, "Cannot copy a directory into itself")
+}
+
+func TestCopy_IntoItselfThroughSymlink(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_copy_into_itself_symlink")
+	source := filepath.Join(testDir, "source")
+	link := filepath.Join(testDir, "link")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(source, "file.txt"), Content: "x"})
+	if _, err := client.LinkCreate(link_models.CreateRequest{Path: link, Target: source}); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.CopyFile(copy_models.Request{Source: source, Destination: filepath.Join(link, "nested")})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusBadRequest, "Cannot copy a directory into itself")
+}
+
+func TestCopy_OntoItself(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_copy_onto_itself")
+	source := filepath.Join(testDir, "file.txt")
+	hardlink := filepath.Join(testDir, "hardlink.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: source, Content: "keep me"})
+	if _, err := client.LinkCreate(link_models.CreateRequest{Path: hardlink, Target: source, Type: link_models.TypeHardlink}); err != nil {
+		t.Fatalf("Failed to arrange: could not create hard link: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, errSame := client.CopyFile(copy_models.Request{Source: source, Destination: source, Overwrite: true})
+	_, errLink := client.CopyFile(copy_models.Request{Source: source, Destination: hardlink, Overwrite: true})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, errSame, http.StatusBadRequest, "Cannot copy a file onto itself")
+	e2e.AssertError(t, errLink, http.StatusBadRequest, "Cannot copy a file onto itself")
+
+	read, err := client.ReadFile(read_models.Request{Path: source})
+	if err != nil || read.Content != "keep me" {
+		t.Errorf("expected the source to keep its content, got %v, %v", read, err)
+	}
+}
+
+func TestCopy_SourceNotFound(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.CopyFile(copy_models.Request{
+		Source:      filepath.Join(e2e.TestDir, "test_copy_missing.txt"),
+		Destination: filepath.Join(e2e.TestDir, "test_copy_missing_dest.txt"),
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusNotFound, "Source path does not exist")
+}
+
+func TestCopy_CanBeUndone(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_copy_undo")
+	source := filepath.Join(testDir, "source")
+	destination := filepath.Join(testDir, "destination")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(source, "kept.txt"), Content: "new"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(source, "sub", "added.txt"), Content: "added"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(destination, "kept.txt"), Content: "old"})
+	if _, err := client.CopyFile(copy_models.Request{Source: source, Destination: destination, Overwrite: true}); err != nil {
+		t.Fatalf("Failed to arrange: %v", err)
+	}
+	history, err := client.JournalList(journal_models.ListRequest{Path: destination, Limit: 1})
+	if err != nil || len(history.Entries) != 1 || history.Entries[0].Op != "copy" {
+		t.Fatalf("Failed to arrange: expected a copy entry, got %+v, %v", history, err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.JournalUndo(journal_models.UndoRequest{ID: history.Entries[0].ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected the copy to be undone, got %v", err)
+	}
+	read, err := client.ReadFile(read_models.Request{Path: filepath.Join(destination, "kept.txt")})
+	if err != nil || read.Content != "old" {
+		t.Errorf("expected the overwritten file to come back, got %v, %v", read, err)
+	}
+	if _, err := client.ListFiles(ls_models.Request{Path: filepath.Join(destination, "sub")}); err == nil {
+		t.Error("expected the copied directory to be removed")
+	}
+}
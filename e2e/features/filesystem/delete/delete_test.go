@@ -136,3 +136,175 @@ func TestDeleteFile_EmptyPath(t *testing.T) {
 	// ------------------------------------ Assert -------------------------------------
 	AssertError(t, err, http.StatusBadRequest, "Path is required")
 }
+
+func createTree(t *testing.T, client *Client, base string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		if _, err := client.CreateFile(create_models.Request{Path: base + "/" + path, Content: content}); err != nil {
+			t.Fatalf("Failed to arrange: could not create %s: %v", path, err)
+		}
+	}
+}
+
+func TestDelete_PatternsDryRun(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := NewClient()
+	baseDir := TestDir + "/delete_patterns_dry_run"
+	defer client.DeleteFile(delete_models.Request{Path: baseDir, Recursive: true, Permanent: true})
+
+	createTree(t, client, baseDir, map[string]string{
+		"coverage.out":     "12345",
+		"pkg/a.test":       "123",
+		"pkg/a.go":         "package pkg",
+		"dist/bundle.js":   "1234567",
+		"web/dist/app.js":  "12",
+		"web/src/index.ts": "export {}",
+	})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.DeleteFile(delete_models.Request{
+		Path:      baseDir,
+		Recursive: true,
+		Patterns:  []string{"**/*.test", "dist/", "coverage.out"},
+		DryRun:    true,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []delete_models.Item{
+		{Path: baseDir + "/coverage.out", Size: 5},
+		{Path: baseDir + "/dist", IsDir: true, Size: 7},
+		{Path: baseDir + "/pkg/a.test", Size: 3},
+		{Path: baseDir + "/web/dist", IsDir: true, Size: 2},
+	}
+	if len(resp.Items) != len(expected) {
+		t.Fatalf("Expected items %+v, got %+v", expected, resp.Items)
+	}
+	for i, item := range expected {
+		if resp.Items[i] != item {
+			t.Errorf("Expected item %+v, got %+v", item, resp.Items[i])
+		}
+	}
+	if resp.TotalBytes != 17 || !resp.DryRun {
+		t.Errorf("Expected 17 bytes on a dry run, got %d (dry run %v)", resp.TotalBytes, resp.DryRun)
+	}
+
+	// Nothing is removed on a dry run
+	if _, err := client.ReadFile(read_models.Request{Path: baseDir + "/pkg/a.test"}); err != nil {
+		t.Errorf("Expected file to remain after dry run, got: %v", err)
+	}
+}
+
+func TestDelete_PatternsDeletesMatches(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := NewClient()
+	baseDir := TestDir + "/delete_patterns"
+	defer client.DeleteFile(delete_models.Request{Path: baseDir, Recursive: true, Permanent: true})
+
+	createTree(t, client, baseDir, map[string]string{
+		"a.log":     "x",
+		"sub/b.log": "x",
+		"sub/c.txt": "keep",
+	})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.DeleteFile(delete_models.Request{
+		Path:     baseDir,
+		Patterns: []string{"*.log"},
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(resp.Items) != 2 {
+		t.Fatalf("Expected 2 items deleted, got %+v", resp.Items)
+	}
+	for _, item := range resp.Items {
+		if item.TrashID == "" {
+			t.Errorf("Expected %s to be moved to the trash", item.Path)
+		}
+	}
+
+	_, err = client.ReadFile(read_models.Request{Path: baseDir + "/sub/b.log"})
+	AssertError(t, err, http.StatusNotFound, "File not found")
+
+	if _, err := client.ReadFile(read_models.Request{Path: baseDir + "/sub/c.txt"}); err != nil {
+		t.Errorf("Expected unmatched file to remain, got: %v", err)
+	}
+}
+
+func TestDelete_PatternsDirectoriesNeedRecursive(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := NewClient()
+	baseDir := TestDir + "/delete_patterns_non_recursive"
+	defer client.DeleteFile(delete_models.Request{Path: baseDir, Recursive: true, Permanent: true})
+
+	createTree(t, client, baseDir, map[string]string{
+		"build/out.bin": "x",
+	})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.DeleteFile(delete_models.Request{
+		Path:     baseDir,
+		Patterns: []string{"build/"},
+		DryRun:   true,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(resp.Items) != 0 {
+		t.Errorf("Expected no directories to match without recursive, got %+v", resp.Items)
+	}
+}
+
+func TestDelete_PatternsMaxItems(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := NewClient()
+	baseDir := TestDir + "/delete_patterns_max_items"
+	defer client.DeleteFile(delete_models.Request{Path: baseDir, Recursive: true, Permanent: true})
+
+	createTree(t, client, baseDir, map[string]string{
+		"a.tmp": "x",
+		"b.tmp": "x",
+		"c.tmp": "x",
+	})
+	maxItems := 2
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.DeleteFile(delete_models.Request{
+		Path:     baseDir,
+		Patterns: []string{"*.tmp"},
+		MaxItems: &maxItems,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	AssertError(t, err, http.StatusBadRequest, "Patterns match 3 entries, more than max_items (2)")
+
+	if _, err := client.ReadFile(read_models.Request{Path: baseDir + "/a.tmp"}); err != nil {
+		t.Errorf("Expected files to remain when max items is exceeded, got: %v", err)
+	}
+}
+
+func TestDelete_PatternsRequireDirectory(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := NewClient()
+	filePath := TestDir + "/delete_patterns_on_file.txt"
+	defer client.DeleteFile(delete_models.Request{Path: filePath, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "x"})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.DeleteFile(delete_models.Request{
+		Path:     filePath,
+		Patterns: []string{"*"},
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	AssertError(t, err, http.StatusBadRequest, "Path must be a directory when patterns are given")
+}
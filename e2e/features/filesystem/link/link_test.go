@@ -0,0 +1,233 @@
+package link_test
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	link_models "agent-dev-environment/src/api/v1/filesystem/link"
+	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
+	run_models "agent-dev-environment/src/api/v1/shell/run"
+)
+
+func TestLink_CreateSymlink(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_link_symlink")
+	target := filepath.Join(testDir, "config", "base.yaml")
+	path := filepath.Join(testDir, "app.yaml")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: target, Content: "port: 8080"})
+
+	// -------------------------------------- Act --------------------------------------
+	created, err := client.LinkCreate(link_models.CreateRequest{Path: path, Target: "config/base.yaml"})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if created.Type != link_models.TypeSymlink || created.Target != "config/base.yaml" || created.Resolved != target || !created.TargetExists {
+		t.Errorf("unexpected link: %+v", created)
+	}
+
+	read, err := client.ReadFile(read_models.Request{Path: path})
+	if err != nil {
+		t.Fatalf("failed to read through the link: %v", err)
+	}
+	if read.Content != "port: 8080" || read.SymlinkTarget != "config/base.yaml" {
+		t.Errorf("expected the target's content reported as read through a symlink, got %+v", read)
+	}
+}
+
+func TestLink_CreateHardlink(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_link_hardlink")
+	target := filepath.Join(testDir, "original.txt")
+	path := filepath.Join(testDir, "alias.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: target, Content: "shared"})
+
+	// -------------------------------------- Act --------------------------------------
+	created, err := client.LinkCreate(link_models.CreateRequest{Path: path, Target: target, Type: link_models.TypeHardlink})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	original, err := client.LinkRead(link_models.ReadRequest{Path: target})
+	if err != nil {
+		t.Fatalf("failed to read link: %v", err)
+	}
+	if created.Type != link_models.TypeFile || created.Links != 2 || created.Inode != original.Inode {
+		t.Errorf("expected a second name for the same file, got %+v and %+v", created, original)
+	}
+
+	client.Replace(replace_models.Request{Path: path, OldString: "shared", NewString: "changed"})
+	read, err := client.ReadFile(read_models.Request{Path: target})
+	if err != nil || read.Content != "changed" {
+		t.Errorf("expected the change to show through the other name, got %v, %v", read, err)
+	}
+}
+
+func TestLink_SymlinkTargetOutsideWorkspace(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	_, err := client.ReadFile(read_models.Request{Path: "/etc/hostname"})
+	var apiErr *e2e.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusForbidden {
+		t.Skip("server is not confined to a workspace root")
+	}
+	testDir := filepath.Join(e2e.TestDir, "test_link_outside")
+	path := filepath.Join(testDir, "escape")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.Mkdir(mkdir_models.Request{Path: testDir})
+
+	// -------------------------------------- Act --------------------------------------
+	_, errAbs := client.LinkCreate(link_models.CreateRequest{Path: path, Target: "/etc"})
+	_, errRel := client.LinkCreate(link_models.CreateRequest{Path: path, Target: "../../../../etc"})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, errAbs, http.StatusForbidden, `Target "/etc" is outside the workspace`)
+	e2e.AssertError(t, errRel, http.StatusForbidden, `Target "../../../../etc" is outside the workspace`)
+
+	if _, err := client.LinkRead(link_models.ReadRequest{Path: path}); err == nil {
+		t.Error("expected no link to be created")
+	}
+}
+
+func TestLink_ReadSymlinkOutsideWorkspace(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	_, err := client.ReadFile(read_models.Request{Path: "/etc/hostname"})
+	var apiErr *e2e.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusForbidden {
+		t.Skip("server is not confined to a workspace root")
+	}
+	testDir := filepath.Join(e2e.TestDir, "test_link_read_outside")
+	path := filepath.Join(testDir, "escape")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.Mkdir(mkdir_models.Request{Path: testDir})
+	// Made outside the API, which refuses to create such links
+	script := "import os, sys; os.symlink('/etc', sys.argv[1])"
+	if _, err := client.RunShell(run_models.Request{Command: "python3", Args: []string{"-c", script, path}}); err != nil {
+		t.Fatalf("failed to arrange: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	link, err := client.LinkRead(link_models.ReadRequest{Path: path})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected the link itself to be readable, got %v", err)
+	}
+	if link.Type != link_models.TypeSymlink || link.Target != "/etc" || link.Resolved != "/etc" || !link.TargetExists || link.Inside {
+		t.Errorf("expected a link reported as leading outside the workspace, got %+v", link)
+	}
+
+	_, err = client.ReadFile(read_models.Request{Path: filepath.Join(path, "hostname")})
+	e2e.AssertError(t, err, http.StatusForbidden, `Path "`+filepath.Join(path, "hostname")+`" is outside the workspace`)
+}
+
+func TestLink_PathAlreadyExists(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_link_exists")
+	path := filepath.Join(testDir, "file.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: path, Content: "kept"})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.LinkCreate(link_models.CreateRequest{Path: path, Target: "other.txt"})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusConflict, "Path already exists")
+}
+
+func TestLink_HardlinkToDirectory(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_link_hardlink_dir")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.Mkdir(mkdir_models.Request{Path: filepath.Join(testDir, "dir")})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.LinkCreate(link_models.CreateRequest{
+		Path:   filepath.Join(testDir, "alias"),
+		Target: filepath.Join(testDir, "dir"),
+		Type:   link_models.TypeHardlink,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusBadRequest, "Hard links to directories are not supported")
+}
+
+func TestLink_ReadDanglingSymlink(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_link_dangling")
+	path := filepath.Join(testDir, "dangling")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.Mkdir(mkdir_models.Request{Path: testDir})
+	client.LinkCreate(link_models.CreateRequest{Path: path, Target: "missing.txt"})
+
+	// -------------------------------------- Act --------------------------------------
+	link, err := client.LinkRead(link_models.ReadRequest{Path: path})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if link.Type != link_models.TypeSymlink || link.TargetExists || link.Resolved != filepath.Join(testDir, "missing.txt") {
+		t.Errorf("expected a dangling symlink, got %+v", link)
+	}
+}
+
+func TestLink_DeleteSymlinkToDirectory(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_link_delete")
+	file := filepath.Join(testDir, "dir", "file.txt")
+	path := filepath.Join(testDir, "link")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: file, Content: "kept"})
+	client.LinkCreate(link_models.CreateRequest{Path: path, Target: "dir"})
+
+	// -------------------------------------- Act --------------------------------------
+	res, err := client.DeleteFile(delete_models.Request{Path: path})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected the link to be deleted without the recursive flag, got %v", err)
+	}
+	if len(res.Items) != 1 || !res.Items[0].IsSymlink || res.Items[0].IsDir {
+		t.Errorf("expected the link itself to be reported, got %+v", res.Items)
+	}
+
+	read, err := client.ReadFile(read_models.Request{Path: file})
+	if err != nil || read.Content != "kept" {
+		t.Errorf("expected the directory behind the link to be kept, got %v, %v", read, err)
+	}
+}
@@ -2,15 +2,24 @@ package ls
 
 import (
 	"path/filepath"
-	"strings"
+	"reflect"
 	"testing"
 
 	"agent-dev-environment/e2e"
 	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
 	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
 	ls_models "agent-dev-environment/src/api/v1/filesystem/ls"
+	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
 )
 
+func entryNames(entries []ls_models.Entry) []string {
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
 func TestListFiles_BasicDirectory(t *testing.T) {
 	// ------------------------------------ Arrange ------------------------------------
 	client := e2e.NewClient()
@@ -38,9 +47,7 @@ func TestListFiles_BasicDirectory(t *testing.T) {
 
 	// -------------------------------------- Act --------------------------------------
 	resp, err := client.ListFiles(ls_models.Request{
-		Path:      testDir,
-		Recursive: false,
-		Long:      false,
+		Path: testDir,
 	})
 
 	// ------------------------------------ Assert -------------------------------------
@@ -48,17 +55,20 @@ func TestListFiles_BasicDirectory(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	got := strings.TrimSpace(resp.CommandOutput)
-	expected := "file1.txt\nfile2.txt\nfile3.txt"
-	if got != expected {
-		t.Errorf("expected exactly %q, got %q", expected, got)
+	got := entryNames(resp.Entries)
+	expected := []string{"file1.txt", "file2.txt", "file3.txt"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected exactly %v, got %v", expected, got)
+	}
+	if resp.Total != 3 || resp.HasMore {
+		t.Errorf("expected total 3 without more entries, got total %d, has_more %v", resp.Total, resp.HasMore)
 	}
 }
 
-func TestListFiles_WithLongFormat(t *testing.T) {
+func TestListFiles_EntryMetadata(t *testing.T) {
 	// ------------------------------------ Arrange ------------------------------------
 	client := e2e.NewClient()
-	testDir := filepath.Join(e2e.TestDir, "test_ls_long")
+	testDir := filepath.Join(e2e.TestDir, "test_ls_metadata")
 
 	defer func() {
 		client.DeleteFile(delete_models.Request{
@@ -71,47 +81,61 @@ func TestListFiles_WithLongFormat(t *testing.T) {
 		Path:    filepath.Join(testDir, "file.txt"),
 		Content: "test content",
 	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "subdir", "a.txt"),
+		Content: "a",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "subdir", "b.txt"),
+		Content: "b",
+	})
 
 	// -------------------------------------- Act --------------------------------------
 	resp, err := client.ListFiles(ls_models.Request{
-		Path:      testDir,
-		Recursive: false,
-		Long:      true,
+		Path: testDir,
 	})
 
 	// ------------------------------------ Assert -------------------------------------
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-
-	output := resp.CommandOutput
-	if output == "" {
-		t.Fatal("expected command output to be non-empty")
+	if len(resp.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %v", entryNames(resp.Entries))
 	}
 
-	// Long format should contain file permissions
-	if !strings.Contains(output, "file.txt") {
-		t.Errorf("expected output to contain 'file.txt', got: %s", output)
+	file := resp.Entries[0]
+	if file.Name != "file.txt" || file.Type != ls_models.TypeFile {
+		t.Errorf("expected file entry 'file.txt', got %+v", file)
+	}
+	if file.Path != filepath.Join(testDir, "file.txt") {
+		t.Errorf("expected path %q, got %q", filepath.Join(testDir, "file.txt"), file.Path)
+	}
+	if file.Size != int64(len("test content")) {
+		t.Errorf("expected size %d, got %d", len("test content"), file.Size)
+	}
+	if file.Mode != "-rw-r--r--" {
+		t.Errorf("expected mode -rw-r--r--, got %q", file.Mode)
+	}
+	if file.ModifiedAt.IsZero() {
+		t.Error("expected modified_at to be set")
+	}
+	if file.ChildCount != nil {
+		t.Errorf("expected no child count for a file, got %d", *file.ChildCount)
 	}
 
-	// Long format should have permission info (starting with - or d)
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	foundFileInfo := false
-	for _, line := range lines {
-		if strings.Contains(line, "file.txt") && (strings.HasPrefix(line, "-") || strings.HasPrefix(line, "d")) {
-			foundFileInfo = true
-			break
-		}
+	dir := resp.Entries[1]
+	if dir.Name != "subdir" || dir.Type != ls_models.TypeDir {
+		t.Errorf("expected dir entry 'subdir', got %+v", dir)
 	}
-	if !foundFileInfo {
-		t.Errorf("expected long format output with permissions, got: %s", output)
+	if dir.ChildCount == nil || *dir.ChildCount != 2 {
+		t.Errorf("expected child count 2, got %v", dir.ChildCount)
 	}
 }
 
-func TestListFiles_WithoutLongFormat(t *testing.T) {
+func TestListFiles_Recursive(t *testing.T) {
 	// ------------------------------------ Arrange ------------------------------------
 	client := e2e.NewClient()
-	testDir := filepath.Join(e2e.TestDir, "test_ls_no_long")
+	testDir := filepath.Join(e2e.TestDir, "test_ls_recursive")
 
 	defer func() {
 		client.DeleteFile(delete_models.Request{
@@ -121,15 +145,22 @@ func TestListFiles_WithoutLongFormat(t *testing.T) {
 	}()
 
 	client.CreateFile(create_models.Request{
-		Path:    filepath.Join(testDir, "file.txt"),
-		Content: "test content",
+		Path:    filepath.Join(testDir, "file1.txt"),
+		Content: "content1",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "subdir", "file2.txt"),
+		Content: "content2",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "subdir", "nested", "file3.txt"),
+		Content: "content3",
 	})
 
 	// -------------------------------------- Act --------------------------------------
 	resp, err := client.ListFiles(ls_models.Request{
 		Path:      testDir,
-		Recursive: false,
-		Long:      false,
+		Recursive: true,
 	})
 
 	// ------------------------------------ Assert -------------------------------------
@@ -137,21 +168,57 @@ func TestListFiles_WithoutLongFormat(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	output := resp.CommandOutput
-	if output == "" {
-		t.Fatal("expected command output to be non-empty")
+	got := entryNames(resp.Entries)
+	expected := []string{
+		"file1.txt",
+		"subdir",
+		"subdir/file2.txt",
+		"subdir/nested",
+		"subdir/nested/file3.txt",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected exactly %v, got %v", expected, got)
+	}
+}
+
+func TestListFiles_RecursivePagination(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_ls_recursive_pagination")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	for _, name := range []string{"a-c.txt", "a/b.txt", "a/c.txt", "z.txt"} {
+		client.CreateFile(create_models.Request{Path: filepath.Join(testDir, name), Content: name})
+	}
+	offset := 1
+	limit := 3
+
+	// -------------------------------------- Act --------------------------------------
+	all, errAll := client.ListFiles(ls_models.Request{Path: testDir, Recursive: true})
+	page, errPage := client.ListFiles(ls_models.Request{Path: testDir, Recursive: true, Offset: &offset, Limit: &limit})
+
+	// ------------------------------------ Assert -------------------------------------
+	if errAll != nil || errPage != nil {
+		t.Fatalf("expected no error, got %v, %v", errAll, errPage)
 	}
 
-	// Should contain file name
-	if !strings.Contains(output, "file.txt") {
-		t.Errorf("expected output to contain 'file.txt', got: %s", output)
+	expected := []string{"a", "a/b.txt", "a/c.txt", "a-c.txt", "z.txt"}
+	if got := entryNames(all.Entries); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected directories followed by their contents %v, got %v", expected, got)
+	}
+	if got := entryNames(page.Entries); !reflect.DeepEqual(got, expected[1:4]) {
+		t.Errorf("expected the page %v, got %v", expected[1:4], got)
+	}
+	if !page.HasMore {
+		t.Error("expected has_more to be true")
 	}
 }
 
-func TestListFiles_Recursive(t *testing.T) {
+func TestListFiles_RecursiveDepthLimit(t *testing.T) {
 	// ------------------------------------ Arrange ------------------------------------
 	client := e2e.NewClient()
-	testDir := filepath.Join(e2e.TestDir, "test_ls_recursive")
+	testDir := filepath.Join(e2e.TestDir, "test_ls_depth_limit")
 
 	defer func() {
 		client.DeleteFile(delete_models.Request{
@@ -161,23 +228,16 @@ func TestListFiles_Recursive(t *testing.T) {
 	}()
 
 	client.CreateFile(create_models.Request{
-		Path:    filepath.Join(testDir, "file1.txt"),
-		Content: "content1",
-	})
-	client.CreateFile(create_models.Request{
-		Path:    filepath.Join(testDir, "subdir", "file2.txt"),
-		Content: "content2",
-	})
-	client.CreateFile(create_models.Request{
-		Path:    filepath.Join(testDir, "subdir", "nested", "file3.txt"),
-		Content: "content3",
+		Path:    filepath.Join(testDir, "a", "b", "c", "deep.txt"),
+		Content: "deep",
 	})
+	maxDepth := 2
 
 	// -------------------------------------- Act --------------------------------------
 	resp, err := client.ListFiles(ls_models.Request{
 		Path:      testDir,
 		Recursive: true,
-		Long:      false,
+		MaxDepth:  &maxDepth,
 	})
 
 	// ------------------------------------ Assert -------------------------------------
@@ -185,17 +245,10 @@ func TestListFiles_Recursive(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	output := resp.CommandOutput
-	if output == "" {
-		t.Fatal("expected command output to be non-empty")
-	}
-
-	// All files should be present in recursive output
-	expectedFiles := []string{"file1.txt", "file2.txt", "file3.txt"}
-	for _, file := range expectedFiles {
-		if !strings.Contains(output, file) {
-			t.Errorf("expected recursive output to contain %s, got: %s", file, output)
-		}
+	got := entryNames(resp.Entries)
+	expected := []string{"a", "a/b"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected exactly %v, got %v", expected, got)
 	}
 }
 
@@ -224,7 +277,6 @@ func TestListFiles_NonRecursive(t *testing.T) {
 	resp, err := client.ListFiles(ls_models.Request{
 		Path:      testDir,
 		Recursive: false,
-		Long:      false,
 	})
 
 	// ------------------------------------ Assert -------------------------------------
@@ -232,22 +284,11 @@ func TestListFiles_NonRecursive(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	output := resp.CommandOutput
-	if output == "" {
-		t.Fatal("expected command output to be non-empty")
-	}
-
-	// Should contain file1.txt and subdir
-	if !strings.Contains(output, "file1.txt") {
-		t.Errorf("expected output to contain 'file1.txt', got: %s", output)
-	}
-	if !strings.Contains(output, "subdir") {
-		t.Errorf("expected output to contain 'subdir', got: %s", output)
-	}
-
-	// Should NOT contain file2.txt (it's in subdir)
-	if strings.Contains(output, "file2.txt") {
-		t.Errorf("expected non-recursive output NOT to contain 'file2.txt', got: %s", output)
+	// Should contain file1.txt and subdir but NOT file2.txt (it's in subdir)
+	got := entryNames(resp.Entries)
+	expected := []string{"file1.txt", "subdir"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected exactly %v, got %v", expected, got)
 	}
 }
 
@@ -258,9 +299,7 @@ func TestListFiles_PathNotFound(t *testing.T) {
 
 	// -------------------------------------- Act --------------------------------------
 	_, err := client.ListFiles(ls_models.Request{
-		Path:      testPath,
-		Recursive: false,
-		Long:      false,
+		Path: testPath,
 	})
 
 	// ------------------------------------ Assert -------------------------------------
@@ -273,15 +312,27 @@ func TestListFiles_EmptyPath(t *testing.T) {
 
 	// -------------------------------------- Act --------------------------------------
 	_, err := client.ListFiles(ls_models.Request{
-		Path:      "",
-		Recursive: false,
-		Long:      false,
+		Path: "",
 	})
 
 	// ------------------------------------ Assert -------------------------------------
 	e2e.AssertError(t, err, 400, "Path is required")
 }
 
+func TestListFiles_InvalidSortBy(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.ListFiles(ls_models.Request{
+		Path:   e2e.TestDir,
+		SortBy: "owner",
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, 400, "Sort by must be one of 'name', 'size', 'mtime' or 'type'")
+}
+
 func TestListFiles_SingleFile(t *testing.T) {
 	// ------------------------------------ Arrange ------------------------------------
 	client := e2e.NewClient()
@@ -301,9 +352,7 @@ func TestListFiles_SingleFile(t *testing.T) {
 
 	// -------------------------------------- Act --------------------------------------
 	resp, err := client.ListFiles(ls_models.Request{
-		Path:      testFile,
-		Recursive: false,
-		Long:      false,
+		Path: testFile,
 	})
 
 	// ------------------------------------ Assert -------------------------------------
@@ -311,14 +360,12 @@ func TestListFiles_SingleFile(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	output := resp.CommandOutput
-	if output == "" {
-		t.Fatal("expected command output to be non-empty")
+	// When listing a single file, the only entry is the file itself
+	if len(resp.Entries) != 1 {
+		t.Fatalf("expected exactly one entry, got %v", entryNames(resp.Entries))
 	}
-
-	// When listing a single file, ls returns the file path
-	if !strings.Contains(output, testFile) {
-		t.Errorf("expected output to contain the file path, got: %s", output)
+	if resp.Entries[0].Path != testFile || resp.Entries[0].Type != ls_models.TypeFile {
+		t.Errorf("expected file entry for %q, got %+v", testFile, resp.Entries[0])
 	}
 }
 
@@ -334,20 +381,11 @@ func TestListFiles_EmptyDirectory(t *testing.T) {
 		})
 	}()
 
-	client.CreateFile(create_models.Request{
-		Path:    filepath.Join(testDir, ".placeholder"),
-		Content: "",
-	})
-	client.DeleteFile(delete_models.Request{
-		Path:      filepath.Join(testDir, ".placeholder"),
-		Recursive: false,
-	})
+	client.Mkdir(mkdir_models.Request{Path: testDir})
 
 	// -------------------------------------- Act --------------------------------------
 	resp, err := client.ListFiles(ls_models.Request{
-		Path:      testDir,
-		Recursive: false,
-		Long:      false,
+		Path: testDir,
 	})
 
 	// ------------------------------------ Assert -------------------------------------
@@ -355,17 +393,15 @@ func TestListFiles_EmptyDirectory(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	// Empty directory output should be empty or just whitespace
-	output := strings.TrimSpace(resp.CommandOutput)
-	if output != "" {
-		t.Fatalf("expected empty output for empty directory, got: %s", output)
+	if resp.Entries == nil || len(resp.Entries) != 0 || resp.Total != 0 {
+		t.Fatalf("expected an empty entry list for empty directory, got: %+v", resp)
 	}
 }
 
-func TestListFiles_CommandOutputField(t *testing.T) {
+func TestListFiles_HiddenFiles(t *testing.T) {
 	// ------------------------------------ Arrange ------------------------------------
 	client := e2e.NewClient()
-	testDir := filepath.Join(e2e.TestDir, "test_ls_output_field")
+	testDir := filepath.Join(e2e.TestDir, "test_ls_hidden")
 
 	defer func() {
 		client.DeleteFile(delete_models.Request{
@@ -375,15 +411,111 @@ func TestListFiles_CommandOutputField(t *testing.T) {
 	}()
 
 	client.CreateFile(create_models.Request{
-		Path:    filepath.Join(testDir, "test.txt"),
-		Content: "content",
+		Path:    filepath.Join(testDir, ".env"),
+		Content: "SECRET=1",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "visible.txt"),
+		Content: "visible",
+	})
+
+	// -------------------------------------- Act --------------------------------------
+	hiddenResp, hiddenErr := client.ListFiles(ls_models.Request{
+		Path: testDir,
+	})
+	shownResp, shownErr := client.ListFiles(ls_models.Request{
+		Path:       testDir,
+		ShowHidden: true,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if hiddenErr != nil || shownErr != nil {
+		t.Fatalf("expected no error, got %v / %v", hiddenErr, shownErr)
+	}
+
+	if got := entryNames(hiddenResp.Entries); !reflect.DeepEqual(got, []string{"visible.txt"}) {
+		t.Errorf("expected hidden files to be filtered out, got %v", got)
+	}
+	if got := entryNames(shownResp.Entries); !reflect.DeepEqual(got, []string{".env", "visible.txt"}) {
+		t.Errorf("expected hidden files to be listed, got %v", got)
+	}
+}
+
+func TestListFiles_SortBySize(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_ls_sort_size")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "small.txt"),
+		Content: "1",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "large.txt"),
+		Content: "1234567890",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "medium.txt"),
+		Content: "12345",
 	})
 
 	// -------------------------------------- Act --------------------------------------
 	resp, err := client.ListFiles(ls_models.Request{
-		Path:      testDir,
-		Recursive: false,
-		Long:      false,
+		Path:   testDir,
+		SortBy: ls_models.SortBySize,
+	})
+	reversed, reversedErr := client.ListFiles(ls_models.Request{
+		Path:    testDir,
+		SortBy:  ls_models.SortBySize,
+		Reverse: true,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil || reversedErr != nil {
+		t.Fatalf("expected no error, got %v / %v", err, reversedErr)
+	}
+
+	if got := entryNames(resp.Entries); !reflect.DeepEqual(got, []string{"large.txt", "medium.txt", "small.txt"}) {
+		t.Errorf("expected largest first, got %v", got)
+	}
+	if got := entryNames(reversed.Entries); !reflect.DeepEqual(got, []string{"small.txt", "medium.txt", "large.txt"}) {
+		t.Errorf("expected smallest first, got %v", got)
+	}
+}
+
+func TestListFiles_Pagination(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_ls_pagination")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt", "e.txt"} {
+		client.CreateFile(create_models.Request{
+			Path:    filepath.Join(testDir, name),
+			Content: name,
+		})
+	}
+	offset := 1
+	limit := 2
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.ListFiles(ls_models.Request{
+		Path:   testDir,
+		Offset: &offset,
+		Limit:  &limit,
 	})
 
 	// ------------------------------------ Assert -------------------------------------
@@ -391,13 +523,13 @@ func TestListFiles_CommandOutputField(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	// Verify that the response has the CommandOutput field with raw ls output
-	if resp.CommandOutput == "" {
-		t.Error("expected CommandOutput field to be populated with ls command output")
+	if got := entryNames(resp.Entries); !reflect.DeepEqual(got, []string{"b.txt", "c.txt"}) {
+		t.Errorf("expected second page [b.txt c.txt], got %v", got)
 	}
-
-	// The output should be a raw string from the ls command
-	if !strings.Contains(resp.CommandOutput, "test.txt") {
-		t.Errorf("expected CommandOutput to contain raw ls output with 'test.txt', got: %s", resp.CommandOutput)
+	if resp.Total != 5 {
+		t.Errorf("expected total 5, got %d", resp.Total)
+	}
+	if !resp.HasMore {
+		t.Error("expected has_more to be true")
 	}
 }
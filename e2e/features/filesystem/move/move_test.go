@@ -7,6 +7,7 @@ import (
 	"agent-dev-environment/e2e"
 	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
 	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
 	move_models "agent-dev-environment/src/api/v1/filesystem/move"
 	read_models "agent-dev-environment/src/api/v1/filesystem/read"
 )
@@ -424,8 +425,9 @@ func TestMoveDirectory_DestinationAlreadyExists(t *testing.T) {
 	}
 	defer client.DeleteFile(delete_models.Request{Path: sourceDir, Recursive: true})
 
-	// Create destination directory with a file via API
-	destFile := destDir + "/existing.txt"
+	// Create a directory with the same name inside the destination, since
+	// moving onto an existing directory moves the source into it
+	destFile := destDir + "/dir_exists_source/existing.txt"
 	_, err = client.CreateFile(create_models.Request{
 		Path:    destFile,
 		Content: "dest content",
@@ -446,3 +448,143 @@ func TestMoveDirectory_DestinationAlreadyExists(t *testing.T) {
 	// ------------------------------------ Assert -------------------------------------
 	e2e.AssertError(t, err, http.StatusConflict, "Destination path already exists")
 }
+
+func TestMoveFile_OverwriteReplacesDestination(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	sourcePath := e2e.TestDir + "/test_move_overwrite_source.txt"
+	destPath := e2e.TestDir + "/test_move_overwrite_dest.txt"
+
+	client.CreateFile(create_models.Request{Path: sourcePath, Content: "new content"})
+	client.CreateFile(create_models.Request{Path: destPath, Content: "old content"})
+	defer client.DeleteFile(delete_models.Request{Path: destPath})
+
+	req := move_models.Request{
+		Source:      sourcePath,
+		Destination: destPath,
+		Overwrite:   true,
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.MoveFile(req)
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if resp.Destination != destPath {
+		t.Errorf("Expected destination %q, got %q", destPath, resp.Destination)
+	}
+
+	read, err := client.ReadFile(read_models.Request{Path: destPath})
+	if err != nil {
+		t.Fatalf("Failed to read destination file: %v", err)
+	}
+	if read.Content != "new content" {
+		t.Errorf("Expected destination content to be %q, got %q", "new content", read.Content)
+	}
+}
+
+func TestMoveFile_IntoExistingDirectory(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	sourcePath := e2e.TestDir + "/test_move_into_dir_source.txt"
+	destDir := e2e.TestDir + "/test_move_into_dir"
+
+	client.CreateFile(create_models.Request{Path: sourcePath, Content: "moved into directory"})
+	client.Mkdir(mkdir_models.Request{Path: destDir})
+	defer client.DeleteFile(delete_models.Request{Path: destDir, Recursive: true})
+
+	req := move_models.Request{
+		Source:      sourcePath,
+		Destination: destDir,
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.MoveFile(req)
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := destDir + "/test_move_into_dir_source.txt"
+	if resp.Destination != expected {
+		t.Errorf("Expected destination %q, got %q", expected, resp.Destination)
+	}
+
+	read, err := client.ReadFile(read_models.Request{Path: expected})
+	if err != nil {
+		t.Fatalf("Failed to read moved file: %v", err)
+	}
+	if read.Content != "moved into directory" {
+		t.Errorf("Expected moved content to be %q, got %q", "moved into directory", read.Content)
+	}
+}
+
+func TestMoveFile_IntoDirectoryWithExistingEntry(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	sourcePath := e2e.TestDir + "/test_move_clash.txt"
+	destDir := e2e.TestDir + "/test_move_clash_dir"
+
+	client.CreateFile(create_models.Request{Path: sourcePath, Content: "source"})
+	client.CreateFile(create_models.Request{Path: destDir + "/test_move_clash.txt", Content: "existing"})
+	defer client.DeleteFile(delete_models.Request{Path: sourcePath})
+	defer client.DeleteFile(delete_models.Request{Path: destDir, Recursive: true})
+
+	req := move_models.Request{
+		Source:      sourcePath,
+		Destination: destDir,
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.MoveFile(req)
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusConflict, "Destination path already exists")
+}
+
+func TestMoveDirectory_IntoItself(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	sourceDir := e2e.TestDir + "/test_move_into_itself"
+
+	client.CreateFile(create_models.Request{Path: sourceDir + "/file.txt", Content: "x"})
+	defer client.DeleteFile(delete_models.Request{Path: sourceDir, Recursive: true})
+
+	req := move_models.Request{
+		Source:      sourceDir,
+		Destination: sourceDir + "/nested",
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.MoveFile(req)
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusBadRequest, "Cannot move a directory into itself")
+}
+
+func TestMoveDirectory_OverwriteNonEmptyDirectory(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	sourceDir := e2e.TestDir + "/test_move_overwrite_nonempty"
+	destDir := e2e.TestDir + "/test_move_overwrite_nonempty_dest"
+
+	client.CreateFile(create_models.Request{Path: sourceDir + "/new.txt", Content: "new"})
+	client.CreateFile(create_models.Request{Path: destDir + "/test_move_overwrite_nonempty/old.txt", Content: "old"})
+	defer client.DeleteFile(delete_models.Request{Path: sourceDir, Recursive: true})
+	defer client.DeleteFile(delete_models.Request{Path: destDir, Recursive: true})
+
+	req := move_models.Request{
+		Source:      sourceDir,
+		Destination: destDir,
+		Overwrite:   true,
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.MoveFile(req)
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusConflict, "Destination is a non-empty directory")
+}
@@ -0,0 +1,268 @@
+package quota_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	archive_models "agent-dev-environment/src/api/v1/filesystem/archive"
+	codemod_models "agent-dev-environment/src/api/v1/filesystem/codemod"
+	copy_models "agent-dev-environment/src/api/v1/filesystem/copy"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	ls_models "agent-dev-environment/src/api/v1/filesystem/ls"
+	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
+	raw_models "agent-dev-environment/src/api/v1/filesystem/raw"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
+	run_models "agent-dev-environment/src/api/v1/shell/run"
+	snapshot_models "agent-dev-environment/src/api/v1/snapshots"
+)
+
+// requireFileLimit skips tests against servers started without a file size
+// limit, and returns the limit.
+func requireFileLimit(t *testing.T, client *e2e.Client) int64 {
+	t.Helper()
+	usage, err := client.Usage()
+	if err != nil {
+		t.Fatalf("failed to get usage: %v", err)
+	}
+	if usage.MaxFileBytes == 0 {
+		t.Skip("server has no file size limit")
+	}
+	return usage.MaxFileBytes
+}
+
+func limitError(path string, limit int64) string {
+	return fmt.Sprintf("File %q exceeds the limit of %d bytes per file", path, limit)
+}
+
+func assertMissing(t *testing.T, client *e2e.Client, path string) {
+	t.Helper()
+	if _, err := client.ReadFile(read_models.Request{Path: path}); err == nil {
+		t.Errorf("expected %s not to be written", path)
+	}
+}
+
+func TestQuota_Usage(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_quota_usage")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "file.txt"), Content: "content"})
+
+	// -------------------------------------- Act --------------------------------------
+	usage, err := client.Usage()
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if usage.Root == "" || usage.Files < 1 || usage.Bytes < int64(len("content")) || usage.ScannedAt.IsZero() {
+		t.Errorf("expected the file to be counted, got %+v", usage)
+	}
+}
+
+func TestQuota_CreateFileTooLarge(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	limit := requireFileLimit(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_quota_create_file")
+	path := filepath.Join(testDir, "big.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.CreateFile(create_models.Request{Path: path, Content: strings.Repeat("a", int(limit)+1)})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusInsufficientStorage, limitError(path, limit))
+	assertMissing(t, client, path)
+}
+
+func TestQuota_ReplaceTooLarge(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	limit := requireFileLimit(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_quota_replace")
+	path := filepath.Join(testDir, "grows.txt")
+	content := strings.Repeat("a", int(limit)-10) + "END"
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	if _, err := client.CreateFile(create_models.Request{Path: path, Content: content}); err != nil {
+		t.Fatalf("failed to arrange: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.Replace(replace_models.Request{Path: path, OldString: "END", NewString: strings.Repeat("b", 20)})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusInsufficientStorage, limitError(path, limit))
+	_, body, err := client.RawDownload(raw_models.DownloadRequest{Path: path})
+	if err != nil || string(body) != content {
+		t.Errorf("expected the file to be unchanged, got %d bytes, %v", len(body), err)
+	}
+}
+
+func TestQuota_CodemodTooLarge(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	limit := requireFileLimit(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_quota_codemod")
+	path := filepath.Join(testDir, "grows.txt")
+	small := filepath.Join(testDir, "small.txt")
+	content := strings.Repeat("a", int(limit)-10) + "END"
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	if _, err := client.CreateFile(create_models.Request{Path: path, Content: content}); err != nil {
+		t.Fatalf("failed to arrange: %v", err)
+	}
+	client.CreateFile(create_models.Request{Path: small, Content: "END"})
+	preview, err := client.CodemodPreview(codemod_models.PreviewRequest{
+		Path:        testDir,
+		Pattern:     "END",
+		Replacement: strings.Repeat("b", 20),
+	})
+	if err != nil {
+		t.Fatalf("failed to arrange: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.CodemodApply(codemod_models.ApplyRequest{PreviewID: preview.PreviewID})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusInsufficientStorage, limitError(path, limit))
+	read, err := client.ReadFile(read_models.Request{Path: small})
+	if err != nil || read.Content != "END" {
+		t.Errorf("expected no file to be changed, got %v, %v", read, err)
+	}
+}
+
+func TestQuota_CreateFileConflictCheckedFirst(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	limit := requireFileLimit(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_quota_create_conflict")
+	path := filepath.Join(testDir, "existing.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: path, Content: "existing"})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.CreateFile(create_models.Request{Path: path, Content: strings.Repeat("a", int(limit)+1)})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusConflict, "File already exists")
+}
+
+func TestQuota_UploadTooLarge(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	limit := requireFileLimit(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_quota_upload")
+	path := filepath.Join(testDir, "big.bin")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.RawUpload(raw_models.UploadRequest{Path: path}, make([]byte, limit+1))
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusInsufficientStorage, limitError(path, limit))
+	ls, err := client.ListFiles(ls_models.Request{Path: testDir})
+	if err != nil || len(ls.Entries) != 0 {
+		t.Errorf("expected nothing to be left behind, got %+v, %v", ls, err)
+	}
+}
+
+func TestQuota_CopyTooLarge(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	limit := requireFileLimit(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_quota_copy")
+	source := filepath.Join(testDir, "big.bin")
+	destination := filepath.Join(testDir, "copy.bin")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// Commands are not limited, so they can make a file too large to copy
+	client.Mkdir(mkdir_models.Request{Path: testDir})
+	script := fmt.Sprintf("open(%q, 'wb').write(b'0' * %d)", source, limit+1)
+	if res, err := client.RunShell(run_models.Request{Command: "python3", Args: []string{"-c", script}}); err != nil || res.ExitCode != 0 {
+		t.Skipf("python3 is not available: %v, %v", res, err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.CopyFile(copy_models.Request{Source: source, Destination: destination})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusInsufficientStorage, limitError(destination, limit))
+	assertMissing(t, client, destination)
+}
+
+func TestQuota_SnapshotRestoreTooLarge(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	limit := requireFileLimit(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_quota_snapshot_restore")
+	path := filepath.Join(testDir, "big.bin")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// Commands are not limited, so they can make a file too large to restore
+	client.Mkdir(mkdir_models.Request{Path: testDir})
+	script := fmt.Sprintf("open(%q, 'wb').write(b'0' * %d)", path, limit+1)
+	if res, err := client.RunShell(run_models.Request{Command: "python3", Args: []string{"-c", script}}); err != nil || res.ExitCode != 0 {
+		t.Skipf("python3 is not available: %v, %v", res, err)
+	}
+	snap, err := client.SnapshotCreate(snapshot_models.CreateRequest{Path: testDir})
+	if err != nil {
+		t.Fatalf("failed to arrange: %v", err)
+	}
+	client.DeleteFile(delete_models.Request{Path: path, Permanent: true})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.SnapshotRestore(snapshot_models.RestoreRequest{ID: snap.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusInsufficientStorage, limitError(path, limit))
+	assertMissing(t, client, path)
+}
+
+func TestQuota_ArchiveImportTooLarge(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	limit := requireFileLimit(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_quota_archive_import")
+	destination := filepath.Join(testDir, "destination")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "small.txt", Mode: 0o644, Size: 5, Typeflag: tar.TypeReg})
+	tw.Write([]byte("small"))
+	tw.WriteHeader(&tar.Header{Name: "big.bin", Mode: 0o644, Size: limit + 1, Typeflag: tar.TypeReg})
+	tw.Write(make([]byte, limit+1))
+	tw.Close()
+	gz.Close()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.ArchiveImport(archive_models.ImportRequest{Path: destination}, buf.Bytes())
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusInsufficientStorage, limitError(filepath.Join(destination, "big.bin"), limit))
+	assertMissing(t, client, filepath.Join(destination, "small.txt"))
+}
@@ -0,0 +1,152 @@
+package raw_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	ls_models "agent-dev-environment/src/api/v1/filesystem/ls"
+	raw_models "agent-dev-environment/src/api/v1/filesystem/raw"
+)
+
+// binary holds every byte value, including ones that are not valid UTF-8.
+func binary() []byte {
+	data := make([]byte, 4096)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	return data
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRaw_RoundTrip(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_raw_round_trip")
+	path := filepath.Join(testDir, "assets", "blob.bin")
+	data := binary()
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// -------------------------------------- Act --------------------------------------
+	uploaded, err := client.RawUpload(raw_models.UploadRequest{Path: path, SHA256: checksum(data)}, data)
+	if err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+	resp, body, err := client.RawDownload(raw_models.DownloadRequest{Path: path, Checksum: true})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !uploaded.Created || uploaded.Size != int64(len(data)) || uploaded.SHA256 != checksum(data) {
+		t.Errorf("unexpected upload response: %+v", uploaded)
+	}
+	if !bytes.Equal(body, data) {
+		t.Errorf("expected the downloaded bytes to match the upload")
+	}
+	if got := resp.Header.Get(raw_models.ChecksumHeader); got != checksum(data) {
+		t.Errorf("expected checksum header %s, got %q", checksum(data), got)
+	}
+}
+
+func TestRaw_DownloadRange(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_raw_range")
+	path := filepath.Join(testDir, "blob.bin")
+	data := binary()
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	if _, err := client.RawUpload(raw_models.UploadRequest{Path: path}, data); err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+	ranged := *client
+	ranged.Header = http.Header{"Range": {"bytes=1000-1999"}}
+
+	// -------------------------------------- Act --------------------------------------
+	resp, body, err := ranged.RawDownload(raw_models.DownloadRequest{Path: path})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Errorf("expected status 206, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Range"); got != "bytes 1000-1999/4096" {
+		t.Errorf("unexpected Content-Range %q", got)
+	}
+	if !bytes.Equal(body, data[1000:2000]) {
+		t.Errorf("expected only the requested range, got %d bytes", len(body))
+	}
+}
+
+func TestRaw_ChecksumMismatch(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_raw_checksum_mismatch")
+	path := filepath.Join(testDir, "blob.bin")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.RawUpload(raw_models.UploadRequest{Path: path, SHA256: strings.Repeat("0", 64)}, binary())
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusBadRequest, "Checksum mismatch: body has SHA256 "+checksum(binary()))
+
+	ls, err := client.ListFiles(ls_models.Request{Path: testDir})
+	if err != nil || len(ls.Entries) != 0 {
+		t.Errorf("expected nothing to be written, got %+v, %v", ls, err)
+	}
+}
+
+func TestRaw_UploadExisting(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_raw_upload_existing")
+	path := filepath.Join(testDir, "blob.bin")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	if _, err := client.RawUpload(raw_models.UploadRequest{Path: path}, []byte("first")); err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, conflict := client.RawUpload(raw_models.UploadRequest{Path: path}, []byte("second"))
+	replaced, err := client.RawUpload(raw_models.UploadRequest{Path: path, Overwrite: true}, []byte("second"))
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, conflict, http.StatusConflict, "File already exists")
+	if err != nil || replaced.Created {
+		t.Fatalf("expected the file to be replaced, got %+v, %v", replaced, err)
+	}
+	_, body, err := client.RawDownload(raw_models.DownloadRequest{Path: path})
+	if err != nil || string(body) != "second" {
+		t.Errorf("expected the new content, got %q, %v", body, err)
+	}
+}
+
+func TestRaw_DownloadNotFound(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, _, err := client.RawDownload(raw_models.DownloadRequest{Path: filepath.Join(e2e.TestDir, "test_raw_missing")})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusNotFound, "File not found")
+}
@@ -8,7 +8,9 @@ import (
 	"agent-dev-environment/e2e"
 	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
 	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
 	search_models "agent-dev-environment/src/api/v1/filesystem/search"
+	run_models "agent-dev-environment/src/api/v1/shell/run"
 )
 
 func TestSearch_Basic(t *testing.T) {
@@ -144,3 +146,167 @@ func TestSearch_FilesWithMatches(t *testing.T) {
 		t.Errorf("expected exactly %q, got %q", expected, got)
 	}
 }
+
+func TestSearch_RespectsGitignore(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_search_gitignore")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, ".gitignore"),
+		Content: "build/\n*.log\n",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "src", "main.txt"),
+		Content: "needle in source\n",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "build", "out.txt"),
+		Content: "needle in build output\n",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "debug.log"),
+		Content: "needle in log\n",
+	})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.Search(search_models.Request{
+		Path:             testDir,
+		Pattern:          "needle",
+		FilesWithMatches: true,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := strings.TrimSpace(resp.CommandOutput)
+	expected := filepath.Join(testDir, "src", "main.txt")
+	if got != expected {
+		t.Errorf("expected exactly %q, got %q", expected, got)
+	}
+}
+
+func TestSearch_SkipsBinaryFiles(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_search_binary")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "text.txt"),
+		Content: "marker\n",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "blob.bin"),
+		Content: "\x00\x01marker\x00\n",
+	})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.Search(search_models.Request{
+		Path:             testDir,
+		Pattern:          "marker",
+		FilesWithMatches: true,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := strings.TrimSpace(resp.CommandOutput)
+	expected := filepath.Join(testDir, "text.txt")
+	if got != expected {
+		t.Errorf("expected exactly %q, got %q", expected, got)
+	}
+}
+
+func TestSearch_SingleFile(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testFile := filepath.Join(e2e.TestDir, "test_search_single_file.txt")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{Path: testFile})
+	}()
+
+	client.CreateFile(create_models.Request{
+		Path:    testFile,
+		Content: "first line\nsecond line\n",
+	})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.Search(search_models.Request{
+		Path:    testFile,
+		Pattern: "second",
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := strings.TrimSpace(resp.CommandOutput)
+	if got != "second line" {
+		t.Errorf("expected exactly %q, got %q", "second line", got)
+	}
+}
+
+func TestSearch_SeesChangesBetweenSearches(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_search_changes")
+	edited := filepath.Join(testDir, "edited.txt")
+	removed := filepath.Join(testDir, "removed.txt")
+	added := filepath.Join(testDir, "added.txt")
+	written := filepath.Join(testDir, "written.txt")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	client.CreateFile(create_models.Request{Path: edited, Content: "alpha\n"})
+	client.CreateFile(create_models.Request{Path: removed, Content: "omega\n"})
+	client.Search(search_models.Request{Path: testDir, Pattern: "alpha"})
+
+	client.Replace(replace_models.Request{Path: edited, OldString: "alpha", NewString: "omega"})
+	client.DeleteFile(delete_models.Request{Path: removed})
+	client.CreateFile(create_models.Request{Path: added, Content: "omega\n"})
+	// Written behind the API's back, as a command run by the agent would
+	client.RunShell(run_models.Request{Command: "cp", Args: []string{added, written}})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.Search(search_models.Request{
+		Path:             testDir,
+		Pattern:          "omega",
+		FilesWithMatches: true,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := strings.TrimSpace(resp.CommandOutput)
+	expected := strings.Join([]string{added, edited, written}, "\n")
+	if got != expected {
+		t.Errorf("expected exactly %q, got %q", expected, got)
+	}
+}
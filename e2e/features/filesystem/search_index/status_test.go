@@ -0,0 +1,117 @@
+package search_index
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"agent-dev-environment/e2e"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	search_models "agent-dev-environment/src/api/v1/filesystem/search"
+	run_models "agent-dev-environment/src/api/v1/shell/run"
+)
+
+func TestSearchIndexStatus_ReportsState(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.SearchIndexStatus()
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	switch resp.State {
+	case "disabled", "pending", "building", "ready", "failed":
+	default:
+		t.Fatalf("unexpected state %q", resp.State)
+	}
+	if resp.Enabled != (resp.State != "disabled") {
+		t.Errorf("expected enabled to match state %q, got %v", resp.State, resp.Enabled)
+	}
+	if resp.Enabled && resp.Root == "" {
+		t.Error("expected root to be reported when the index is enabled")
+	}
+}
+
+func TestSearchIndexStatus_CountsIndexedFiles(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_search_index_status_counts")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "a.txt"),
+		Content: "indexed content\n",
+	})
+	// A search triggers the build when the index is built lazily
+	client.Search(search_models.Request{Path: testDir, Pattern: "indexed"})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.SearchIndexStatus()
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.State != "ready" {
+		t.Skipf("index is %s, nothing to count", resp.State)
+	}
+
+	if resp.Files == 0 || resp.Trigrams == 0 || resp.Postings == 0 {
+		t.Errorf("expected non-empty index, got %d files, %d trigrams, %d postings", resp.Files, resp.Trigrams, resp.Postings)
+	}
+	if resp.MemoryBytes <= 0 {
+		t.Errorf("expected memory estimate, got %d", resp.MemoryBytes)
+	}
+	if resp.BuiltAt == nil {
+		t.Error("expected built_at to be set")
+	}
+}
+
+func TestSearchIndex_SeesFilesChangedOutsideTheAPI(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_search_index_outside_changes")
+	path := filepath.Join(testDir, "b.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "a.txt"), Content: "unrelated\n"})
+	client.Search(search_models.Request{Path: testDir, Pattern: "unrelated"})
+	if status, err := client.SearchIndexStatus(); err != nil || status.State != "ready" {
+		t.Skipf("index is not ready: %+v, %v", status, err)
+	}
+
+	// Written by a process the command leaves behind, after shell/run has
+	// returned, so neither the API nor shell/run knows about it
+	writer := `import sys, time; time.sleep(0.5); open(sys.argv[1], "w").write("written outside the api\n")`
+	spawn := `import subprocess, sys; subprocess.Popen([sys.executable, "-c", sys.argv[1], sys.argv[2]], start_new_session=True, stdout=subprocess.DEVNULL, stderr=subprocess.DEVNULL)`
+	if _, err := client.RunShell(run_models.Request{Command: "python3", Args: []string{"-c", spawn, writer, path}}); err != nil {
+		t.Fatalf("failed to arrange: %v", err)
+	}
+	// Takes in what shell/run left stale before the file is written
+	client.Search(search_models.Request{Path: testDir, Pattern: "outside the api"})
+	time.Sleep(time.Second)
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.Search(search_models.Request{Path: testDir, Pattern: "outside the api", FilesWithMatches: true})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := strings.TrimSpace(resp.CommandOutput); got != path {
+		t.Errorf("expected %s to be found, got %q", path, got)
+	}
+}
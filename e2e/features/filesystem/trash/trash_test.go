@@ -0,0 +1,292 @@
+package trash_test
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	ls_models "agent-dev-environment/src/api/v1/filesystem/ls"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	trash_models "agent-dev-environment/src/api/v1/filesystem/trash"
+	journal_models "agent-dev-environment/src/api/v1/journal"
+)
+
+func findEntry(entries []trash_models.Entry, id string) *trash_models.Entry {
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+func TestTrash_DeleteAndRestoreDirectory(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_trash_restore")
+	filePath := filepath.Join(testDir, "nested", "file.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "keep me"})
+	deleted, err := client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true})
+	if err != nil {
+		t.Fatalf("Failed to arrange: could not delete directory: %v", err)
+	}
+	if deleted.TrashID == "" {
+		t.Fatal("expected delete to return a trash ID")
+	}
+
+	list, err := client.TrashList()
+	if err != nil {
+		t.Fatalf("Failed to arrange: could not list trash: %v", err)
+	}
+	entry := findEntry(list.Entries, deleted.TrashID)
+	if entry == nil {
+		t.Fatalf("expected trash entry %s to be listed", deleted.TrashID)
+	}
+	if entry.OriginalPath != testDir || !entry.IsDir || entry.Size != int64(len("keep me")) || entry.RequestID == "" {
+		t.Errorf("unexpected trash entry %+v", entry)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.TrashRestore(trash_models.RestoreRequest{ID: deleted.TrashID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Path != testDir {
+		t.Errorf("expected restored path %q, got %q", testDir, resp.Path)
+	}
+
+	read, err := client.ReadFile(read_models.Request{Path: filePath})
+	if err != nil {
+		t.Fatalf("failed to read restored file: %v", err)
+	}
+	if read.Content != "keep me" {
+		t.Errorf("expected restored content %q, got %q", "keep me", read.Content)
+	}
+
+	list, err = client.TrashList()
+	if err != nil {
+		t.Fatalf("failed to list trash: %v", err)
+	}
+	if findEntry(list.Entries, deleted.TrashID) != nil {
+		t.Error("expected restored entry to leave the trash")
+	}
+}
+
+func TestTrash_KeptOutOfReachInWorkspaceRoot(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	filePath := filepath.Join(e2e.TestDir, "test_trash_bin.txt")
+	// Deleting is a rename into the bin at the top of the workspace root
+	bin := filepath.Join(e2e.TestDir, ".agent-trash")
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "trashed"})
+	deleted, err := client.DeleteFile(delete_models.Request{Path: filePath})
+	if err != nil {
+		t.Fatalf("Failed to arrange: could not delete file: %v", err)
+	}
+	defer client.TrashPurge(trash_models.PurgeRequest{IDs: []string{deleted.TrashID}})
+
+	// -------------------------------------- Act --------------------------------------
+	listed, errList := client.ListFiles(ls_models.Request{Path: e2e.TestDir, ShowHidden: true})
+	_, errRead := client.ReadFile(read_models.Request{Path: filepath.Join(bin, deleted.TrashID, "item")})
+
+	// ------------------------------------ Assert -------------------------------------
+	if errList != nil {
+		t.Fatalf("failed to list workspace root: %v", errList)
+	}
+	for _, entry := range listed.Entries {
+		if entry.Path == bin {
+			t.Errorf("expected the trash bin not to be listed, got %+v", entry)
+		}
+	}
+	e2e.AssertError(t, errRead, http.StatusForbidden, fmt.Sprintf("Path %q is inside the trash; use filesystem/trash to restore it", filepath.Join(bin, deleted.TrashID, "item")))
+
+	restored, err := client.TrashRestore(trash_models.RestoreRequest{ID: deleted.TrashID})
+	if err != nil || restored.Path != filePath {
+		t.Fatalf("expected the file to be restored, got %v, %v", restored, err)
+	}
+	client.DeleteFile(delete_models.Request{Path: filePath, Permanent: true})
+}
+
+func TestTrash_RestoreOntoExistingPath(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	filePath := filepath.Join(e2e.TestDir, "test_trash_restore_conflict.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: filePath, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "original"})
+	deleted, _ := client.DeleteFile(delete_models.Request{Path: filePath})
+	client.CreateFile(create_models.Request{Path: filePath, Content: "replacement"})
+	defer client.TrashPurge(trash_models.PurgeRequest{IDs: []string{deleted.TrashID}})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.TrashRestore(trash_models.RestoreRequest{ID: deleted.TrashID})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusConflict, "Destination path already exists")
+}
+
+func TestTrash_RestoreToDestination(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	filePath := filepath.Join(e2e.TestDir, "test_trash_restore_original.txt")
+	destination := filepath.Join(e2e.TestDir, "test_trash_restore_elsewhere", "restored.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: filepath.Dir(destination), Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "moved back"})
+	deleted, _ := client.DeleteFile(delete_models.Request{Path: filePath})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.TrashRestore(trash_models.RestoreRequest{ID: deleted.TrashID, Destination: destination})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Path != destination {
+		t.Errorf("expected restored path %q, got %q", destination, resp.Path)
+	}
+	if _, err := client.ReadFile(read_models.Request{Path: destination}); err != nil {
+		t.Errorf("expected restored file at destination, got %v", err)
+	}
+}
+
+func TestTrash_RestoreChecksProtectedContents(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	approved := e2e.NewClient()
+	approved.Header = http.Header{"X-Approved-Rules": {"e2e_approval/**"}}
+	testDir := filepath.Join(e2e.TestDir, "test_trash_restore_protected")
+	protected := filepath.Join(testDir, "e2e_approval", "file.txt")
+
+	defer approved.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	approved.CreateFile(create_models.Request{Path: protected, Content: "guarded"})
+	deleted, err := approved.DeleteFile(delete_models.Request{Path: testDir, Recursive: true})
+	if err != nil {
+		t.Fatalf("Failed to arrange: could not delete directory: %v", err)
+	}
+	defer client.TrashPurge(trash_models.PurgeRequest{IDs: []string{deleted.TrashID}})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.TrashRestore(trash_models.RestoreRequest{ID: deleted.TrashID})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusPreconditionRequired, `Path "`+protected+`" requires approval by rule "e2e_approval/**"; retry with the X-Approved-Rules header`)
+	if _, err := approved.TrashRestore(trash_models.RestoreRequest{ID: deleted.TrashID}); err != nil {
+		t.Errorf("expected the approved restore to succeed, got %v", err)
+	}
+}
+
+func TestTrash_RestoreCanBeUndone(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_trash_restore_undo")
+	filePath := filepath.Join(testDir, "file.txt")
+	restoredDir := filepath.Join(testDir, "restored")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "trashed version"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(restoredDir, "nested", "a.txt"), Content: "a"})
+	deletedFile, _ := client.DeleteFile(delete_models.Request{Path: filePath})
+	deletedDir, _ := client.DeleteFile(delete_models.Request{Path: restoredDir, Recursive: true})
+	client.CreateFile(create_models.Request{Path: filePath, Content: "current version"})
+
+	client.TrashRestore(trash_models.RestoreRequest{ID: deletedFile.TrashID, Overwrite: true})
+	client.TrashRestore(trash_models.RestoreRequest{ID: deletedDir.TrashID})
+	history, err := client.JournalList(journal_models.ListRequest{Path: testDir, Limit: 2})
+	if err != nil || len(history.Entries) != 2 || history.Entries[0].Op != "restore" || history.Entries[1].Op != "restore" {
+		t.Fatalf("Failed to arrange: expected two restore entries, got %+v, %v", history, err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, errDir := client.JournalUndo(journal_models.UndoRequest{ID: history.Entries[0].ID})
+	_, errFile := client.JournalUndo(journal_models.UndoRequest{ID: history.Entries[1].ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if errDir != nil || errFile != nil {
+		t.Fatalf("expected both restores to be undone, got %v, %v", errDir, errFile)
+	}
+	read, err := client.ReadFile(read_models.Request{Path: filePath})
+	if err != nil || read.Content != "current version" {
+		t.Errorf("expected the overwritten file to come back, got %v, %v", read, err)
+	}
+	if _, err := client.ListFiles(ls_models.Request{Path: restoredDir}); err == nil {
+		t.Error("expected the restored directory to go back to the trash")
+	}
+}
+
+func TestTrash_PermanentDeleteSkipsTrash(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	filePath := filepath.Join(e2e.TestDir, "test_trash_permanent.txt")
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "gone"})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.DeleteFile(delete_models.Request{Path: filePath, Permanent: true})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.TrashID != "" {
+		t.Errorf("expected no trash ID, got %q", resp.TrashID)
+	}
+
+	list, err := client.TrashList()
+	if err != nil {
+		t.Fatalf("failed to list trash: %v", err)
+	}
+	for _, entry := range list.Entries {
+		if entry.OriginalPath == filePath {
+			t.Errorf("expected permanently deleted file not to be trashed, found %+v", entry)
+		}
+	}
+}
+
+func TestTrash_Purge(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	filePath := filepath.Join(e2e.TestDir, "test_trash_purge.txt")
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "purge me"})
+	deleted, _ := client.DeleteFile(delete_models.Request{Path: filePath})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.TrashPurge(trash_models.PurgeRequest{IDs: []string{deleted.TrashID}})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Purged != 1 || resp.BytesFreed != int64(len("purge me")) {
+		t.Errorf("expected 1 entry and 8 bytes purged, got %+v", resp)
+	}
+
+	_, err = client.TrashRestore(trash_models.RestoreRequest{ID: deleted.TrashID})
+	e2e.AssertError(t, err, http.StatusNotFound, "Trash entry not found")
+}
+
+func TestTrash_PurgeRequiresIDsOrAll(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.TrashPurge(trash_models.PurgeRequest{})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusBadRequest, "IDs or all is required")
+}
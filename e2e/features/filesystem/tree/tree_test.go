@@ -0,0 +1,151 @@
+package tree
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	tree_models "agent-dev-environment/src/api/v1/filesystem/tree"
+)
+
+func TestTree_TextFormatCollapsesIgnoredDirectories(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_tree_text")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, ".gitignore"),
+		Content: "node_modules/\n*.log\n",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "package.json"),
+		Content: "{}",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "debug.log"),
+		Content: "noise",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "src", "index.ts"),
+		Content: "export {}",
+	})
+	for i := 0; i < 3; i++ {
+		client.CreateFile(create_models.Request{
+			Path:    filepath.Join(testDir, "node_modules", fmt.Sprintf("pkg%d", i), "index.js"),
+			Content: "module.exports = {}",
+		})
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.Tree(tree_models.Request{
+		Path: testDir,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := testDir + "/\n" +
+		"├── node_modules/ (3 files, ignored)\n" +
+		"├── src/\n" +
+		"│   └── index.ts\n" +
+		"└── package.json\n"
+	if resp.Tree != expected {
+		t.Errorf("expected tree:\n%s\ngot:\n%s", expected, resp.Tree)
+	}
+}
+
+func TestTree_JSONFormatWithLimits(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_tree_json")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	for i := 0; i < 5; i++ {
+		client.CreateFile(create_models.Request{
+			Path:    filepath.Join(testDir, fmt.Sprintf("file%d.txt", i)),
+			Content: "x",
+		})
+	}
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "deep", "nested", "leaf.txt"),
+		Content: "x",
+	})
+	maxDepth := 1
+	maxEntries := 3
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.Tree(tree_models.Request{
+		Path:             testDir,
+		MaxDepth:         &maxDepth,
+		MaxEntriesPerDir: &maxEntries,
+		Format:           tree_models.FormatJSON,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Root == nil {
+		t.Fatal("expected root node in json format")
+	}
+
+	children := resp.Root.Children
+	if len(children) != 4 {
+		t.Fatalf("expected 3 entries plus a summary node, got %d", len(children))
+	}
+
+	deep := children[0]
+	if deep.Name != "deep" || deep.Collapsed != tree_models.CollapsedDepth || deep.FileCount != 1 {
+		t.Errorf("expected 'deep' collapsed by depth with 1 file, got %+v", deep)
+	}
+
+	summary := children[3]
+	if summary.Type != tree_models.TypeSummary || summary.Omitted != 3 {
+		t.Errorf("expected summary node omitting 3 entries, got %+v", summary)
+	}
+}
+
+func TestTree_PathNotFound(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.Tree(tree_models.Request{
+		Path: filepath.Join(e2e.TestDir, "nonexistent_tree_dir"),
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, 404, "Path not found")
+}
+
+func TestTree_InvalidFormat(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.Tree(tree_models.Request{
+		Path:   e2e.TestDir,
+		Format: "xml",
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, 400, "Format must be 'text' or 'json'")
+}
@@ -0,0 +1,212 @@
+package watch_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"agent-dev-environment/e2e"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
+	move_models "agent-dev-environment/src/api/v1/filesystem/move"
+	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
+	watch_models "agent-dev-environment/src/api/v1/filesystem/watch"
+)
+
+// watch opens a stream on req and waits until the server watches the tree.
+func watch(t *testing.T, client *e2e.Client, req watch_models.Request) *e2e.EventStream {
+	t.Helper()
+	stream, err := client.Watch(req)
+	if err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	if event, ok := stream.Next(5 * time.Second); !ok || event.Name != "ready" {
+		stream.Close()
+		t.Fatalf("expected a ready event, got %+v", event)
+	}
+	return stream
+}
+
+// changes collects change events until none arrives for quiet.
+func changes(t *testing.T, stream *e2e.EventStream, quiet time.Duration) []watch_models.Event {
+	t.Helper()
+	result := []watch_models.Event{}
+	for {
+		event, ok := stream.Next(quiet)
+		if !ok {
+			return result
+		}
+		if event.Name != "change" {
+			continue
+		}
+		var change watch_models.Event
+		if err := json.Unmarshal([]byte(event.Data), &change); err != nil {
+			t.Fatalf("invalid change event %q: %v", event.Data, err)
+		}
+		result = append(result, change)
+	}
+}
+
+func TestWatch_ReportsChanges(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_watch_reports_changes")
+	path := filepath.Join(testDir, "main.go")
+	renamed := filepath.Join(testDir, "app.go")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.Mkdir(mkdir_models.Request{Path: testDir})
+	stream := watch(t, client, watch_models.Request{Path: testDir, DebounceMs: 50})
+	defer stream.Close()
+
+	// -------------------------------------- Act --------------------------------------
+	client.CreateFile(create_models.Request{Path: path, Content: "package main"})
+	created := changes(t, stream, time.Second)
+	client.Replace(replace_models.Request{Path: path, OldString: "main", NewString: "app"})
+	written := changes(t, stream, time.Second)
+	client.MoveFile(move_models.Request{Source: path, Destination: renamed})
+	moved := changes(t, stream, time.Second)
+	client.DeleteFile(delete_models.Request{Path: renamed, Permanent: true})
+	removed := changes(t, stream, time.Second)
+
+	// ------------------------------------ Assert -------------------------------------
+	if len(created) != 1 || created[0] != (watch_models.Event{Op: "create", Path: path}) {
+		t.Errorf("expected one create event, got %+v", created)
+	}
+	if len(written) != 1 || written[0].Op != "write" || written[0].Path != path {
+		t.Errorf("expected one write event, got %+v", written)
+	}
+	if len(moved) != 2 || moved[0] != (watch_models.Event{Op: "rename", Path: path}) || moved[1] != (watch_models.Event{Op: "create", Path: renamed}) {
+		t.Errorf("expected a rename and a create, got %+v", moved)
+	}
+	if len(removed) != 1 || removed[0] != (watch_models.Event{Op: "remove", Path: renamed}) {
+		t.Errorf("expected one remove event, got %+v", removed)
+	}
+}
+
+func TestWatch_NewDirectories(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_watch_new_directories")
+	nested := filepath.Join(testDir, "a", "b", "c.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.Mkdir(mkdir_models.Request{Path: testDir})
+	stream := watch(t, client, watch_models.Request{Path: testDir, DebounceMs: 50})
+	defer stream.Close()
+
+	// -------------------------------------- Act --------------------------------------
+	client.CreateFile(create_models.Request{Path: nested, Content: "first"})
+	created := changes(t, stream, time.Second)
+	client.Replace(replace_models.Request{Path: nested, OldString: "first", NewString: "second"})
+	written := changes(t, stream, time.Second)
+
+	// ------------------------------------ Assert -------------------------------------
+	paths := map[string]bool{}
+	for _, change := range created {
+		paths[change.Path] = change.Op == "create"
+	}
+	if len(created) != 3 || !paths[filepath.Join(testDir, "a")] || !paths[filepath.Join(testDir, "a", "b")] || !paths[nested] {
+		t.Errorf("expected the directories and the file to be created, got %+v", created)
+	}
+	if len(written) != 1 || written[0].Path != nested {
+		t.Errorf("expected new directories to be watched, got %+v", written)
+	}
+}
+
+func TestWatch_Filters(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_watch_filters")
+	kept := filepath.Join(testDir, "src", "main.go")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, ".gitignore"), Content: "node_modules/\n"})
+	client.Mkdir(mkdir_models.Request{Path: filepath.Join(testDir, "src")})
+	stream := watch(t, client, watch_models.Request{Path: testDir, Include: []string{"*.go", "*.txt"}, Exclude: []string{"vendor"}, DebounceMs: 50})
+	defer stream.Close()
+
+	// -------------------------------------- Act --------------------------------------
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "node_modules", "pkg", "index.go"), Content: "ignored"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "vendor", "lib.go"), Content: "excluded"})
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "src", "app.log"), Content: "not included"})
+	client.CreateFile(create_models.Request{Path: kept, Content: "package main"})
+	got := changes(t, stream, time.Second)
+
+	// ------------------------------------ Assert -------------------------------------
+	if len(got) != 1 || got[0] != (watch_models.Event{Op: "create", Path: kept}) {
+		t.Errorf("expected only %s to be reported, got %+v", kept, got)
+	}
+}
+
+func TestWatch_IncludeWatchesNewDirectories(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_watch_include_new_directories")
+	top := filepath.Join(testDir, "top.go")
+	inner := filepath.Join(testDir, "newpkg", "inner.go")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.Mkdir(mkdir_models.Request{Path: testDir})
+	stream := watch(t, client, watch_models.Request{Path: testDir, Include: []string{"*.go"}, DebounceMs: 50})
+	defer stream.Close()
+
+	// -------------------------------------- Act --------------------------------------
+	client.CreateFile(create_models.Request{Path: top, Content: "package top"})
+	client.Mkdir(mkdir_models.Request{Path: filepath.Dir(inner)})
+	created := changes(t, stream, time.Second)
+	client.CreateFile(create_models.Request{Path: inner, Content: "package newpkg"})
+	inside := changes(t, stream, time.Second)
+
+	// ------------------------------------ Assert -------------------------------------
+	if len(created) != 1 || created[0] != (watch_models.Event{Op: "create", Path: top}) {
+		t.Errorf("expected only %s to be reported, got %+v", top, created)
+	}
+	if len(inside) != 1 || inside[0] != (watch_models.Event{Op: "create", Path: inner}) {
+		t.Errorf("expected %s in the new directory to be reported, got %+v", inner, inside)
+	}
+}
+
+func TestWatch_Debounce(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_watch_debounce")
+	path := filepath.Join(testDir, "counter.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.Mkdir(mkdir_models.Request{Path: testDir})
+	stream := watch(t, client, watch_models.Request{Path: testDir, DebounceMs: 1000})
+	defer stream.Close()
+
+	// -------------------------------------- Act --------------------------------------
+	client.CreateFile(create_models.Request{Path: path, Content: "0"})
+	for i := 1; i <= 5; i++ {
+		client.Replace(replace_models.Request{Path: path, OldString: strconv.Itoa(i - 1), NewString: strconv.Itoa(i)})
+	}
+	got := changes(t, stream, 2*time.Second)
+
+	// ------------------------------------ Assert -------------------------------------
+	if len(got) != 1 || got[0] != (watch_models.Event{Op: "create", Path: path}) {
+		t.Errorf("expected the changes to be merged into one create, got %+v", got)
+	}
+}
+
+func TestWatch_NotFound(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.Watch(watch_models.Request{Path: filepath.Join(e2e.TestDir, "test_watch_missing")})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusNotFound, "Directory not found")
+}
@@ -0,0 +1,304 @@
+package journal_test
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	ls_models "agent-dev-environment/src/api/v1/filesystem/ls"
+	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
+	move_models "agent-dev-environment/src/api/v1/filesystem/move"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
+	journal_models "agent-dev-environment/src/api/v1/journal"
+	snapshot_models "agent-dev-environment/src/api/v1/snapshots"
+)
+
+// latestEntry returns the newest journal entry for op that touched path.
+// Other test packages run concurrently, so tests never undo by count.
+func latestEntry(t *testing.T, client *e2e.Client, path, op string) journal_models.Entry {
+	t.Helper()
+	resp, err := client.JournalList(journal_models.ListRequest{Path: path})
+	if err != nil {
+		t.Fatalf("failed to list journal: %v", err)
+	}
+	for _, entry := range resp.Entries {
+		if entry.Op == op {
+			return entry
+		}
+	}
+	t.Fatalf("no %s journal entry for %s in %+v", op, path, resp.Entries)
+	return journal_models.Entry{}
+}
+
+func readFile(t *testing.T, client *e2e.Client, path string) string {
+	t.Helper()
+	resp, err := client.ReadFile(read_models.Request{Path: path})
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return resp.Content
+}
+
+func TestJournal_UndoReplace(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_journal_replace")
+	filePath := filepath.Join(testDir, "file.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "hello world"})
+	if _, err := client.Replace(replace_models.Request{Path: filePath, OldString: "world", NewString: "there"}); err != nil {
+		t.Fatalf("Failed to arrange: could not replace: %v", err)
+	}
+	entry := latestEntry(t, client, filePath, "replace")
+	if !entry.Reversible || len(entry.Changes) != 1 || entry.Changes[0].Kind != "modified" || entry.RequestID == "" {
+		t.Fatalf("unexpected journal entry %+v", entry)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.JournalUndo(journal_models.UndoRequest{ID: entry.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(resp.Undone) != 1 || resp.Undone[0].ID != entry.ID || resp.Undone[0].UndoneAt == nil {
+		t.Errorf("unexpected undo response %+v", resp)
+	}
+	if got := readFile(t, client, filePath); got != "hello world" {
+		t.Errorf("expected %q, got %q", "hello world", got)
+	}
+
+	_, err = client.JournalUndo(journal_models.UndoRequest{ID: entry.ID})
+	e2e.AssertError(t, err, http.StatusConflict, "Operation "+entry.ID+" was already undone")
+}
+
+func TestJournal_UndoConflict(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_journal_conflict")
+	filePath := filepath.Join(testDir, "file.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "first"})
+	created := latestEntry(t, client, filePath, "create_file")
+	client.Replace(replace_models.Request{Path: filePath, OldString: "first", NewString: "second"})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.JournalUndo(journal_models.UndoRequest{ID: created.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusConflict, "Cannot undo operation "+created.ID+": "+filePath+" changed since")
+	if got := readFile(t, client, filePath); got != "second" {
+		t.Errorf("expected conflicting undo to leave the file alone, got %q", got)
+	}
+}
+
+func TestJournal_UndoCreateFile(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_journal_create")
+	filePath := filepath.Join(testDir, "file.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "created"})
+	entry := latestEntry(t, client, filePath, "create_file")
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.JournalUndo(journal_models.UndoRequest{ID: entry.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, err = client.ListFiles(ls_models.Request{Path: filePath})
+	e2e.AssertError(t, err, http.StatusNotFound, "Path not found")
+}
+
+func TestJournal_UndoCreateFileRemovesCreatedParents(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_journal_create_parents")
+	filePath := filepath.Join(testDir, "a", "b", "file.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.Mkdir(mkdir_models.Request{Path: testDir})
+	client.CreateFile(create_models.Request{Path: filePath, Content: "created"})
+	entry := latestEntry(t, client, filePath, "create_file")
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.JournalUndo(journal_models.UndoRequest{ID: entry.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, err = client.ListFiles(ls_models.Request{Path: filepath.Join(testDir, "a")})
+	e2e.AssertError(t, err, http.StatusNotFound, "Path not found")
+	if _, err := client.ListFiles(ls_models.Request{Path: testDir}); err != nil {
+		t.Errorf("expected the pre-existing parent to be kept, got %v", err)
+	}
+}
+
+func TestJournal_UndoSnapshotRestore(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_journal_snapshot_restore")
+	edited := filepath.Join(testDir, "edited.txt")
+	removed := filepath.Join(testDir, "removed.txt")
+	added := filepath.Join(testDir, "new", "added.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: edited, Content: "original"})
+	client.CreateFile(create_models.Request{Path: removed, Content: "removed later"})
+	snap, err := client.SnapshotCreate(snapshot_models.CreateRequest{Path: testDir})
+	if err != nil {
+		t.Fatalf("failed to arrange: %v", err)
+	}
+	client.Replace(replace_models.Request{Path: edited, OldString: "original", NewString: "edited"})
+	client.DeleteFile(delete_models.Request{Path: removed, Permanent: true})
+	client.CreateFile(create_models.Request{Path: added, Content: "added"})
+	if _, err := client.SnapshotRestore(snapshot_models.RestoreRequest{ID: snap.ID}); err != nil {
+		t.Fatalf("failed to arrange: %v", err)
+	}
+	entry := latestEntry(t, client, edited, "snapshot_restore")
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.JournalUndo(journal_models.UndoRequest{ID: entry.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := readFile(t, client, edited); got != "edited" {
+		t.Errorf("expected edited.txt to be %q again, got %q", "edited", got)
+	}
+	if got := readFile(t, client, added); got != "added" {
+		t.Errorf("expected added.txt to be back, got %q", got)
+	}
+	_, err = client.ListFiles(ls_models.Request{Path: removed})
+	e2e.AssertError(t, err, http.StatusNotFound, "Path not found")
+}
+
+func TestJournal_UndoDelete(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_journal_delete")
+	filePath := filepath.Join(testDir, "dir", "file.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "deleted"})
+	client.DeleteFile(delete_models.Request{Path: filepath.Dir(filePath), Recursive: true})
+	entry := latestEntry(t, client, filePath, "delete")
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.JournalUndo(journal_models.UndoRequest{ID: entry.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := readFile(t, client, filePath); got != "deleted" {
+		t.Errorf("expected %q, got %q", "deleted", got)
+	}
+}
+
+func TestJournal_UndoPermanentDelete(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_journal_permanent")
+	filePath := filepath.Join(testDir, "file.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "gone"})
+	client.DeleteFile(delete_models.Request{Path: filePath, Permanent: true})
+	entry := latestEntry(t, client, filePath, "delete")
+	if entry.Reversible {
+		t.Fatal("expected permanent delete to be irreversible")
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.JournalUndo(journal_models.UndoRequest{ID: entry.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusConflict, "Operation "+entry.ID+" cannot be undone: its previous state was not kept")
+}
+
+func TestJournal_UndoMoveWithOverwrite(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_journal_move")
+	source := filepath.Join(testDir, "source.txt")
+	destination := filepath.Join(testDir, "destination.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: source, Content: "moved"})
+	client.CreateFile(create_models.Request{Path: destination, Content: "overwritten"})
+	if _, err := client.MoveFile(move_models.Request{Source: source, Destination: destination, Overwrite: true}); err != nil {
+		t.Fatalf("Failed to arrange: could not move: %v", err)
+	}
+	entry := latestEntry(t, client, destination, "move")
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.JournalUndo(journal_models.UndoRequest{ID: entry.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := readFile(t, client, source); got != "moved" {
+		t.Errorf("expected source %q, got %q", "moved", got)
+	}
+	if got := readFile(t, client, destination); got != "overwritten" {
+		t.Errorf("expected overwritten destination to come back, got %q", got)
+	}
+}
+
+func TestJournal_UndoMkdir(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_journal_mkdir")
+	nested := filepath.Join(testDir, "a", "b")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.Mkdir(mkdir_models.Request{Path: testDir})
+	client.Mkdir(mkdir_models.Request{Path: nested})
+	entry := latestEntry(t, client, nested, "mkdir")
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.JournalUndo(journal_models.UndoRequest{ID: entry.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, err = client.ListFiles(ls_models.Request{Path: filepath.Join(testDir, "a")})
+	e2e.AssertError(t, err, http.StatusNotFound, "Path not found")
+	if _, err := client.ListFiles(ls_models.Request{Path: testDir}); err != nil {
+		t.Errorf("expected the pre-existing parent to be kept, got %v", err)
+	}
+}
+
+func TestJournal_UndoNotFound(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.JournalUndo(journal_models.UndoRequest{ID: "20000101T000000-deadbeef"})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusNotFound, "Journal entry not found")
+}
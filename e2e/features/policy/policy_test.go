@@ -0,0 +1,163 @@
+package policy_test
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	changes_models "agent-dev-environment/src/api/v1/filesystem/changes"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	run_models "agent-dev-environment/src/api/v1/shell/run"
+)
+
+// requireTestRules skips tests against servers started without the
+// e2e_denied and e2e_approval rules scripts/run-e2e.sh adds to
+// AGENT_DEV_ENVIRONMENT_PROTECTED_PATHS.
+func requireTestRules(t *testing.T, client *e2e.Client) {
+	t.Helper()
+	_, err := client.ReadFile(read_models.Request{Path: filepath.Join(e2e.TestDir, "e2e_denied", "probe")})
+	var apiErr *e2e.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusForbidden {
+		t.Skip("server is not configured with the e2e protected path rules")
+	}
+}
+
+func TestPolicy_ReadOnlyDefaultRule(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_policy_readonly")
+	hook := filepath.Join(testDir, ".git", "hooks", "pre-commit")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.CreateFile(create_models.Request{Path: hook, Content: "#!/bin/sh\n"})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusForbidden, `Path "`+hook+`" is protected by rule ".git/hooks/**" (readonly)`)
+
+	if _, err := client.ReadFile(read_models.Request{Path: hook}); err == nil {
+		t.Error("expected the hook not to be created")
+	}
+}
+
+func TestPolicy_ShellArgument(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_policy_shell")
+	config := filepath.Join(testDir, ".git", "config")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.RunShell(run_models.Request{Command: "touch", Args: []string{config}})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusForbidden, `Path "`+config+`" is protected by rule ".git/config" (readonly)`)
+}
+
+func TestPolicy_DenyBlocksReads(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	requireTestRules(t, client)
+	path := filepath.Join(e2e.TestDir, "test_policy_deny", "e2e_denied", "secret.txt")
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.ReadFile(read_models.Request{Path: path})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusForbidden, `Path "`+path+`" is protected by rule "e2e_denied/**" (deny)`)
+}
+
+func TestPolicy_RequireApproval(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	requireTestRules(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_policy_approval")
+	path := filepath.Join(testDir, "e2e_approval", "file.txt")
+
+	approved := e2e.NewClient()
+	approved.Header = http.Header{"X-Approved-Rules": {"e2e_approval/**"}}
+	defer approved.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// -------------------------------------- Act --------------------------------------
+	_, rejected := client.CreateFile(create_models.Request{Path: path, Content: "approved"})
+	_, err := approved.CreateFile(create_models.Request{Path: path, Content: "approved"})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, rejected, http.StatusPreconditionRequired, `Path "`+path+`" requires approval by rule "e2e_approval/**"; retry with the X-Approved-Rules header`)
+
+	if err != nil {
+		t.Fatalf("expected the approved request to succeed, got %v", err)
+	}
+	read, err := client.ReadFile(read_models.Request{Path: path})
+	if err != nil || read.Content != "approved" {
+		t.Errorf("expected the file to be written, got %v, %v", read, err)
+	}
+}
+
+func TestPolicy_DeleteDirectoryContainingProtectedPath(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	requireTestRules(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_policy_delete_tree")
+	path := filepath.Join(testDir, "e2e_approval", "file.txt")
+
+	approved := e2e.NewClient()
+	approved.Header = http.Header{"X-Approved-Rules": {"e2e_approval/**"}}
+	defer approved.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	approved.CreateFile(create_models.Request{Path: path, Content: "kept"})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusPreconditionRequired, `Path "`+path+`" requires approval by rule "e2e_approval/**"; retry with the X-Approved-Rules header`)
+
+	read, err := client.ReadFile(read_models.Request{Path: path})
+	if err != nil || read.Content != "kept" {
+		t.Errorf("expected the protected file to be kept, got %v, %v", read, err)
+	}
+}
+
+func TestPolicy_DenyHidesChanges(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	requireTestRules(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_policy_deny_changes")
+	visible := filepath.Join(testDir, "visible.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "keep.txt"), Content: "x"})
+	start, err := client.Changes(changes_models.Request{Path: testDir})
+	if err != nil {
+		t.Fatalf("failed to start tracking: %v", err)
+	}
+	// Written by a command, as the API refuses denied paths; the name is
+	// split so the argument check does not refuse it either
+	script := `import os, sys
+d = os.path.join(sys.argv[1], "e2e_" + "denied")
+os.makedirs(d)
+open(os.path.join(d, "secret.txt"), "w").close()
+open(os.path.join(sys.argv[1], "visible.txt"), "w").close()`
+	if _, err := client.RunShell(run_models.Request{Command: "python3", Args: []string{"-c", script, testDir}}); err != nil {
+		t.Fatalf("failed to arrange: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	res, err := client.Changes(changes_models.Request{Path: testDir, Cursor: start.Cursor})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(res.Created) != 1 || res.Created[0] != visible {
+		t.Errorf("expected only %s to be reported, got %v", visible, res.Created)
+	}
+}
@@ -0,0 +1,114 @@
+package overview
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	overview_models "agent-dev-environment/src/api/v1/project/overview"
+)
+
+func TestProjectOverview_DetectsProjectLayout(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_project_overview")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	files := map[string]string{
+		"go.mod":               "module example.com/demo\n\ngo 1.25\n",
+		"cmd/app/main.go":      "package main\n\nfunc main() {\n}\n",
+		"internal/lib.go":      "package internal\n\nfunc Lib() {}\n",
+		"internal/lib_test.go": "package internal\n",
+		"web/package.json":     `{"name": "demo-web", "bin": {"demo": "bin/cli.js"}}`,
+		"web/bin/cli.js":       "console.log('hi')",
+		".gitignore":           "build/\n",
+		"build/out.js":         "ignored()\n",
+	}
+	for path, content := range files {
+		client.CreateFile(create_models.Request{Path: filepath.Join(testDir, path), Content: content})
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.ProjectOverview(overview_models.Request{Path: testDir})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(resp.Languages) == 0 || resp.Languages[0].Name != "Go" {
+		t.Fatalf("expected Go to be the main language, got %+v", resp.Languages)
+	}
+	if resp.Languages[0].Files != 3 || resp.Languages[0].Lines != 8 {
+		t.Errorf("expected 3 Go files with 8 lines, got %+v", resp.Languages[0])
+	}
+	for _, lang := range resp.Languages {
+		if lang.Name == "JavaScript" && lang.Files != 1 {
+			t.Errorf("expected ignored JavaScript to be skipped, got %+v", lang)
+		}
+	}
+
+	expectedManifests := []overview_models.Manifest{
+		{Path: "go.mod", Kind: "go", Name: "example.com/demo"},
+		{Path: "web/package.json", Kind: "npm", Name: "demo-web"},
+	}
+	if len(resp.Manifests) != len(expectedManifests) {
+		t.Fatalf("expected manifests %+v, got %+v", expectedManifests, resp.Manifests)
+	}
+	for i, expected := range expectedManifests {
+		if resp.Manifests[i] != expected {
+			t.Errorf("expected manifest %+v, got %+v", expected, resp.Manifests[i])
+		}
+	}
+
+	if len(resp.TestDirs) != 1 || resp.TestDirs[0] != (overview_models.TestDir{Path: "internal", Files: 1}) {
+		t.Errorf("expected internal test dir, got %+v", resp.TestDirs)
+	}
+
+	expectedEntries := []overview_models.EntryPoint{
+		{Path: "cmd/app/main.go", Kind: "go main"},
+		{Path: "web/bin/cli.js", Kind: "package.json bin"},
+	}
+	if len(resp.EntryPoints) != len(expectedEntries) {
+		t.Fatalf("expected entry points %+v, got %+v", expectedEntries, resp.EntryPoints)
+	}
+	for i, expected := range expectedEntries {
+		if resp.EntryPoints[i] != expected {
+			t.Errorf("expected entry point %+v, got %+v", expected, resp.EntryPoints[i])
+		}
+	}
+
+	usage := map[string]overview_models.DirUsage{}
+	for _, dir := range resp.DiskUsage {
+		usage[dir.Path] = dir
+	}
+	if _, ok := usage["build"]; ok {
+		t.Error("expected ignored build directory to be excluded from disk usage")
+	}
+	if usage["internal"].Files != 2 || usage["web"].Files != 2 || usage["."].Files != 2 {
+		t.Errorf("unexpected disk usage %+v", resp.DiskUsage)
+	}
+	if resp.TotalFiles != 7 {
+		t.Errorf("expected 7 files, got %d", resp.TotalFiles)
+	}
+}
+
+func TestProjectOverview_PathNotFound(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.ProjectOverview(overview_models.Request{Path: filepath.Join(e2e.TestDir, "missing_project")})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusNotFound, "Path not found")
+}
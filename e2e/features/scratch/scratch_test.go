@@ -0,0 +1,149 @@
+package scratch_test
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"agent-dev-environment/e2e"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	scratch_models "agent-dev-environment/src/api/v1/scratch"
+)
+
+func listed(t *testing.T, client *e2e.Client, id string) bool {
+	t.Helper()
+	resp, err := client.ScratchList()
+	if err != nil {
+		t.Fatalf("failed to list scratch directories: %v", err)
+	}
+	for _, d := range resp.Dirs {
+		if d.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestScratch_CreateWriteDelete(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	dir, err := client.ScratchCreate(scratch_models.CreateRequest{Prefix: "experiment", TTL: "10m"})
+	if err != nil {
+		t.Fatalf("failed to create scratch directory: %v", err)
+	}
+	defer client.ScratchDelete(scratch_models.DeleteRequest{ID: dir.ID})
+
+	file := filepath.Join(dir.Path, "notes.txt")
+	_, errCreate := client.CreateFile(create_models.Request{Path: file, Content: "try this"})
+
+	// ------------------------------------ Assert -------------------------------------
+	if !strings.HasPrefix(filepath.Base(dir.Path), "experiment-") || dir.ExpiresAt == nil {
+		t.Errorf("unexpected scratch directory %+v", dir)
+	}
+	if errCreate != nil {
+		t.Fatalf("expected writing inside the scratch directory to be allowed, got %v", errCreate)
+	}
+	if !listed(t, client, dir.ID) {
+		t.Errorf("expected %s to be listed", dir.ID)
+	}
+
+	if _, err := client.ScratchDelete(scratch_models.DeleteRequest{ID: dir.ID}); err != nil {
+		t.Fatalf("failed to delete scratch directory: %v", err)
+	}
+	if listed(t, client, dir.ID) {
+		t.Errorf("expected %s not to be listed after deleting it", dir.ID)
+	}
+	if _, err := client.ReadFile(read_models.Request{Path: file}); err == nil {
+		t.Error("expected the scratch directory's files to be deleted")
+	}
+}
+
+func TestScratch_ExpiresAfterTTL(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	dir, err := client.ScratchCreate(scratch_models.CreateRequest{TTL: "1s"})
+	if err != nil {
+		t.Fatalf("Failed to arrange: could not create scratch directory: %v", err)
+	}
+	defer client.ScratchDelete(scratch_models.DeleteRequest{ID: dir.ID})
+	file := filepath.Join(dir.Path, "temp.txt")
+	client.CreateFile(create_models.Request{Path: file, Content: "short-lived"})
+
+	// -------------------------------------- Act --------------------------------------
+	time.Sleep(1500 * time.Millisecond)
+
+	// ------------------------------------ Assert -------------------------------------
+	if listed(t, client, dir.ID) {
+		t.Errorf("expected %s to expire", dir.ID)
+	}
+	if _, err := client.ReadFile(read_models.Request{Path: file}); err == nil {
+		t.Error("expected the expired directory's files to be deleted")
+	}
+}
+
+func TestScratch_DeletedWhenSessionEnds(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	session := e2e.NewClient()
+	session.Header = http.Header{"X-Session-ID": {"scratch-session-end"}}
+
+	dir, err := session.ScratchCreate(scratch_models.CreateRequest{})
+	if err != nil {
+		t.Fatalf("Failed to arrange: could not create scratch directory: %v", err)
+	}
+	defer client.ScratchDelete(scratch_models.DeleteRequest{ID: dir.ID})
+	if dir.Session != "scratch-session-end" || dir.ExpiresAt != nil {
+		t.Fatalf("expected a directory tied to the session, got %+v", dir)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = session.EndSession()
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("failed to end session: %v", err)
+	}
+	if listed(t, client, dir.ID) {
+		t.Errorf("expected %s to be deleted with its session", dir.ID)
+	}
+}
+
+func TestScratch_EndDefaultSession(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.EndSession()
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusBadRequest, "The default session cannot be ended; send an X-Session-ID header")
+}
+
+func TestScratch_DeleteUnknown(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.ScratchDelete(scratch_models.DeleteRequest{ID: "scratch-does-not-exist"})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusNotFound, "Scratch directory not found")
+}
+
+func TestScratch_InvalidRequest(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, errPrefix := client.ScratchCreate(scratch_models.CreateRequest{Prefix: "../escape"})
+	_, errTTL := client.ScratchCreate(scratch_models.CreateRequest{TTL: "-5m"})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, errPrefix, http.StatusBadRequest, "Prefix must be at most 32 letters, digits, '.', '_' or '-' and not start with '.'")
+	e2e.AssertError(t, errTTL, http.StatusBadRequest, "TTL must be a positive Go duration, like \"30m\"")
+}
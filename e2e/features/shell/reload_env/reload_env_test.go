@@ -56,7 +56,7 @@ func TestReloadEnv_UpdatesProcessEnvironment(t *testing.T) {
 	}
 
 	expectedLine := testVarName + "=" + testVarValue
-	if !strings.Contains(resp.CommandOutput, expectedLine) {
-		t.Errorf("Expected environment output to contain %q, but it didn't. Full output:\n%s", expectedLine, resp.CommandOutput)
+	if !strings.Contains(resp.Stdout, expectedLine) {
+		t.Errorf("Expected environment output to contain %q, but it didn't. Full output:\n%s", expectedLine, resp.Stdout)
 	}
 }
@@ -2,7 +2,10 @@ package run
 
 import (
 	. "agent-dev-environment/e2e"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
 	run_models "agent-dev-environment/src/api/v1/shell/run"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -22,7 +25,10 @@ func TestRunShell_AllowedCommand_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
-	if resp.CommandOutput == "" {
+	if resp.ExitCode != 0 || resp.TimedOut || resp.Signal != "" {
+		t.Errorf("Expected a clean exit, got %+v", resp)
+	}
+	if resp.Stdout == "" {
 		t.Error("Expected command output, got empty string")
 	}
 }
@@ -42,8 +48,65 @@ func TestRunShell_CurlLocalhost_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
-	if !strings.Contains(resp.CommandOutput, "OK") {
-		t.Errorf("Expected output to contain 'OK', got %q", resp.CommandOutput)
+	if !strings.Contains(resp.Stdout, "OK") {
+		t.Errorf("Expected output to contain 'OK', got %q", resp.Stdout)
+	}
+}
+
+func TestRunShell_NonZeroExit_ReportsOutput(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := NewClient()
+	testDir := filepath.Join(TestDir, "run_non_zero_exit_test")
+	existing := filepath.Join(testDir, "existing.txt")
+	missing := filepath.Join(testDir, "missing.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: existing, Content: "kept output"})
+	req := run_models.Request{
+		Command: "cat",
+		Args:    []string{existing, missing},
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.RunShell(req)
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("Expected a failing command to be reported, not an error, got %v", err)
+	}
+	if resp.ExitCode == 0 || resp.TimedOut || resp.Signal != "" {
+		t.Errorf("Expected a non-zero exit code, got %+v", resp)
+	}
+	if resp.Stdout != "kept output" {
+		t.Errorf("Expected stdout to be kept, got %q", resp.Stdout)
+	}
+	if !strings.Contains(resp.Stderr, "missing.txt") {
+		t.Errorf("Expected stderr to name the missing file, got %q", resp.Stderr)
+	}
+}
+
+func TestRunShell_Timeout_KillsCommand(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := NewClient()
+	req := run_models.Request{
+		Command:   "tail",
+		Args:      []string{"-f", "/dev/null"},
+		TimeoutMs: 200,
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.RunShell(req)
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("Expected a timed out command to be reported, not an error, got %v", err)
+	}
+	if !resp.TimedOut || resp.Signal != "killed" || resp.ExitCode != -1 {
+		t.Errorf("Expected the command to be killed after the timeout, got %+v", resp)
+	}
+	if resp.DurationMs < 200 || resp.DurationMs > 5000 {
+		t.Errorf("Expected the duration to reflect the timeout, got %dms", resp.DurationMs)
 	}
 }
 
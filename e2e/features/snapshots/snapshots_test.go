@@ -0,0 +1,297 @@
+package snapshots_test
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	ls_models "agent-dev-environment/src/api/v1/filesystem/ls"
+	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
+	snapshot_models "agent-dev-environment/src/api/v1/snapshots"
+)
+
+func readContent(t *testing.T, client *e2e.Client, path string) string {
+	t.Helper()
+	resp, err := client.ReadFile(read_models.Request{Path: path})
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return resp.Content
+}
+
+func indexOf(snapshots []snapshot_models.Snapshot, id string) int {
+	for i := range snapshots {
+		if snapshots[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestSnapshots_RestoreFull(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_snapshots_restore_full")
+	kept := filepath.Join(testDir, "kept.txt")
+	edited := filepath.Join(testDir, "src", "edited.txt")
+	removed := filepath.Join(testDir, "src", "removed.txt")
+	added := filepath.Join(testDir, "new", "added.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: kept, Content: "kept"})
+	client.CreateFile(create_models.Request{Path: edited, Content: "original"})
+	client.CreateFile(create_models.Request{Path: removed, Content: "removed later"})
+
+	snap, err := client.SnapshotCreate(snapshot_models.CreateRequest{Path: testDir, Label: "before step"})
+	if err != nil {
+		t.Fatalf("Failed to arrange: could not create snapshot: %v", err)
+	}
+	if snap.Files != 3 || snap.Label != "before step" || snap.Root != testDir {
+		t.Fatalf("unexpected snapshot %+v", snap)
+	}
+
+	client.Replace(replace_models.Request{Path: edited, OldString: "original", NewString: "edited by a runaway step"})
+	client.DeleteFile(delete_models.Request{Path: removed, Permanent: true})
+	client.CreateFile(create_models.Request{Path: added, Content: "should not survive"})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.SnapshotRestore(snapshot_models.RestoreRequest{ID: snap.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Restored != 2 || resp.Deleted != 1 || resp.Unchanged != 1 {
+		t.Errorf("expected 2 restored, 1 deleted, 1 unchanged, got %+v", resp)
+	}
+	if resp.BackupID == "" {
+		t.Error("expected a backup snapshot of the replaced state")
+	}
+
+	if got := readContent(t, client, edited); got != "original" {
+		t.Errorf("expected edited.txt to be %q, got %q", "original", got)
+	}
+	if got := readContent(t, client, removed); got != "removed later" {
+		t.Errorf("expected removed.txt to be %q, got %q", "removed later", got)
+	}
+	// Deleting added.txt leaves its directory empty, so it goes too
+	_, err = client.ListFiles(ls_models.Request{Path: filepath.Dir(added)})
+	e2e.AssertError(t, err, http.StatusNotFound, "Path not found")
+
+	// The backup undoes the restore
+	if _, err := client.SnapshotRestore(snapshot_models.RestoreRequest{ID: resp.BackupID}); err != nil {
+		t.Fatalf("failed to restore backup: %v", err)
+	}
+	if got := readContent(t, client, added); got != "should not survive" {
+		t.Errorf("expected backup to bring back added.txt, got %q", got)
+	}
+}
+
+func TestSnapshots_RestoreSelectedPaths(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_snapshots_restore_paths")
+	first := filepath.Join(testDir, "a", "first.txt")
+	second := filepath.Join(testDir, "b", "second.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: first, Content: "first"})
+	client.CreateFile(create_models.Request{Path: second, Content: "second"})
+
+	snap, err := client.SnapshotCreate(snapshot_models.CreateRequest{Path: testDir})
+	if err != nil {
+		t.Fatalf("Failed to arrange: could not create snapshot: %v", err)
+	}
+
+	client.Replace(replace_models.Request{Path: first, OldString: "first", NewString: "first, changed"})
+	client.Replace(replace_models.Request{Path: second, OldString: "second", NewString: "second, changed"})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.SnapshotRestore(snapshot_models.RestoreRequest{ID: snap.ID, Paths: []string{"a"}})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Restored != 1 {
+		t.Errorf("expected 1 restored file, got %d", resp.Restored)
+	}
+	if got := readContent(t, client, first); got != "first" {
+		t.Errorf("expected first.txt to be restored, got %q", got)
+	}
+	if got := readContent(t, client, second); got != "second, changed" {
+		t.Errorf("expected second.txt to be left alone, got %q", got)
+	}
+}
+
+func TestSnapshots_IncrementalAndList(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_snapshots_list")
+	filePath := filepath.Join(testDir, "file.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filePath, Content: "unchanged content"})
+	first, err := client.SnapshotCreate(snapshot_models.CreateRequest{Path: testDir})
+	if err != nil {
+		t.Fatalf("Failed to arrange: could not create snapshot: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	second, err := client.SnapshotCreate(snapshot_models.CreateRequest{Path: testDir})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if second.NewBytes != 0 || second.Bytes != first.Bytes {
+		t.Errorf("expected unchanged snapshot to store no new content, got %+v", second)
+	}
+
+	list, err := client.SnapshotList(snapshot_models.ListRequest{Path: testDir})
+	if err != nil {
+		t.Fatalf("failed to list snapshots: %v", err)
+	}
+	firstIndex, secondIndex := indexOf(list.Snapshots, first.ID), indexOf(list.Snapshots, second.ID)
+	if firstIndex < 0 || secondIndex < 0 || secondIndex > firstIndex {
+		t.Fatalf("expected both snapshots newest first, got %+v", list.Snapshots)
+	}
+
+	if _, err := client.SnapshotDelete(snapshot_models.DeleteRequest{ID: first.ID}); err != nil {
+		t.Fatalf("failed to delete snapshot: %v", err)
+	}
+	list, _ = client.SnapshotList(snapshot_models.ListRequest{Path: testDir})
+	if indexOf(list.Snapshots, first.ID) >= 0 || indexOf(list.Snapshots, second.ID) < 0 {
+		t.Errorf("expected only the second snapshot after delete, got %+v", list.Snapshots)
+	}
+
+	// Content shared with the deleted snapshot is still restorable
+	client.DeleteFile(delete_models.Request{Path: filePath, Permanent: true})
+	if _, err := client.SnapshotRestore(snapshot_models.RestoreRequest{ID: second.ID}); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if got := readContent(t, client, filePath); got != "unchanged content" {
+		t.Errorf("expected %q, got %q", "unchanged content", got)
+	}
+}
+
+func TestSnapshots_NotFound(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.SnapshotRestore(snapshot_models.RestoreRequest{ID: "20000101T000000-deadbeef"})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusNotFound, "Snapshot not found")
+}
+
+func TestSnapshots_RestorePathOutsideRoot(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_snapshots_outside")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "file.txt"), Content: "x"})
+	snap, err := client.SnapshotCreate(snapshot_models.CreateRequest{Path: testDir})
+	if err != nil {
+		t.Fatalf("Failed to arrange: could not create snapshot: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.SnapshotRestore(snapshot_models.RestoreRequest{ID: snap.ID, Paths: []string{"../elsewhere"}})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusBadRequest, "Paths must be inside the snapshot root")
+}
+
+func TestSnapshots_RestoreKeepsEmptyDirectories(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_snapshots_restore_dirs")
+	build := filepath.Join(testDir, "build")
+	logs := filepath.Join(testDir, "logs")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, "file.txt"), Content: "file"})
+	client.Mkdir(mkdir_models.Request{Path: build})
+	client.Mkdir(mkdir_models.Request{Path: logs})
+
+	snap, err := client.SnapshotCreate(snapshot_models.CreateRequest{Path: testDir})
+	if err != nil {
+		t.Fatalf("Failed to arrange: could not create snapshot: %v", err)
+	}
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(build, "out.txt"), Content: "built"})
+	client.DeleteFile(delete_models.Request{Path: logs, Recursive: true, Permanent: true})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.SnapshotRestore(snapshot_models.RestoreRequest{ID: snap.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Deleted != 1 {
+		t.Errorf("expected out.txt to be deleted, got %+v", resp)
+	}
+	for _, dir := range []string{build, logs} {
+		ls, err := client.ListFiles(ls_models.Request{Path: dir})
+		if err != nil || len(ls.Entries) != 0 {
+			t.Errorf("expected %s to be an empty directory, got %+v, %v", dir, ls, err)
+		}
+	}
+}
+
+func TestSnapshots_RestoreFileOverDirectoryKeepsIgnoredFiles(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_snapshots_restore_over_dir")
+	cache := filepath.Join(testDir, "cache")
+	ignored := filepath.Join(cache, "debug.log")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: filepath.Join(testDir, ".gitignore"), Content: "*.log\n"})
+	client.CreateFile(create_models.Request{Path: cache, Content: "cache file"})
+
+	snap, err := client.SnapshotCreate(snapshot_models.CreateRequest{Path: testDir})
+	if err != nil {
+		t.Fatalf("Failed to arrange: could not create snapshot: %v", err)
+	}
+
+	client.DeleteFile(delete_models.Request{Path: cache, Permanent: true})
+	client.CreateFile(create_models.Request{Path: ignored, Content: "ignored"})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err = client.SnapshotRestore(snapshot_models.RestoreRequest{ID: snap.ID})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := readContent(t, client, cache); got != "cache file" {
+		t.Errorf("expected cache to be a file again, got %q", got)
+	}
+	trash, err := client.TrashList()
+	if err != nil {
+		t.Fatalf("failed to list the trash: %v", err)
+	}
+	found := false
+	for _, entry := range trash.Entries {
+		found = found || entry.OriginalPath == cache
+	}
+	if !found {
+		t.Error("expected the directory in the way, with its ignored file, to be moved to the trash")
+	}
+}
@@ -0,0 +1,236 @@
+package search
+
+import (
+	"path/filepath"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
+	symbols_models "agent-dev-environment/src/api/v1/symbols/search"
+)
+
+const goSource = `package api
+
+const DefaultPort = 8080
+
+type Server struct {
+	port int
+}
+
+func (s *Server) Start() error {
+	return nil
+}
+
+func WrappedHandler(name string) {
+	WrappedHandler(name)
+}
+`
+
+func TestSearchSymbols_GoDefinitions(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_symbols_go")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	sourceFile := filepath.Join(testDir, "api", "api.go")
+	client.CreateFile(create_models.Request{
+		Path:    sourceFile,
+		Content: goSource,
+	})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.SearchSymbols(symbols_models.Request{
+		Path:  testDir,
+		Query: "WrappedHandler",
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(resp.Symbols) != 1 {
+		t.Fatalf("expected only the definition, got %+v", resp.Symbols)
+	}
+	expected := symbols_models.Symbol{
+		Name:      "WrappedHandler",
+		Kind:      "func",
+		Path:      sourceFile,
+		StartLine: 13,
+		EndLine:   15,
+	}
+	if resp.Symbols[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, resp.Symbols[0])
+	}
+}
+
+func TestSearchSymbols_KindFilterAndPrefix(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_symbols_kinds")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "server.go"),
+		Content: goSource,
+	})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.SearchSymbols(symbols_models.Request{
+		Path:  testDir,
+		Query: "st",
+		Match: symbols_models.MatchPrefix,
+		Kinds: []string{"method"},
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(resp.Symbols) != 1 {
+		t.Fatalf("expected a single method, got %+v", resp.Symbols)
+	}
+	method := resp.Symbols[0]
+	if method.Name != "Start" || method.Kind != "method" || method.Container != "Server" {
+		t.Errorf("expected method Server.Start, got %+v", method)
+	}
+}
+
+func TestSearchSymbols_HeuristicLanguages(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_symbols_heuristic")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "app.ts"),
+		Content: "export class UserService {\n  async fetchUser(id: string): Promise<User> {\n    return db.get(id);\n  }\n}\n\nexport interface User {\n  id: string;\n}\n",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "tool.py"),
+		Content: "MAX_USERS = 10\n\nclass UserRepo:\n    def fetch_user(self, id):\n        return None\n",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "lib.rs"),
+		Content: "pub struct UserCache<'a> {\n    name: &'a str,\n}\n\nimpl<'a> UserCache<'a> {\n    pub fn fetch_user(&self) -> Option<&'a str> {\n        None\n    }\n}\n",
+	})
+
+	// -------------------------------------- Act --------------------------------------
+	resp, err := client.SearchSymbols(symbols_models.Request{
+		Path:  testDir,
+		Query: "fetchuser",
+		Match: symbols_models.MatchFuzzy,
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	containers := map[string]symbols_models.Symbol{}
+	for _, symbol := range resp.Symbols {
+		if symbol.Kind != "method" {
+			t.Errorf("expected only methods, got %+v", symbol)
+		}
+		containers[symbol.Container] = symbol
+	}
+	for _, container := range []string{"UserService", "UserRepo", "UserCache"} {
+		if _, ok := containers[container]; !ok {
+			t.Errorf("expected a fetch user method in %s, got %+v", container, resp.Symbols)
+		}
+	}
+	if ts := containers["UserService"]; ts.StartLine != 2 || ts.EndLine != 4 {
+		t.Errorf("expected TypeScript method on lines 2-4, got %d-%d", ts.StartLine, ts.EndLine)
+	}
+	if rs := containers["UserCache"]; rs.StartLine != 6 || rs.EndLine != 8 {
+		t.Errorf("expected Rust method on lines 6-8, got %d-%d", rs.StartLine, rs.EndLine)
+	}
+}
+
+func TestSearchSymbols_UpdatesIncrementally(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	testDir := filepath.Join(e2e.TestDir, "test_symbols_incremental")
+
+	defer func() {
+		client.DeleteFile(delete_models.Request{
+			Path:      testDir,
+			Recursive: true,
+		})
+	}()
+
+	sourceFile := filepath.Join(testDir, "a.go")
+	client.CreateFile(create_models.Request{
+		Path:    sourceFile,
+		Content: "package a\n\nfunc Before() {}\n",
+	})
+	// Builds the index
+	if _, err := client.SearchSymbols(symbols_models.Request{Path: testDir, Query: "Before"}); err != nil {
+		t.Fatalf("failed to build index: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	client.Replace(replace_models.Request{
+		Path:      sourceFile,
+		OldString: "func Before() {}",
+		NewString: "func After() {}",
+	})
+	client.CreateFile(create_models.Request{
+		Path:    filepath.Join(testDir, "b.go"),
+		Content: "package a\n\ntype Added struct{}\n",
+	})
+
+	before, errBefore := client.SearchSymbols(symbols_models.Request{Path: testDir, Query: "Before"})
+	after, errAfter := client.SearchSymbols(symbols_models.Request{Path: testDir, Query: "After"})
+	added, errAdded := client.SearchSymbols(symbols_models.Request{Path: testDir, Query: "Added"})
+
+	// ------------------------------------ Assert -------------------------------------
+	if errBefore != nil || errAfter != nil || errAdded != nil {
+		t.Fatalf("expected no errors, got %v / %v / %v", errBefore, errAfter, errAdded)
+	}
+	if before.Total != 0 {
+		t.Errorf("expected renamed symbol to be gone, got %+v", before.Symbols)
+	}
+	if after.Total != 1 {
+		t.Errorf("expected renamed symbol to be indexed, got %+v", after.Symbols)
+	}
+	if added.Total != 1 || added.Symbols[0].Kind != "type" {
+		t.Errorf("expected new type to be indexed, got %+v", added.Symbols)
+	}
+}
+
+func TestSearchSymbols_InvalidKind(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.SearchSymbols(symbols_models.Request{
+		Path:  e2e.TestDir,
+		Query: "x",
+		Kinds: []string{"macro"},
+	})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, 400, "Unknown symbol kind: macro")
+}
@@ -0,0 +1,191 @@
+package workspace_test
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"agent-dev-environment/e2e"
+	chdir_models "agent-dev-environment/src/api/v1/filesystem/chdir"
+	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
+	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
+	link_models "agent-dev-environment/src/api/v1/filesystem/link"
+	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
+	move_models "agent-dev-environment/src/api/v1/filesystem/move"
+	read_models "agent-dev-environment/src/api/v1/filesystem/read"
+	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
+	run_models "agent-dev-environment/src/api/v1/shell/run"
+)
+
+// requireConfinement skips tests against servers started without
+// AGENT_DEV_ENVIRONMENT_WORKSPACE_ROOT, as scripts/run-e2e.sh sets it.
+func requireConfinement(t *testing.T, client *e2e.Client) {
+	t.Helper()
+	_, err := client.ReadFile(read_models.Request{Path: "/etc/hostname"})
+	var apiErr *e2e.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusForbidden {
+		t.Skip("server is not confined to a workspace root")
+	}
+}
+
+func TestConfinement_ReadOutsideWorkspace(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	requireConfinement(t, client)
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.ReadFile(read_models.Request{Path: "/proc/self/environ"})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusForbidden, `Path "/proc/self/environ" is outside the workspace`)
+}
+
+func TestConfinement_DotDotEscape(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	requireConfinement(t, client)
+	path := e2e.TestDir + "/../../etc/hostname"
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.ReadFile(read_models.Request{Path: path})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusForbidden, `Path "`+path+`" is outside the workspace`)
+}
+
+func TestConfinement_MoveOutOfWorkspace(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	requireConfinement(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_confinement_move")
+	source := filepath.Join(testDir, "file.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: source, Content: "stays"})
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.MoveFile(move_models.Request{Source: source, Destination: "/var/tmp/escaped.txt"})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusForbidden, `Path "/var/tmp/escaped.txt" is outside the workspace`)
+
+	read, err := client.ReadFile(read_models.Request{Path: source})
+	if err != nil || read.Content != "stays" {
+		t.Errorf("expected source to be untouched, got %v, %v", read, err)
+	}
+}
+
+func TestConfinement_ChdirOutsideWorkspace(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	requireConfinement(t, client)
+
+	// -------------------------------------- Act --------------------------------------
+	_, err := client.Chdir(chdir_models.Request{Path: "/"})
+
+	// ------------------------------------ Assert -------------------------------------
+	e2e.AssertError(t, err, http.StatusForbidden, `Path "/" is outside the workspace`)
+}
+
+func TestConfinement_AbsoluteSymlinkInsideWorkspace(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	requireConfinement(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_confinement_absolute_link")
+	target := filepath.Join(testDir, "target.txt")
+	link := filepath.Join(testDir, "link.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: target, Content: "value = 1"})
+	if _, err := client.LinkCreate(link_models.CreateRequest{Path: link, Target: target}); err != nil {
+		t.Fatalf("Failed to arrange: %v", err)
+	}
+
+	// -------------------------------------- Act --------------------------------------
+	_, replaceErr := client.Replace(replace_models.Request{Path: link, OldString: "value = 1", NewString: "value = 2"})
+	read, readErr := client.ReadFile(read_models.Request{Path: link})
+
+	// ------------------------------------ Assert -------------------------------------
+	if replaceErr != nil || readErr != nil {
+		t.Fatalf("expected to write and read through the link, got %v, %v", replaceErr, readErr)
+	}
+	if read.Content != "value = 2" {
+		t.Errorf("expected %q through the link, got %q", "value = 2", read.Content)
+	}
+}
+
+func TestConfinement_DeleteSymlinkLeadingOutside(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	requireConfinement(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_confinement_delete_link")
+	trashed := filepath.Join(testDir, "trashed")
+	removed := filepath.Join(testDir, "removed")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.Mkdir(mkdir_models.Request{Path: testDir})
+	symlinkOutside(t, client, "/etc", trashed, removed)
+
+	// -------------------------------------- Act --------------------------------------
+	trashRes, trashErr := client.DeleteFile(delete_models.Request{Path: trashed})
+	_, removeErr := client.DeleteFile(delete_models.Request{Path: removed, Permanent: true})
+
+	// ------------------------------------ Assert -------------------------------------
+	if trashErr != nil || removeErr != nil {
+		t.Fatalf("expected the links themselves to be deleted, got %v, %v", trashErr, removeErr)
+	}
+	if len(trashRes.Items) != 1 || !trashRes.Items[0].IsSymlink || trashRes.TrashID == "" {
+		t.Errorf("expected the link to be trashed, got %+v", trashRes)
+	}
+	for _, path := range []string{trashed, removed} {
+		if _, err := client.LinkRead(link_models.ReadRequest{Path: path}); err == nil {
+			t.Errorf("expected %s to be gone", path)
+		}
+	}
+}
+
+func TestConfinement_MoveSymlinkLeadingOutside(t *testing.T) {
+	// ------------------------------------ Arrange ------------------------------------
+	client := e2e.NewClient()
+	requireConfinement(t, client)
+	testDir := filepath.Join(e2e.TestDir, "test_confinement_move_link")
+	source := filepath.Join(testDir, "escape")
+	destination := filepath.Join(testDir, "renamed")
+	file := filepath.Join(testDir, "note.txt")
+	escaped := filepath.Join(destination, "note.txt")
+
+	defer client.DeleteFile(delete_models.Request{Path: testDir, Recursive: true, Permanent: true})
+
+	client.CreateFile(create_models.Request{Path: file, Content: "stays"})
+	symlinkOutside(t, client, "/etc", source)
+
+	// -------------------------------------- Act --------------------------------------
+	_, moveErr := client.MoveFile(move_models.Request{Source: source, Destination: destination})
+	_, escapeErr := client.MoveFile(move_models.Request{Source: file, Destination: escaped})
+
+	// ------------------------------------ Assert -------------------------------------
+	if moveErr != nil {
+		t.Fatalf("expected the link itself to be moved, got %v", moveErr)
+	}
+	link, err := client.LinkRead(link_models.ReadRequest{Path: destination})
+	if err != nil || link.Target != "/etc" {
+		t.Errorf("expected the link under its new name, got %+v, %v", link, err)
+	}
+	// Only the source is acted on itself; destinations are still followed
+	e2e.AssertError(t, escapeErr, http.StatusForbidden, `Path "`+escaped+`" is outside the workspace`)
+}
+
+// symlinkOutside creates symlinks to target at paths outside the API, which
+// refuses to create links leading out of the workspace.
+func symlinkOutside(t *testing.T, client *e2e.Client, target string, paths ...string) {
+	t.Helper()
+	script := "import os, sys\nfor path in sys.argv[2:]: os.symlink(sys.argv[1], path)"
+	args := append([]string{"-c", script, target}, paths...)
+	if _, err := client.RunShell(run_models.Request{Command: "python3", Args: args}); err != nil {
+		t.Fatalf("Failed to arrange: %v", err)
+	}
+}
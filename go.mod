@@ -1,3 +1,8 @@
 module agent-dev-environment
 
 go 1.25.0
+
+require (
+	github.com/fsnotify/fsnotify v1.9.0
+	golang.org/x/sys v0.41.0
+)
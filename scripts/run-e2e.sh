@@ -27,21 +27,47 @@ mkdir -p "$TEST_DIR"
 # We use 'sed -u' (unbuffered) so logs appear instantly.
 echo "Starting API..."
 export AGENT_DEV_ENVIRONMENT_LOGGING_TYPE=plain
-./bin/agent-dev-environment > >(sed -u "s/^/[$APP_ID] /") 2>&1 &
-API_PID=$!
-
-# 3. Wait for the API to be ready (Loop until connection succeeds)
-echo "Waiting for API to be ready..."
-for i in {1..30}; do
-  if curl -s http://localhost:8080/health > /dev/null; then
-    echo "API is up!"
-    break
-  fi
-  sleep 0.5
-done
+# Confine the API to the test directory; the repository stays reachable for reload_env
+export AGENT_DEV_ENVIRONMENT_WORKSPACE_ROOT="$TEST_DIR"
+export AGENT_DEV_ENVIRONMENT_ALLOWED_ROOTS="$(pwd)"
+# The default rules plus ones the policy tests exercise
+export AGENT_DEV_ENVIRONMENT_PROTECTED_PATHS=".git/hooks/**=readonly,.git/config=readonly,.github/workflows/**=readonly,e2e_denied/**=deny,e2e_approval/**=require-approval"
+# Small enough for the archive tests to exceed
+export AGENT_DEV_ENVIRONMENT_ARCHIVE_MAX_BYTES=10485760
+# Small enough for the quota tests to exceed
+export AGENT_DEV_ENVIRONMENT_QUOTA_MAX_FILE_BYTES=1048576
+start_api() {
+  ./bin/agent-dev-environment > >(sed -u "s/^/[$APP_ID] /") 2>&1 &
+  API_PID=$!
+
+  # 3. Wait for the API to be ready (Loop until connection succeeds)
+  echo "Waiting for API to be ready..."
+  for i in {1..30}; do
+    if curl -s http://localhost:8080/health > /dev/null; then
+      echo "API is up!"
+      break
+    fi
+    sleep 0.5
+  done
+}
+start_api
 
 # 4. Run the blackbox tests
 # Pass the API URL so tests know where to hit
 echo "Running E2E tests..."
 export API_URL="http://localhost:8080"
 go test ./e2e/... -v -count=1 2>&1 | sed -u "s/^/[e2e-tests] /"
+
+# 5. Run the search tests again without ripgrep, which the run above uses
+# whenever it is installed: once with the built-in engine walking the tree
+# and once narrowed by the trigram index
+SEARCH_PACKAGES="./e2e/features/filesystem/search ./e2e/features/filesystem/search_index"
+for config in "AGENT_DEV_ENVIRONMENT_SEARCH_ENGINE=native" "AGENT_DEV_ENVIRONMENT_SEARCH_INDEX=startup"; do
+  echo "Restarting API with $config..."
+  kill $API_PID 2>/dev/null || true
+  wait $API_PID 2>/dev/null || true
+  export "$config" AGENT_DEV_ENVIRONMENT_SEARCH_INDEX_ROOT="$TEST_DIR"
+  start_api
+  go test $SEARCH_PACKAGES -v -count=1 2>&1 | sed -u "s/^/[e2e-tests] /"
+  unset "${config%%=*}"
+done
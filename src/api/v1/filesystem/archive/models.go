@@ -0,0 +1,59 @@
+package archive
+
+import (
+	"slices"
+
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/archive"
+)
+
+// ExportRequest is read from the query string of a GET request.
+type ExportRequest struct {
+	Path           string `json:"path"`
+	Format         string `json:"format"`          // tar.gz (default) or zip
+	ExcludeIgnored bool   `json:"exclude_ignored"` // Leave out files excluded by .gitignore, and the .git directory
+}
+
+func (r ExportRequest) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	return validateFormat(r.Format)
+}
+
+func (r *ExportRequest) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+// ImportRequest is read from the query string; the body is the archive.
+type ImportRequest struct {
+	Path      string `json:"path"`      // Directory to unpack into, created if missing
+	Format    string `json:"format"`    // tar.gz (default) or zip
+	Overwrite bool   `json:"overwrite"` // Replace existing files instead of failing
+}
+
+func (r ImportRequest) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	return validateFormat(r.Format)
+}
+
+func (r *ImportRequest) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+type ImportResponse struct {
+	Path     string `json:"path"`
+	Files    int    `json:"files"`
+	Dirs     int    `json:"dirs"`
+	Symlinks int    `json:"symlinks"`
+	Bytes    int64  `json:"bytes"` // Size of the unpacked files
+}
+
+func validateFormat(format string) error {
+	if format != "" && !slices.Contains(archive.Formats, format) {
+		return api.NewError(api.BadRequest, "Format must be 'tar.gz' or 'zip'")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"agent-dev-environment/src/library/api"
+)
+
+const MaxOperations = 1000
+
+// Ops are the operations a batch can run, named after their endpoints. The
+// batch feature fills it in from the handlers it runs them with.
+var Ops []string
+
+const (
+	StatusOK      = "ok"
+	StatusError   = "error"
+	StatusSkipped = "skipped" // Not run because an earlier operation failed
+)
+
+type Operation struct {
+	Op string `json:"op"`
+	// Request is the body the op's own endpoint takes, such as a
+	// mkdir.Request for "mkdir" or a create_file.Request for "create_file"
+	Request json.RawMessage `json:"request"`
+}
+
+type Request struct {
+	Operations      []Operation `json:"operations"`        // Run in order
+	ContinueOnError bool        `json:"continue_on_error"` // Run the remaining operations after one fails instead of skipping them
+}
+
+func (r Request) Validate() error {
+	if len(r.Operations) == 0 {
+		return api.NewError(api.BadRequest, "Operations are required")
+	}
+	if len(r.Operations) > MaxOperations {
+		return api.NewError(api.BadRequest, "Operations cannot exceed 1000")
+	}
+	for i, op := range r.Operations {
+		if !slices.Contains(Ops, op.Op) {
+			return api.NewError(api.BadRequest, fmt.Sprintf("Operation %d: unknown op %q", i, op.Op))
+		}
+	}
+	return nil
+}
+
+type Result struct {
+	Op       string          `json:"op"`
+	Status   string          `json:"status"`         // ok, error or skipped
+	Code     int             `json:"code,omitempty"` // HTTP status the operation's endpoint would have returned, for errors
+	Error    string          `json:"error,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"` // What the operation's endpoint would have returned, on success
+}
+
+type Response struct {
+	Results   []Result `json:"results"` // One per operation, in order
+	Succeeded int      `json:"succeeded"`
+	Failed    int      `json:"failed"`
+	Skipped   int      `json:"skipped"`
+}
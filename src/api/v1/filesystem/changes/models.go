@@ -0,0 +1,33 @@
+package changes
+
+import (
+	"agent-dev-environment/src/library/api"
+)
+
+type Request struct {
+	Path           string `json:"path"`            // Directory whose files are tracked
+	Cursor         string `json:"cursor"`          // From an earlier response for the same path. Empty starts tracking
+	IncludeIgnored bool   `json:"include_ignored"` // Also track files excluded by .gitignore. Fixed when tracking starts
+}
+
+func (r Request) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	return nil
+}
+
+func (r *Request) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+// Response lists the regular files that changed since the request's cursor,
+// however they were changed, as absolute paths. A file replaced by a rename
+// counts as modified; a moved file is deleted at its old path and created at
+// the new one.
+type Response struct {
+	Cursor   string   `json:"cursor"` // Pass in the next request to get the changes after this one
+	Created  []string `json:"created"`
+	Modified []string `json:"modified"`
+	Deleted  []string `json:"deleted"`
+}
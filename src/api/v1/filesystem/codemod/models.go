@@ -0,0 +1,88 @@
+package codemod
+
+import (
+	"regexp"
+
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/glob"
+)
+
+const (
+	DefaultContextLines = 2
+	MaxContextLines     = 10
+	MaxChanges          = 5000
+)
+
+type PreviewRequest struct {
+	Path         string   `json:"path"` // Directory or file to rewrite
+	Pattern      string   `json:"pattern"`
+	Regex        bool     `json:"regex"` // Treat Pattern as RE2; Replacement may then use $1 or ${name}
+	IgnoreCase   bool     `json:"ignore_case"`
+	Replacement  string   `json:"replacement"`
+	Include      []string `json:"include,omitempty"` // Globs relative to Path; patterns without '/' match file names
+	Exclude      []string `json:"exclude,omitempty"`
+	ContextLines *int     `json:"context_lines,omitempty"` // Defaults to DefaultContextLines
+}
+
+func (r PreviewRequest) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	if r.Pattern == "" {
+		return api.NewError(api.BadRequest, "Pattern is required")
+	}
+	if r.Regex {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return api.NewError(api.BadRequest, "Invalid pattern: "+err.Error())
+		}
+	}
+	for _, pattern := range append(append([]string{}, r.Include...), r.Exclude...) {
+		if err := glob.Validate(pattern); err != nil {
+			return api.NewError(api.BadRequest, err.Error())
+		}
+	}
+	if r.ContextLines != nil && (*r.ContextLines < 0 || *r.ContextLines > MaxContextLines) {
+		return api.NewError(api.BadRequest, "Context lines must be between 0 and 10")
+	}
+	// Replacement can be empty (for deletion)
+	return nil
+}
+
+func (r *PreviewRequest) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+type Change struct {
+	ID            string   `json:"id"`
+	Path          string   `json:"path"`
+	Line          int      `json:"line"`   // 1-based line where the match starts
+	Column        int      `json:"column"` // 1-based byte offset within that line
+	Before        string   `json:"before"` // Lines spanned by the match
+	After         string   `json:"after"`  // The same lines with only this change applied
+	ContextBefore []string `json:"context_before"`
+	ContextAfter  []string `json:"context_after"`
+}
+
+type PreviewResponse struct {
+	PreviewID     string   `json:"preview_id"`
+	Changes       []Change `json:"changes"`
+	TotalChanges  int      `json:"total_changes"`
+	FilesAffected int      `json:"files_affected"`
+}
+
+type ApplyRequest struct {
+	PreviewID string   `json:"preview_id"`
+	ChangeIDs []string `json:"change_ids,omitempty"` // Empty applies every change of the preview
+}
+
+func (r ApplyRequest) Validate() error {
+	if r.PreviewID == "" {
+		return api.NewError(api.BadRequest, "Preview ID is required")
+	}
+	return nil
+}
+
+type ApplyResponse struct {
+	Applied      int      `json:"applied"`
+	FilesChanged []string `json:"files_changed"`
+}
@@ -0,0 +1,42 @@
+package copy
+
+import (
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/glob"
+)
+
+type Request struct {
+	Source         string   `json:"source"`
+	Destination    string   `json:"destination"`
+	Overwrite      bool     `json:"overwrite"`         // Replace existing files and merge into existing directories
+	PreserveMode   bool     `json:"preserve_mode"`     // Copy permission bits exactly instead of applying the umask
+	PreserveTimes  bool     `json:"preserve_times"`    // Copy modification and access times
+	FollowSymlinks bool     `json:"follow_symlinks"`   // Copy what symlinks point to instead of the links
+	Exclude        []string `json:"exclude,omitempty"` // Globs matched against paths relative to the source
+}
+
+func (r Request) Validate() error {
+	if r.Source == "" {
+		return api.NewError(api.BadRequest, "Source path is required")
+	}
+	if r.Destination == "" {
+		return api.NewError(api.BadRequest, "Destination path is required")
+	}
+	for _, pattern := range r.Exclude {
+		if err := glob.Validate(pattern); err != nil {
+			return api.NewError(api.BadRequest, err.Error())
+		}
+	}
+	return nil
+}
+
+func (r *Request) Paths() []*string {
+	return []*string{&r.Source, &r.Destination}
+}
+
+type Response struct {
+	FilesCopied    int   `json:"files_copied"`
+	BytesCopied    int64 `json:"bytes_copied"`
+	SymlinksCopied int   `json:"symlinks_copied"`
+	Excluded       int   `json:"excluded"` // Entries skipped by exclude patterns, or followed symlinks leading outside the workspace
+}
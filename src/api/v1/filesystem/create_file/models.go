@@ -13,4 +13,8 @@ func (r Request) Validate() error {
 	}
 	// Content can be empty, so no validation needed for it for now.
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *Request) Paths() []*string {
+	return []*string{&r.Path}
+}
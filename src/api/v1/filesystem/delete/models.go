@@ -1,15 +1,75 @@
 package delete
 
-import "agent-dev-environment/src/library/api"
+import (
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/glob"
+)
+
+const (
+	DefaultMaxItems = 100
+	MaxItemsLimit   = 10000
+)
 
 type Request struct {
 	Path      string `json:"path"`
 	Recursive bool   `json:"recursive"`
+	Permanent bool   `json:"permanent"` // Remove immediately instead of moving to the trash
+	// Patterns turns Path into a base directory and deletes the entries below
+	// it matching any of these globs, e.g. "**/*.test", "dist/" or
+	// "coverage.out". Patterns without a '/' match names at any depth, and a
+	// trailing '/' only matches directories. Directories need Recursive.
+	Patterns []string `json:"patterns,omitempty"`
+	DryRun   bool     `json:"dry_run"`             // List what would be deleted without deleting it
+	MaxItems *int     `json:"max_items,omitempty"` // Refuse to delete more entries than this. Defaults to DefaultMaxItems
 }
 
 func (r Request) Validate() error {
 	if r.Path == "" {
 		return api.NewError(api.BadRequest, "Path is required")
 	}
+	for _, pattern := range r.Patterns {
+		if pattern == "" || pattern == "/" {
+			return api.NewError(api.BadRequest, "Patterns cannot be empty")
+		}
+		if err := glob.Validate(pattern); err != nil {
+			return api.NewError(api.BadRequest, err.Error())
+		}
+	}
+	if r.MaxItems != nil && (*r.MaxItems < 1 || *r.MaxItems > MaxItemsLimit) {
+		return api.NewError(api.BadRequest, "Max items must be between 1 and 10000")
+	}
 	return nil
 }
+
+// Paths confines a base directory fully, as patterns are matched below
+// wherever it leads.
+func (r *Request) Paths() []*string {
+	if len(r.Patterns) == 0 {
+		return nil
+	}
+	return []*string{&r.Path}
+}
+
+// LinkPaths confines only the directory holding a single path, as a symlink
+// is deleted itself, wherever it points.
+func (r *Request) LinkPaths() []*string {
+	if len(r.Patterns) > 0 {
+		return nil
+	}
+	return []*string{&r.Path}
+}
+
+type Item struct {
+	Path      string `json:"path"`
+	IsDir     bool   `json:"is_dir"`
+	IsSymlink bool   `json:"is_symlink,omitempty"` // The link itself was deleted, not what it points to
+	Size      int64  `json:"size"`                 // Total size of the files removed with this entry
+	TrashID   string `json:"trash_id,omitempty"`   // Set unless the delete was permanent or a dry run
+}
+
+type Response struct {
+	TrashID    string `json:"trash_id,omitempty"` // Set for a single path moved to the trash
+	Items      []Item `json:"items"`              // Entries deleted, or that would be deleted on a dry run
+	TotalBytes int64  `json:"total_bytes"`
+	DryRun     bool   `json:"dry_run"`
+}
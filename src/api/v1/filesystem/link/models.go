@@ -0,0 +1,69 @@
+package link
+
+import "agent-dev-environment/src/library/api"
+
+const (
+	TypeSymlink  = "symlink"
+	TypeHardlink = "hardlink"
+	TypeFile     = "file"
+	TypeDir      = "dir"
+	TypeOther    = "other"
+)
+
+type CreateRequest struct {
+	Path string `json:"path"` // Link to create
+	// Target of a symlink is stored as given, so relative targets resolve
+	// from the link's directory. Target of a hard link is an existing file.
+	Target string `json:"target"`
+	Type   string `json:"type,omitempty"` // symlink (default) or hardlink
+}
+
+func (r CreateRequest) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	if r.Target == "" {
+		return api.NewError(api.BadRequest, "Target is required")
+	}
+	if r.Type != "" && r.Type != TypeSymlink && r.Type != TypeHardlink {
+		return api.NewError(api.BadRequest, "Type must be 'symlink' or 'hardlink'")
+	}
+	return nil
+}
+
+// Paths leaves symlink targets alone, as they are stored verbatim and the
+// handler checks where they lead.
+func (r *CreateRequest) Paths() []*string {
+	if r.Type == TypeHardlink {
+		return []*string{&r.Path, &r.Target}
+	}
+	return []*string{&r.Path}
+}
+
+type ReadRequest struct {
+	Path string `json:"path"`
+}
+
+func (r ReadRequest) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	return nil
+}
+
+// LinkPaths confines only the link's directory, so links leading outside the
+// workspace can be inspected.
+func (r *ReadRequest) LinkPaths() []*string {
+	return []*string{&r.Path}
+}
+
+type Link struct {
+	Path         string `json:"path"`
+	Type         string `json:"type"`             // symlink, file, dir or other
+	Target       string `json:"target,omitempty"` // Contents of a symlink
+	Resolved     string `json:"resolved"`         // Path once every symlink is followed
+	TargetExists bool   `json:"target_exists"`    // Whether Resolved exists; false for dangling symlinks
+	Inside       bool   `json:"inside_workspace"` // Whether Resolved lies within the workspace
+	Links        uint64 `json:"links,omitempty"`  // Hard links to the file, including this one
+	Inode        uint64 `json:"inode,omitempty"`  // Shared by every hard link to the same file
+}
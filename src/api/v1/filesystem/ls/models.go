@@ -1,18 +1,85 @@
 package ls
 
 import (
+	"time"
+
 	"agent-dev-environment/src/library/api"
 )
 
+const (
+	DefaultMaxDepth = 3
+	MaxDepthLimit   = 10
+	MaxLimit        = 1000
+)
+
+const (
+	SortByName  = "name"
+	SortBySize  = "size"
+	SortByMtime = "mtime"
+	SortByType  = "type"
+)
+
+const (
+	TypeFile    = "file"
+	TypeDir     = "dir"
+	TypeSymlink = "symlink"
+	TypeOther   = "other"
+)
+
 type Request struct {
-	Path      string `json:"path"`
-	Recursive bool   `json:"recursive"`
-	Long      bool   `json:"long"`
+	Path       string `json:"path"`
+	Recursive  bool   `json:"recursive"`
+	MaxDepth   *int   `json:"max_depth,omitempty"` // Only used when recursive, defaults to DefaultMaxDepth
+	ShowHidden bool   `json:"show_hidden"`
+	SortBy     string `json:"sort_by,omitempty"` // name (default), size, mtime or type; size and mtime list largest/newest first
+	Reverse    bool   `json:"reverse"`
+	Offset     *int   `json:"offset,omitempty"` // 0-based index of the first entry
+	Limit      *int   `json:"limit,omitempty"`  // Number of entries to return
 }
 
 func (r Request) Validate() error {
 	if r.Path == "" {
 		return api.NewError(api.BadRequest, "Path is required")
 	}
+	if r.MaxDepth != nil && (*r.MaxDepth < 1 || *r.MaxDepth > MaxDepthLimit) {
+		return api.NewError(api.BadRequest, "Max depth must be between 1 and 10")
+	}
+	switch r.SortBy {
+	case "", SortByName, SortBySize, SortByMtime, SortByType:
+	default:
+		return api.NewError(api.BadRequest, "Sort by must be one of 'name', 'size', 'mtime' or 'type'")
+	}
+	if r.Offset != nil && *r.Offset < 0 {
+		return api.NewError(api.BadRequest, "Offset cannot be negative")
+	}
+	if r.Limit != nil && *r.Limit <= 0 {
+		return api.NewError(api.BadRequest, "Limit must be greater than 0")
+	}
+	if r.Limit != nil && *r.Limit > MaxLimit {
+		return api.NewError(api.BadRequest, "Limit cannot exceed 1000 entries")
+	}
 	return nil
 }
+
+func (r *Request) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+type Entry struct {
+	Name          string    `json:"name"` // Relative to the listed directory
+	Path          string    `json:"path"`
+	Type          string    `json:"type"` // file, dir, symlink or other
+	Size          int64     `json:"size"`
+	Mode          string    `json:"mode"`
+	ModifiedAt    time.Time `json:"modified_at"`
+	SymlinkTarget string    `json:"symlink_target,omitempty"`
+	ChildCount    *int      `json:"child_count,omitempty"` // Only set for directories
+}
+
+type Response struct {
+	Entries []Entry `json:"entries"`
+	// Total counts every entry, except for a limited recursive listing in
+	// name order, which stops one past the page and counts only what it saw
+	Total   int  `json:"total"`
+	HasMore bool `json:"has_more"`
+}
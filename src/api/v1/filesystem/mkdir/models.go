@@ -12,3 +12,7 @@ func (r Request) Validate() error {
 	}
 	return nil
 }
+
+func (r *Request) Paths() []*string {
+	return []*string{&r.Path}
+}
@@ -6,7 +6,8 @@ import (
 
 type Request struct {
 	Source      string `json:"source"`
-	Destination string `json:"destination"`
+	Destination string `json:"destination"` // Moving onto an existing directory moves the source into it
+	Overwrite   bool   `json:"overwrite"`   // Replace an existing file or empty directory at the destination
 }
 
 func (r Request) Validate() error {
@@ -18,3 +19,18 @@ func (r Request) Validate() error {
 	}
 	return nil
 }
+
+func (r *Request) Paths() []*string {
+	return []*string{&r.Destination}
+}
+
+// LinkPaths confines only the directory holding the source, as a symlink is
+// moved itself, wherever it points.
+func (r *Request) LinkPaths() []*string {
+	return []*string{&r.Source}
+}
+
+type Response struct {
+	Destination string `json:"destination"`  // Final path of the moved entry
+	CrossDevice bool   `json:"cross_device"` // Moved by copying and deleting because the paths are on different filesystems
+}
@@ -0,0 +1,58 @@
+package raw
+
+import (
+	"encoding/hex"
+
+	"agent-dev-environment/src/library/api"
+)
+
+// ChecksumHeader carries the hex SHA-256 of a downloaded file when asked for.
+const ChecksumHeader = "X-Checksum-Sha256"
+
+// DownloadRequest is read from the query string. The response body is the
+// file's content; Range requests are supported.
+type DownloadRequest struct {
+	Path     string `json:"path"`
+	Checksum bool   `json:"checksum"` // Send the file's SHA-256 in the X-Checksum-Sha256 header; reads the file twice
+}
+
+func (r DownloadRequest) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	return nil
+}
+
+func (r *DownloadRequest) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+// UploadRequest is read from the query string; the body is the content.
+type UploadRequest struct {
+	Path      string `json:"path"`
+	Overwrite bool   `json:"overwrite"` // Replace an existing file instead of failing
+	SHA256    string `json:"sha256"`    // Expected hex SHA-256 of the body. The file is not written if it differs
+}
+
+func (r UploadRequest) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	if r.SHA256 != "" {
+		if b, err := hex.DecodeString(r.SHA256); err != nil || len(b) != 32 {
+			return api.NewError(api.BadRequest, "SHA256 must be 64 hex characters")
+		}
+	}
+	return nil
+}
+
+func (r *UploadRequest) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+type UploadResponse struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+	Created bool   `json:"created"` // False when an existing file was replaced
+}
@@ -24,9 +24,14 @@ func (r Request) Validate() error {
 	return nil
 }
 
+func (r *Request) Paths() []*string {
+	return []*string{&r.Path}
+}
+
 type Response struct {
-	Content     string `json:"content"`
-	TotalLines  int    `json:"total_lines"`
-	HasMore     bool   `json:"has_more"`
-	LinesRead   int    `json:"lines_read"`
+	Content       string `json:"content"`
+	TotalLines    int    `json:"total_lines"`
+	HasMore       bool   `json:"has_more"`
+	LinesRead     int    `json:"lines_read"`
+	SymlinkTarget string `json:"symlink_target,omitempty"` // Set when Path is a symlink; Content is then the target's
 }
\ No newline at end of file
@@ -19,6 +19,11 @@ func (r Request) Validate() error {
 	return nil
 }
 
+func (r *Request) Paths() []*string {
+	return []*string{&r.Path}
+}
+
 type Response struct {
-	Path string `json:"path"`
+	Path          string `json:"path"`
+	SymlinkTarget string `json:"symlink_target,omitempty"` // Set when Path is a symlink; the target's content was replaced
 }
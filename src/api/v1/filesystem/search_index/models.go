@@ -0,0 +1,17 @@
+package search_index
+
+import "time"
+
+type StatusResponse struct {
+	Enabled         bool       `json:"enabled"`
+	State           string     `json:"state"` // disabled, pending, building, ready or failed
+	Root            string     `json:"root,omitempty"`
+	Files           int        `json:"files"`
+	Trigrams        int        `json:"trigrams"`
+	Postings        int        `json:"postings"`
+	MemoryBytes     int64      `json:"memory_bytes"` // Estimated heap used by the index
+	BuildDurationMs int64      `json:"build_duration_ms"`
+	BuiltAt         *time.Time `json:"built_at,omitempty"`
+	LastRefreshAt   *time.Time `json:"last_refresh_at,omitempty"`
+	Error           string     `json:"error,omitempty"`
+}
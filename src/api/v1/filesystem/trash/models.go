@@ -0,0 +1,62 @@
+package trash
+
+import (
+	"time"
+
+	"agent-dev-environment/src/library/api"
+)
+
+type Entry struct {
+	ID           string    `json:"id"`
+	OriginalPath string    `json:"original_path"`
+	DeletedAt    time.Time `json:"deleted_at"`
+	RequestID    string    `json:"request_id,omitempty"` // X-Request-ID of the delete
+	IsDir        bool      `json:"is_dir"`
+	Size         int64     `json:"size"`
+}
+
+type ListResponse struct {
+	Entries    []Entry `json:"entries"` // Most recently deleted first
+	TotalBytes int64   `json:"total_bytes"`
+}
+
+type RestoreRequest struct {
+	ID          string `json:"id"`
+	Destination string `json:"destination,omitempty"` // Defaults to the original path
+	Overwrite   bool   `json:"overwrite"`
+}
+
+func (r RestoreRequest) Validate() error {
+	if r.ID == "" {
+		return api.NewError(api.BadRequest, "ID is required")
+	}
+	return nil
+}
+
+func (r *RestoreRequest) Paths() []*string {
+	return []*string{&r.Destination}
+}
+
+type RestoreResponse struct {
+	Path string `json:"path"`
+}
+
+type PurgeRequest struct {
+	IDs []string `json:"ids,omitempty"`
+	All bool     `json:"all"` // Required to purge everything, guarding against an empty ids list
+}
+
+func (r PurgeRequest) Validate() error {
+	if len(r.IDs) == 0 && !r.All {
+		return api.NewError(api.BadRequest, "IDs or all is required")
+	}
+	if len(r.IDs) > 0 && r.All {
+		return api.NewError(api.BadRequest, "IDs and all cannot be combined")
+	}
+	return nil
+}
+
+type PurgeResponse struct {
+	Purged     int   `json:"purged"`
+	BytesFreed int64 `json:"bytes_freed"`
+}
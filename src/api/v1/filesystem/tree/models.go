@@ -0,0 +1,74 @@
+package tree
+
+import "agent-dev-environment/src/library/api"
+
+const (
+	DefaultMaxDepth         = 3
+	MaxDepthLimit           = 20
+	DefaultMaxEntriesPerDir = 50
+	MaxEntriesPerDirLimit   = 1000
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+const (
+	TypeFile    = "file"
+	TypeDir     = "dir"
+	TypeSymlink = "symlink"
+	TypeOther   = "other"
+	// TypeSummary stands in for entries omitted because of MaxEntriesPerDir
+	TypeSummary = "summary"
+)
+
+// Reasons a directory was collapsed into a summary node
+const (
+	CollapsedIgnored = "ignored"
+	CollapsedDepth   = "depth"
+)
+
+type Request struct {
+	Path             string `json:"path"`
+	MaxDepth         *int   `json:"max_depth,omitempty"`           // Defaults to DefaultMaxDepth
+	MaxEntriesPerDir *int   `json:"max_entries_per_dir,omitempty"` // Defaults to DefaultMaxEntriesPerDir
+	ShowHidden       bool   `json:"show_hidden"`
+	IncludeIgnored   bool   `json:"include_ignored"`  // Expand ignored directories instead of collapsing them
+	Format           string `json:"format,omitempty"` // text (default) or json
+}
+
+func (r Request) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	if r.MaxDepth != nil && (*r.MaxDepth < 1 || *r.MaxDepth > MaxDepthLimit) {
+		return api.NewError(api.BadRequest, "Max depth must be between 1 and 20")
+	}
+	if r.MaxEntriesPerDir != nil && (*r.MaxEntriesPerDir < 1 || *r.MaxEntriesPerDir > MaxEntriesPerDirLimit) {
+		return api.NewError(api.BadRequest, "Max entries per directory must be between 1 and 1000")
+	}
+	if r.Format != "" && r.Format != FormatText && r.Format != FormatJSON {
+		return api.NewError(api.BadRequest, "Format must be 'text' or 'json'")
+	}
+	return nil
+}
+
+func (r *Request) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+type Node struct {
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Children  []*Node `json:"children,omitempty"`
+	Collapsed string  `json:"collapsed,omitempty"`  // Set when a directory's contents were summarised
+	FileCount int     `json:"file_count,omitempty"` // Files below a collapsed directory
+	Truncated bool    `json:"truncated,omitempty"`  // FileCount stopped at the counting limit
+	Omitted   int     `json:"omitted,omitempty"`    // Entries hidden by a summary node
+}
+
+type Response struct {
+	Tree string `json:"tree,omitempty"` // Set for the text format
+	Root *Node  `json:"root,omitempty"` // Set for the json format
+}
@@ -0,0 +1,14 @@
+package usage
+
+import "time"
+
+// Response reports the disk quota. Limits of 0 are not enforced.
+type Response struct {
+	Root         string    `json:"root"`  // Directory whose total size and file count are limited
+	Bytes        int64     `json:"bytes"` // Total size of the regular files below Root
+	Files        int64     `json:"files"` // Number of regular files below Root
+	MaxBytes     int64     `json:"max_bytes"`
+	MaxFileBytes int64     `json:"max_file_bytes"` // Applies to files written anywhere
+	MaxFiles     int64     `json:"max_files"`
+	ScannedAt    time.Time `json:"scanned_at"`
+}
@@ -0,0 +1,51 @@
+package watch
+
+import (
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/glob"
+)
+
+const (
+	DefaultDebounceMs = 200
+	MaxDebounceMs     = 10000
+)
+
+// Request is read from the query string. The response is a stream of
+// server-sent events: one "ready" event once the subtree is watched, then a
+// "change" event carrying an Event for every change.
+type Request struct {
+	Path           string   `json:"path"`    // Directory to watch, with everything below it
+	Include        []string `json:"include"` // Globs relative to Path; patterns without '/' match file names
+	Exclude        []string `json:"exclude"`
+	IncludeIgnored bool     `json:"include_ignored"` // Also report changes to files excluded by .gitignore
+	DebounceMs     int      `json:"debounce_ms"`     // Changes to one path within this window are reported once. 0 uses DefaultDebounceMs
+}
+
+func (r Request) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	for _, pattern := range append(append([]string{}, r.Include...), r.Exclude...) {
+		if err := glob.Validate(pattern); err != nil {
+			return api.NewError(api.BadRequest, err.Error())
+		}
+	}
+	if r.DebounceMs < 0 || r.DebounceMs > MaxDebounceMs {
+		return api.NewError(api.BadRequest, "Debounce must be between 0 and 10000 milliseconds")
+	}
+	return nil
+}
+
+func (r *Request) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+// Event is the data of a "change" event. Paths are absolute. A file moved
+// within the watched subtree is reported as a rename of the old path and a
+// create of the new one. An "overflow" event for Path means changes were
+// lost and the subtree should be rescanned.
+type Event struct {
+	Op    string `json:"op"` // create, write, remove, rename or overflow
+	Path  string `json:"path"`
+	IsDir bool   `json:"is_dir,omitempty"`
+}
@@ -0,0 +1,67 @@
+package journal
+
+import (
+	"time"
+
+	"agent-dev-environment/src/library/api"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 1000
+)
+
+type Change struct {
+	Path string `json:"path"`
+	From string `json:"from,omitempty"` // Source of a move
+	Kind string `json:"kind"`           // created, modified, deleted or moved
+}
+
+type Entry struct {
+	ID         string     `json:"id"`
+	Op         string     `json:"op"` // create_file, replace, move, delete, mkdir, symlink, hardlink, upload, restore, copy, codemod, import or snapshot_restore
+	RequestID  string     `json:"request_id,omitempty"`
+	Time       time.Time  `json:"time"`
+	Changes    []Change   `json:"changes"`
+	Reversible bool       `json:"reversible"` // False when the previous state was not kept, as for permanent deletes
+	UndoneAt   *time.Time `json:"undone_at,omitempty"`
+}
+
+type ListRequest struct {
+	Path  string `json:"path,omitempty"`  // Only entries that touched this path, something below it or a directory containing it
+	Limit int    `json:"limit,omitempty"` // Defaults to 50
+}
+
+func (r ListRequest) Validate() error {
+	if r.Limit < 0 || r.Limit > MaxLimit {
+		return api.NewError(api.BadRequest, "Limit must be between 0 and 1000")
+	}
+	return nil
+}
+
+func (r *ListRequest) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+type ListResponse struct {
+	Entries []Entry `json:"entries"` // Newest first
+}
+
+type UndoRequest struct {
+	ID    string `json:"id,omitempty"`    // Operation to undo
+	Count int    `json:"count,omitempty"` // Undo the last count operations not yet undone. Defaults to 1 without an ID
+}
+
+func (r UndoRequest) Validate() error {
+	if r.ID != "" && r.Count != 0 {
+		return api.NewError(api.BadRequest, "ID and count cannot be combined")
+	}
+	if r.Count < 0 || r.Count > MaxLimit {
+		return api.NewError(api.BadRequest, "Count must be between 0 and 1000")
+	}
+	return nil
+}
+
+type UndoResponse struct {
+	Undone []Entry `json:"undone"` // In the order they were undone, newest first
+}
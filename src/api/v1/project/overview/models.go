@@ -0,0 +1,57 @@
+package overview
+
+import "agent-dev-environment/src/library/api"
+
+type Request struct {
+	Path string `json:"path"`
+}
+
+func (r Request) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	return nil
+}
+
+func (r *Request) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+type Language struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	Lines int    `json:"lines"`
+	Bytes int64  `json:"bytes"`
+}
+
+type Manifest struct {
+	Path string `json:"path"`           // Relative to the requested path
+	Kind string `json:"kind"`           // go, npm, cargo, python, make or mise
+	Name string `json:"name,omitempty"` // Module or package name declared in the manifest
+}
+
+type TestDir struct {
+	Path  string `json:"path"`
+	Files int    `json:"files"` // Test files below the directory
+}
+
+type EntryPoint struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"` // How the entry point was detected, e.g. "go main" or "package.json bin"
+}
+
+type DirUsage struct {
+	Path  string `json:"path"` // Top-level entry; files directly in the root are grouped under "."
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+type Response struct {
+	Languages   []Language   `json:"languages"` // Most lines first
+	Manifests   []Manifest   `json:"manifests"`
+	TestDirs    []TestDir    `json:"test_dirs"`
+	EntryPoints []EntryPoint `json:"entry_points"`
+	DiskUsage   []DirUsage   `json:"disk_usage"` // Largest first
+	TotalFiles  int          `json:"total_files"`
+	TotalBytes  int64        `json:"total_bytes"`
+}
@@ -0,0 +1,52 @@
+package scratch
+
+import (
+	"regexp"
+	"time"
+
+	"agent-dev-environment/src/library/api"
+)
+
+const DefaultPrefix = "scratch"
+
+var prefixPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]{0,31}$`)
+
+type Dir struct {
+	ID        string     `json:"id"`
+	Path      string     `json:"path"`
+	Session   string     `json:"session"` // Session the directory is deleted with, unless it has a TTL
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Set when created with a TTL
+}
+
+type CreateRequest struct {
+	Prefix string `json:"prefix,omitempty"` // Start of the directory name, defaults to "scratch"
+	TTL    string `json:"ttl,omitempty"`    // Go duration after which the directory is deleted; without one it is deleted when the session ends
+}
+
+func (r CreateRequest) Validate() error {
+	if r.Prefix != "" && !prefixPattern.MatchString(r.Prefix) {
+		return api.NewError(api.BadRequest, "Prefix must be at most 32 letters, digits, '.', '_' or '-' and not start with '.'")
+	}
+	if r.TTL != "" {
+		if ttl, err := time.ParseDuration(r.TTL); err != nil || ttl <= 0 {
+			return api.NewError(api.BadRequest, "TTL must be a positive Go duration, like \"30m\"")
+		}
+	}
+	return nil
+}
+
+type ListResponse struct {
+	Dirs []Dir `json:"dirs"` // Newest first
+}
+
+type DeleteRequest struct {
+	ID string `json:"id"`
+}
+
+func (r DeleteRequest) Validate() error {
+	if r.ID == "" {
+		return api.NewError(api.BadRequest, "ID is required")
+	}
+	return nil
+}
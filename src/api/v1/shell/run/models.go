@@ -7,8 +7,19 @@ import (
 )
 
 type Request struct {
-	Command string   `json:"command"`
-	Args    []string `json:"args"`
+	Command   string   `json:"command"`
+	Args      []string `json:"args"`
+	TimeoutMs int      `json:"timeout_ms,omitempty"` // Kill the command after this long; 0 waits for it to finish
+}
+
+// Response describes a command that ran, whether or not it succeeded.
+type Response struct {
+	ExitCode   int    `json:"exit_code"` // -1 when the command was killed by a signal
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	DurationMs int64  `json:"duration_ms"`
+	TimedOut   bool   `json:"timed_out"`
+	Signal     string `json:"signal,omitempty"` // Signal that killed the command, like "killed"
 }
 
 var allowedCommands = map[string]func([]string) error{
@@ -50,6 +61,10 @@ var allowedCommands = map[string]func([]string) error{
 }
 
 func (r Request) Validate() error {
+	if r.TimeoutMs < 0 {
+		return api.NewError(api.BadRequest, "Timeout must not be negative")
+	}
+
 	validator, ok := allowedCommands[r.Command]
 	if !ok {
 		return api.NewError(api.BadRequest, fmt.Sprintf("command '%s' is not allowed", r.Command))
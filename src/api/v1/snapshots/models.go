@@ -0,0 +1,77 @@
+package snapshots
+
+import (
+	"time"
+
+	"agent-dev-environment/src/library/api"
+)
+
+type Snapshot struct {
+	ID        string    `json:"id"`
+	Root      string    `json:"root"`
+	Label     string    `json:"label,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     int       `json:"files"`
+	Bytes     int64     `json:"bytes"`     // Total size of the captured files
+	NewBytes  int64     `json:"new_bytes"` // Content not already stored by earlier snapshots
+}
+
+type CreateRequest struct {
+	Path           string `json:"path"` // Directory to capture
+	Label          string `json:"label,omitempty"`
+	IncludeIgnored bool   `json:"include_ignored"` // Also capture files excluded by .gitignore
+}
+
+func (r CreateRequest) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	return nil
+}
+
+func (r *CreateRequest) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+type ListRequest struct {
+	Path string `json:"path,omitempty"` // Only list snapshots of this directory
+}
+
+func (r *ListRequest) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+type ListResponse struct {
+	Snapshots []Snapshot `json:"snapshots"` // Newest first
+}
+
+type RestoreRequest struct {
+	ID    string   `json:"id"`
+	Paths []string `json:"paths,omitempty"` // Restore only these files or directories, absolute or relative to the snapshot root
+}
+
+func (r RestoreRequest) Validate() error {
+	if r.ID == "" {
+		return api.NewError(api.BadRequest, "ID is required")
+	}
+	return nil
+}
+
+type RestoreResponse struct {
+	BackupID  string `json:"backup_id"` // Snapshot taken just before restoring, to undo the restore
+	Restored  int    `json:"restored"`
+	Deleted   int    `json:"deleted"` // Files created after the snapshot
+	Unchanged int    `json:"unchanged"`
+}
+
+type DeleteRequest struct {
+	ID string `json:"id"`
+}
+
+func (r DeleteRequest) Validate() error {
+	if r.ID == "" {
+		return api.NewError(api.BadRequest, "ID is required")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package search
+
+import "agent-dev-environment/src/library/api"
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+const (
+	MatchExact  = "exact"
+	MatchPrefix = "prefix"
+	MatchFuzzy  = "fuzzy"
+)
+
+var Kinds = []string{"func", "method", "type", "interface", "class", "const", "var", "module"}
+
+type Request struct {
+	Path  string   `json:"path"` // Directory whose symbols are searched
+	Query string   `json:"query"`
+	Match string   `json:"match,omitempty"` // exact (default), prefix or fuzzy
+	Kinds []string `json:"kinds,omitempty"` // Restrict to these kinds, e.g. ["func", "method"]
+	Limit *int     `json:"limit,omitempty"` // Defaults to DefaultLimit
+}
+
+func (r Request) Validate() error {
+	if r.Path == "" {
+		return api.NewError(api.BadRequest, "Path is required")
+	}
+	if r.Query == "" {
+		return api.NewError(api.BadRequest, "Query is required")
+	}
+	switch r.Match {
+	case "", MatchExact, MatchPrefix, MatchFuzzy:
+	default:
+		return api.NewError(api.BadRequest, "Match must be one of 'exact', 'prefix' or 'fuzzy'")
+	}
+	for _, kind := range r.Kinds {
+		if !isKind(kind) {
+			return api.NewError(api.BadRequest, "Unknown symbol kind: "+kind)
+		}
+	}
+	if r.Limit != nil && (*r.Limit <= 0 || *r.Limit > MaxLimit) {
+		return api.NewError(api.BadRequest, "Limit must be between 1 and 500")
+	}
+	return nil
+}
+
+func (r *Request) Paths() []*string {
+	return []*string{&r.Path}
+}
+
+func isKind(kind string) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+type Symbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Container string `json:"container,omitempty"` // Receiver, class or trait
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+type Response struct {
+	Symbols []Symbol `json:"symbols"`
+	Total   int      `json:"total"`
+}
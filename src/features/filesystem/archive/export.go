@@ -0,0 +1,43 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	archive_models "agent-dev-environment/src/api/v1/filesystem/archive"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/archive"
+	"agent-dev-environment/src/library/trash"
+)
+
+var contentTypes = map[string]string{
+	archive.TarGz: "application/gzip",
+	archive.Zip:   "application/zip",
+}
+
+// ExportHandler streams req.Path as an archive. Paths hidden by deny rules
+// and the trash are left out.
+func ExportHandler(ctx context.Context, req archive_models.ExportRequest, w http.ResponseWriter, r *http.Request) error {
+	if _, err := os.Lstat(req.Path); err != nil {
+		if os.IsNotExist(err) {
+			return api.NewError(api.NotFound, "Path not found")
+		}
+		return err
+	}
+	format := req.Format
+	if format == "" {
+		format = archive.TarGz
+	}
+
+	name := filepath.Base(req.Path) + "." + format
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+
+	_, err := archive.Write(w, req.Path, format, req.ExcludeIgnored, func(path string, isDir bool) bool {
+		return api.CheckRead(path) == nil && !trash.Within(path)
+	})
+	return err
+}
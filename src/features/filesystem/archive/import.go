@@ -0,0 +1,79 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+
+	archive_models "agent-dev-environment/src/api/v1/filesystem/archive"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/archive"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/journal"
+	"agent-dev-environment/src/library/trash"
+)
+
+// ImportHandler unpacks the archive in the request body into req.Path.
+func ImportHandler(ctx context.Context, req archive_models.ImportRequest, w http.ResponseWriter, r *http.Request) error {
+	if trash.Within(req.Path) {
+		return api.NewError(api.BadRequest, "Cannot import into the trash directory")
+	}
+	format := req.Format
+	if format == "" {
+		format = archive.TarGz
+	}
+
+	// Whatever the import creates or overwrites is noted so it can be undone
+	var changes []journal.Change
+	body := http.MaxBytesReader(w, r.Body, archive.MaxBytes)
+	stats, written, err := archive.Extract(body, format, req.Path, archive.ExtractOptions{
+		Overwrite: req.Overwrite,
+		Allow:     func(path string) error { return api.CheckWrite(ctx, path) },
+		Reserve:   api.ReserveAll,
+		Change: func(path string) {
+			changes = append(changes, journal.Change{Path: path, Before: journal.SavedStat(path)})
+		},
+	})
+	for _, path := range written {
+		events.Publish(events.Create, path)
+	}
+	journal.Record(journal.Import, api.RequestID(ctx), journal.Completed(changes)...)
+	if err != nil {
+		return importError(err)
+	}
+
+	api.Respond(w, &archive_models.ImportResponse{
+		Path:     req.Path,
+		Files:    stats.Files,
+		Dirs:     stats.Dirs,
+		Symlinks: stats.Symlinks,
+		Bytes:    stats.Bytes,
+	})
+	return nil
+}
+
+func importError(err error) error {
+	var appErr *api.AppError
+	var unsafe *archive.UnsafeError
+	var conflict *archive.ConflictError
+	var tooLarge *http.MaxBytesError
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	switch {
+	case errors.As(err, &appErr):
+		return err
+	case errors.As(err, &unsafe):
+		return api.NewError(api.BadRequest, fmt.Sprintf("Archive entry %q leads outside the target directory", unsafe.Name))
+	case errors.As(err, &conflict):
+		return api.NewError(api.Conflict, fmt.Sprintf("Path %q already exists", conflict.Path))
+	case errors.As(err, &tooLarge), errors.Is(err, archive.ErrTooLarge):
+		return api.NewError(api.ContentTooLarge, fmt.Sprintf("Archive exceeds the limit of %d bytes", archive.MaxBytes))
+	case errors.As(err, &pathErr), errors.As(err, &linkErr):
+		// Failing to write is the server's problem, not the archive's
+		return err
+	}
+	return api.NewError(api.BadRequest, "Invalid archive: "+err.Error())
+}
@@ -0,0 +1,73 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	batch_models "agent-dev-environment/src/api/v1/filesystem/batch"
+	"agent-dev-environment/src/features/filesystem/copy"
+	"agent-dev-environment/src/features/filesystem/create_file"
+	"agent-dev-environment/src/features/filesystem/delete"
+	"agent-dev-environment/src/features/filesystem/link"
+	"agent-dev-environment/src/features/filesystem/mkdir"
+	"agent-dev-environment/src/features/filesystem/move"
+	"agent-dev-environment/src/features/filesystem/replace"
+	"agent-dev-environment/src/library/api"
+)
+
+// operations run each op through its endpoint's handler, with the same
+// validation and path checks as a request of its own.
+var operations = map[string]func(context.Context, json.RawMessage) (any, error){
+	"mkdir":       api.Invoke(mkdir.Handler),
+	"create_file": api.Invoke(create_file.Handler),
+	"replace":     api.Invoke(replace.Handler),
+	"move":        api.Invoke(move.Handler),
+	"copy":        api.Invoke(copy.Handler),
+	"delete":      api.Invoke(delete.Handler),
+	"link/create": api.Invoke(link.CreateHandler),
+}
+
+func init() {
+	// Requests are validated against the ops there is a handler for
+	batch_models.Ops = slices.Sorted(maps.Keys(operations))
+}
+
+// Handler runs the operations in order. Failures are reported per operation
+// rather than failing the batch, and operations already run stay applied.
+func Handler(ctx context.Context, req batch_models.Request) (*batch_models.Response, error) {
+	res := &batch_models.Response{Results: make([]batch_models.Result, 0, len(req.Operations))}
+	failed := false
+	for _, op := range req.Operations {
+		result := batch_models.Result{Op: op.Op}
+		if failed && !req.ContinueOnError {
+			result.Status = batch_models.StatusSkipped
+			res.Skipped++
+		} else if err := run(ctx, op, &result); err != nil {
+			result.Status = batch_models.StatusError
+			result.Code, result.Error = api.ErrorResponse(err)
+			res.Failed++
+			failed = true
+		} else {
+			result.Status = batch_models.StatusOK
+			res.Succeeded++
+		}
+		res.Results = append(res.Results, result)
+	}
+	return res, nil
+}
+
+func run(ctx context.Context, op batch_models.Operation, result *batch_models.Result) error {
+	invoke, ok := operations[op.Op]
+	if !ok {
+		return api.NewError(api.BadRequest, fmt.Sprintf("Unknown op %q", op.Op))
+	}
+	out, err := invoke(ctx, op.Request)
+	if err != nil {
+		return err
+	}
+	result.Response, err = json.Marshal(out)
+	return err
+}
@@ -0,0 +1,73 @@
+package changes
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	changes_models "agent-dev-environment/src/api/v1/filesystem/changes"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/changes"
+)
+
+// Handler compares the files below req.Path with the state recorded by
+// req.Cursor. Without a cursor, it only records the current state.
+func Handler(ctx context.Context, req changes_models.Request) (*changes_models.Response, error) {
+	if req.Cursor == "" {
+		info, err := os.Stat(req.Path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, api.NewError(api.NotFound, "Directory not found")
+			}
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, api.NewError(api.BadRequest, "Path is not a directory")
+		}
+
+		cursor, err := changes.Start(req.Path, req.IncludeIgnored)
+		if err != nil {
+			return nil, api.NewError(api.InternalServerError, "Failed to scan directory: "+err.Error())
+		}
+		return &changes_models.Response{Cursor: cursor.ID, Created: []string{}, Modified: []string{}, Deleted: []string{}}, nil
+	}
+
+	root, err := changes.Root(req.Cursor)
+	if err != nil {
+		return nil, cursorError(err)
+	}
+	if abs, err := filepath.Abs(req.Path); err != nil || abs != root {
+		return nil, api.NewError(api.BadRequest, "Cursor was issued for a different path")
+	}
+
+	cursor, diff, err := changes.Since(req.Cursor)
+	if err != nil {
+		return nil, cursorError(err)
+	}
+	return &changes_models.Response{
+		Cursor:   cursor.ID,
+		Created:  visible(diff.Created),
+		Modified: visible(diff.Modified),
+		Deleted:  visible(diff.Deleted),
+	}, nil
+}
+
+// visible leaves out paths hidden by deny rules, which are not revealed,
+// not even by name.
+func visible(paths []string) []string {
+	result := []string{}
+	for _, path := range paths {
+		if api.CheckRead(path) == nil {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+func cursorError(err error) error {
+	if errors.Is(err, changes.ErrNotFound) {
+		return api.NewError(api.Gone, "Cursor not found or expired; start again without a cursor")
+	}
+	return api.NewError(api.InternalServerError, "Failed to scan directory: "+err.Error())
+}
@@ -1,26 +1,35 @@
 package chdir
 
 import (
+	"context"
 	"os"
-	"path/filepath"
 
 	"agent-dev-environment/src/api/v1"
 	chdir_models "agent-dev-environment/src/api/v1/filesystem/chdir"
 	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/session"
+	"agent-dev-environment/src/library/workspace"
 )
 
-func Handler(req chdir_models.Request) (*v1.EmptyResponse, error) {
-	absPath, err := filepath.Abs(req.Path)
+// Handler changes the working directory of the request's session only, so
+// concurrent sessions keep resolving relative paths against their own.
+func Handler(ctx context.Context, req chdir_models.Request) (*v1.EmptyResponse, error) {
+	info, err := os.Stat(req.Path)
 	if err != nil {
-		return nil, api.NewError(api.BadRequest, "Invalid path: "+err.Error())
-	}
-
-	if err := os.Chdir(absPath); err != nil {
 		if os.IsNotExist(err) {
 			return nil, api.NewError(api.NotFound, "Directory not found")
 		}
 		return nil, api.NewError(api.InternalServerError, "Failed to change directory: "+err.Error())
 	}
+	if !info.IsDir() {
+		return nil, api.NewError(api.BadRequest, "Path is not a directory")
+	}
 
+	// Stored with symlinks resolved, as the kernel reports a working directory
+	dir, err := workspace.Resolve(req.Path)
+	if err != nil {
+		return nil, api.NewError(api.BadRequest, "Invalid path: "+err.Error())
+	}
+	session.Chdir(api.Session(ctx), dir)
 	return &v1.EmptyResponse{}, nil
 }
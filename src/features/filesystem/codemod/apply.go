@@ -0,0 +1,146 @@
+package codemod
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	codemod_models "agent-dev-environment/src/api/v1/filesystem/codemod"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/journal"
+	"agent-dev-environment/src/library/workspace"
+)
+
+func ApplyHandler(ctx context.Context, req codemod_models.ApplyRequest) (*codemod_models.ApplyResponse, error) {
+	p, ok := previews.get(req.PreviewID)
+	if !ok {
+		return nil, api.NewError(api.NotFound, "Preview not found or expired")
+	}
+
+	selected, err := selectChanges(p, req.ChangeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := map[string][]pendingChange{}
+	for _, change := range selected {
+		byPath[change.path] = append(byPath[change.path], change)
+	}
+
+	// Verify every file before touching any, so a conflict leaves the tree untouched
+	contents := map[string][]byte{}
+	for path := range byPath {
+		if err := api.CheckWrite(ctx, path); err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || sha256.Sum256(content) != p.files[path].hash {
+			return nil, api.NewError(api.Conflict, "File changed since preview: "+path)
+		}
+		contents[path] = content
+	}
+
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	updated := map[string][]byte{}
+	sizes := map[string]int64{}
+	for _, path := range paths {
+		changes := byPath[path]
+		// Apply back to front so earlier offsets stay valid
+		sort.Slice(changes, func(i, j int) bool { return changes[i].start > changes[j].start })
+
+		content := contents[path]
+		for _, change := range changes {
+			next := append([]byte{}, content[:change.start]...)
+			next = append(next, change.replacement...)
+			content = append(next, content[change.end:]...)
+		}
+		updated[path] = content
+		sizes[path] = int64(len(content))
+	}
+	if err := api.ReserveAll(sizes); err != nil {
+		return nil, err
+	}
+
+	var applied []journal.Change
+	// Files already written stay changed, and journaled, when a later one fails
+	defer func() { journal.Record(journal.Codemod, api.RequestID(ctx), applied...) }()
+	for i, path := range paths {
+		content := updated[path]
+		if err := writeFile(path, content, p.files[path].mode); err != nil {
+			msg := fmt.Sprintf("Failed to write %s: %v", path, err)
+			if i > 0 {
+				msg += ". Already changed: " + strings.Join(paths[:i], ", ")
+			}
+			return nil, api.NewError(api.InternalServerError, msg)
+		}
+		events.Publish(events.Write, path)
+		applied = append(applied, journal.Change{
+			Path:   path,
+			Before: journal.SavedContent(contents[path], p.files[path].mode),
+			After:  journal.Content(content),
+		})
+	}
+	previews.remove(req.PreviewID)
+
+	return &codemod_models.ApplyResponse{
+		Applied:      len(selected),
+		FilesChanged: paths,
+	}, nil
+}
+
+// writeFile replaces path with content through a temporary file renamed
+// into place, so a failed write leaves the original intact.
+func writeFile(path string, content []byte, mode fs.FileMode) error {
+	tmp, err := workspace.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".codemod-*")
+	if err != nil {
+		return err
+	}
+	defer workspace.Remove(tmp.Name())
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := workspace.Chmod(tmp.Name(), mode.Perm()); err != nil {
+		return err
+	}
+	return workspace.Rename(tmp.Name(), path)
+}
+
+func selectChanges(p *preview, ids []string) ([]pendingChange, error) {
+	if len(ids) == 0 {
+		return p.changes, nil
+	}
+
+	byID := map[string]pendingChange{}
+	for _, change := range p.changes {
+		byID[change.id] = change
+	}
+
+	var selected []pendingChange
+	seen := map[string]bool{}
+	for _, id := range ids {
+		change, ok := byID[id]
+		if !ok {
+			return nil, api.NewError(api.BadRequest, "Unknown change ID: "+id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			selected = append(selected, change)
+		}
+	}
+	return selected, nil
+}
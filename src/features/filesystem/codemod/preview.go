@@ -0,0 +1,192 @@
+package codemod
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	codemod_models "agent-dev-environment/src/api/v1/filesystem/codemod"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/glob"
+	"agent-dev-environment/src/library/walk"
+)
+
+type fileResult struct {
+	path    string
+	content []byte
+	file    previewFile
+	matches []pendingChange
+}
+
+func PreviewHandler(ctx context.Context, req codemod_models.PreviewRequest) (*codemod_models.PreviewResponse, error) {
+	info, err := os.Stat(req.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, api.NewError(api.NotFound, "Path not found")
+		}
+		return nil, err
+	}
+
+	re := compilePattern(req)
+	contextLines := codemod_models.DefaultContextLines
+	if req.ContextLines != nil {
+		contextLines = *req.ContextLines
+	}
+
+	var mu sync.Mutex
+	var results []fileResult
+	opts := walk.Options{RespectIgnore: true}
+	err = walk.Walk(req.Path, opts, func(path string, d fs.DirEntry) error {
+		rel := filepath.Base(path)
+		if info.IsDir() {
+			rel, _ = filepath.Rel(req.Path, path)
+		}
+		rel = filepath.ToSlash(rel)
+		if len(req.Include) > 0 && !glob.MatchAnyPath(req.Include, rel) {
+			return nil
+		}
+		if glob.MatchAnyPath(req.Exclude, rel) {
+			return nil
+		}
+
+		result, ok := matchFile(path, d, re, req)
+		if !ok {
+			return nil
+		}
+
+		mu.Lock()
+		results = append(results, result)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].path < results[j].path })
+
+	total := 0
+	for _, result := range results {
+		total += len(result.matches)
+	}
+	if total > codemod_models.MaxChanges {
+		return nil, api.NewError(api.BadRequest, fmt.Sprintf("Too many matches (%d), narrow the pattern or scope", total))
+	}
+
+	p := &preview{createdAt: time.Now(), files: map[string]previewFile{}}
+	changes := []codemod_models.Change{}
+	for _, result := range results {
+		p.files[result.path] = result.file
+		lines := strings.Split(string(result.content), "\n")
+
+		for _, match := range result.matches {
+			match.id = fmt.Sprintf("c%d", len(p.changes)+1)
+			p.changes = append(p.changes, match)
+			changes = append(changes, describe(match, result.content, lines, contextLines))
+		}
+	}
+
+	return &codemod_models.PreviewResponse{
+		PreviewID:     previews.put(p),
+		Changes:       changes,
+		TotalChanges:  len(changes),
+		FilesAffected: len(results),
+	}, nil
+}
+
+func compilePattern(req codemod_models.PreviewRequest) *regexp.Regexp {
+	pattern := req.Pattern
+	if !req.Regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if req.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	// The pattern was validated by the request
+	return regexp.MustCompile(pattern)
+}
+
+// matchFile collects the changes for one file. Binary and unreadable files,
+// as well as files where every match would be left unchanged, are skipped.
+func matchFile(path string, d fs.DirEntry, re *regexp.Regexp, req codemod_models.PreviewRequest) (fileResult, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil || fsutil.IsBinary(content) {
+		return fileResult{}, false
+	}
+
+	var matches []pendingChange
+	for _, loc := range re.FindAllSubmatchIndex(content, -1) {
+		var replacement []byte
+		if req.Regex {
+			replacement = re.Expand(nil, []byte(req.Replacement), content, loc)
+		} else {
+			replacement = []byte(req.Replacement)
+		}
+		if bytes.Equal(replacement, content[loc[0]:loc[1]]) {
+			continue
+		}
+
+		matches = append(matches, pendingChange{
+			path:        path,
+			start:       loc[0],
+			end:         loc[1],
+			replacement: replacement,
+		})
+	}
+	if len(matches) == 0 {
+		return fileResult{}, false
+	}
+
+	mode := fs.FileMode(0644)
+	if info, err := d.Info(); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	return fileResult{
+		path:    path,
+		content: content,
+		file:    previewFile{hash: sha256.Sum256(content), mode: mode},
+		matches: matches,
+	}, true
+}
+
+func describe(match pendingChange, content []byte, lines []string, contextLines int) codemod_models.Change {
+	lineStart := bytes.LastIndexByte(content[:match.start], '\n') + 1
+	lineEnd := len(content)
+	if i := bytes.IndexByte(content[match.end:], '\n'); i >= 0 {
+		lineEnd = match.end + i
+	}
+	if match.end > match.start && content[match.end-1] == '\n' {
+		// A match ending in a newline does not extend into the next line
+		lineEnd = match.end - 1
+	}
+
+	firstLine := bytes.Count(content[:lineStart], []byte("\n"))
+	lastLine := firstLine + bytes.Count(content[lineStart:lineEnd], []byte("\n"))
+
+	after := string(content[lineStart:match.start]) + string(match.replacement)
+	if match.end <= lineEnd {
+		after += string(content[match.end:lineEnd])
+	}
+
+	return codemod_models.Change{
+		ID:            match.id,
+		Path:          match.path,
+		Line:          firstLine + 1,
+		Column:        match.start - lineStart + 1,
+		Before:        string(content[lineStart:lineEnd]),
+		After:         after,
+		ContextBefore: lines[max(0, firstLine-contextLines):firstLine],
+		ContextAfter:  lines[min(len(lines), lastLine+1):min(len(lines), lastLine+1+contextLines)],
+	}
+}
@@ -0,0 +1,92 @@
+package codemod
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+const (
+	previewTTL  = 30 * time.Minute
+	maxPreviews = 100
+)
+
+// pendingChange is a single replacement recorded by a preview, addressed by
+// byte offsets into the file content that was hashed at preview time.
+type pendingChange struct {
+	id          string
+	path        string
+	start       int
+	end         int
+	replacement []byte
+}
+
+type previewFile struct {
+	hash [sha256.Size]byte
+	mode fs.FileMode
+}
+
+type preview struct {
+	createdAt time.Time
+	files     map[string]previewFile
+	changes   []pendingChange
+}
+
+type store struct {
+	mu       sync.Mutex
+	previews map[string]*preview
+}
+
+var previews = &store{previews: map[string]*preview{}}
+
+func (s *store) put(p *preview) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictLocked()
+	id := newPreviewID()
+	s.previews[id] = p
+	return id
+}
+
+func (s *store) get(id string) (*preview, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictLocked()
+	p, ok := s.previews[id]
+	return p, ok
+}
+
+// remove drops an applied preview: its offsets no longer match the files.
+func (s *store) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.previews, id)
+}
+
+func (s *store) evictLocked() {
+	var oldestID string
+	var oldest time.Time
+	for id, p := range s.previews {
+		if time.Since(p.createdAt) > previewTTL {
+			delete(s.previews, id)
+			continue
+		}
+		if oldestID == "" || p.createdAt.Before(oldest) {
+			oldestID, oldest = id, p.createdAt
+		}
+	}
+	if len(s.previews) >= maxPreviews {
+		delete(s.previews, oldestID)
+	}
+}
+
+func newPreviewID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,82 @@
+package copy
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	models "agent-dev-environment/src/api/v1/filesystem/copy"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/glob"
+	"agent-dev-environment/src/library/journal"
+	"agent-dev-environment/src/library/workspace"
+)
+
+func Handler(ctx context.Context, req models.Request) (*models.Response, error) {
+	if _, err := os.Lstat(req.Source); os.IsNotExist(err) {
+		return nil, api.NewError(api.NotFound, "Source path does not exist")
+	}
+	if _, err := os.Lstat(req.Destination); err == nil && !req.Overwrite {
+		return nil, api.NewError(api.Conflict, "Destination path already exists")
+	}
+
+	if err := api.CheckMirror(ctx, req.Source, req.Destination); err != nil {
+		return nil, err
+	}
+
+	opts := fsutil.CopyOptions{
+		Overwrite:      req.Overwrite,
+		PreserveMode:   req.PreserveMode,
+		PreserveTimes:  req.PreserveTimes,
+		FollowSymlinks: req.FollowSymlinks,
+		Reserve:        api.Reserve,
+	}
+	// Whatever the copy creates or overwrites is noted so it can be undone
+	var changes []journal.Change
+	opts.Change = func(dst string) {
+		changes = append(changes, journal.Change{Path: dst, Before: journal.SavedStat(dst)})
+	}
+	if len(req.Exclude) > 0 || req.FollowSymlinks {
+		opts.Exclude = func(rel string, isDir bool) bool {
+			if len(req.Exclude) > 0 && glob.MatchAnyPath(req.Exclude, rel) {
+				return true
+			}
+			// Following a symlink out of the workspace would copy what it may not read
+			return req.FollowSymlinks && workspace.Check(filepath.Join(req.Source, filepath.FromSlash(rel))) != nil
+		}
+	}
+
+	stats, err := fsutil.Copy(req.Source, req.Destination, opts)
+	// A partial copy still leaves new files behind
+	if stats.Files > 0 || stats.Symlinks > 0 {
+		events.Publish(events.Create, req.Destination)
+	}
+	journal.Record(journal.Copy, api.RequestID(ctx), journal.Completed(changes)...)
+	if err != nil {
+		var appErr *api.AppError
+		switch {
+		case errors.As(err, &appErr):
+			return nil, err
+		case errors.Is(err, fsutil.ErrCopyIntoSelf):
+			return nil, api.NewError(api.BadRequest, "Cannot copy a directory into itself")
+		case errors.Is(err, fsutil.ErrCopyOntoSelf):
+			return nil, api.NewError(api.BadRequest, "Cannot copy a file onto itself")
+		case errors.Is(err, fs.ErrExist):
+			return nil, api.NewError(api.Conflict, "Destination conflicts with an existing path: "+err.Error())
+		case errors.Is(err, fs.ErrNotExist):
+			return nil, api.NewError(api.NotFound, "Copy failed: "+err.Error())
+		}
+		return nil, api.NewError(api.InternalServerError, "Failed to copy: "+err.Error())
+	}
+
+	return &models.Response{
+		FilesCopied:    stats.Files,
+		BytesCopied:    stats.Bytes,
+		SymlinksCopied: stats.Symlinks,
+		Excluded:       stats.Excluded,
+	}, nil
+}
@@ -1,6 +1,7 @@
 package create_file
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -8,15 +9,31 @@ import (
 	"agent-dev-environment/src/api/v1"
 	create_models "agent-dev-environment/src/api/v1/filesystem/create_file"
 	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/journal"
+	"agent-dev-environment/src/library/workspace"
 )
 
-func Handler(req create_models.Request) (*v1.EmptyResponse, error) {
+func Handler(ctx context.Context, req create_models.Request) (*v1.EmptyResponse, error) {
+	if err := api.CheckWrite(ctx, req.Path); err != nil {
+		return nil, err
+	}
+	// Checked before reserving, so a conflict is not counted against the quota
+	if _, err := os.Lstat(req.Path); err == nil {
+		return nil, api.NewError(api.Conflict, "File already exists")
+	}
+	if err := api.Reserve(req.Path, int64(len(req.Content))); err != nil {
+		return nil, err
+	}
+
 	dir := filepath.Dir(req.Path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	created := fsutil.MissingDirs(dir)
+	if err := workspace.MkdirAll(dir, 0755); err != nil {
 		return nil, err
 	}
 
-	file, err := os.OpenFile(req.Path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	file, err := workspace.OpenFile(req.Path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
 	if err != nil {
 		if errors.Is(err, os.ErrExist) {
 			return nil, api.NewError(api.Conflict, "File already exists")
@@ -28,6 +45,9 @@ func Handler(req create_models.Request) (*v1.EmptyResponse, error) {
 	if _, err := file.WriteString(req.Content); err != nil {
 		return nil, err
 	}
+	events.Publish(events.Create, req.Path)
+	changes := append(journal.Dirs(created), journal.Change{Path: req.Path, After: journal.Content([]byte(req.Content))})
+	journal.Record(journal.CreateFile, api.RequestID(ctx), changes...)
 
 	return &v1.EmptyResponse{}, nil
 }
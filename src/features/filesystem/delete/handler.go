@@ -1,39 +1,112 @@
 package delete
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 
-	"agent-dev-environment/src/api/v1"
 	delete_models "agent-dev-environment/src/api/v1/filesystem/delete"
 	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/journal"
+	"agent-dev-environment/src/library/trash"
+	"agent-dev-environment/src/library/workspace"
 )
 
-func Handler(req delete_models.Request) (*v1.EmptyResponse, error) {
-	stat, err := os.Stat(req.Path)
+func Handler(ctx context.Context, req delete_models.Request) (*delete_models.Response, error) {
+	// Symlinks are deleted themselves, never what they point to
+	stat, err := os.Lstat(req.Path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, api.NewError(api.NotFound, "File or directory not found")
 		}
 		return nil, err
 	}
-
-	if stat.IsDir() && !req.Recursive {
-		return nil, api.NewError(api.BadRequest, "Cannot delete directory without recursive flag")
+	if trash.Within(req.Path) || (len(req.Patterns) == 0 && trash.Contains(req.Path)) {
+		return nil, api.NewError(api.BadRequest, "Cannot delete the trash directory")
 	}
 
-	if req.Recursive {
-		err = os.RemoveAll(req.Path)
+	var targets []target
+	if len(req.Patterns) == 0 {
+		if stat.IsDir() && !req.Recursive {
+			return nil, api.NewError(api.BadRequest, "Cannot delete directory without recursive flag")
+		}
+		targets = []target{{path: req.Path, isDir: stat.IsDir(), isSymlink: stat.Mode()&fs.ModeSymlink != 0}}
 	} else {
-		err = os.Remove(req.Path)
+		if info, err := os.Stat(req.Path); err != nil || !info.IsDir() {
+			return nil, api.NewError(api.BadRequest, "Path must be a directory when patterns are given")
+		}
+		targets, err = findMatches(req.Path, req.Patterns, req.Recursive)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil, api.NewError(api.NotFound, "File or directory not found")
+	maxItems := delete_models.DefaultMaxItems
+	if req.MaxItems != nil {
+		maxItems = *req.MaxItems
+	}
+	if len(targets) > maxItems && !req.DryRun {
+		return nil, api.NewError(api.BadRequest, fmt.Sprintf("Patterns match %d entries, more than max_items (%d)", len(targets), maxItems))
+	}
+
+	for _, t := range targets {
+		if err := api.CheckTree(ctx, t.path); err != nil {
+			return nil, err
 		}
-		return nil, err
 	}
 
-	return &v1.EmptyResponse{}, nil
+	res := &delete_models.Response{Items: make([]delete_models.Item, 0, len(targets)), DryRun: req.DryRun}
+	var changes []journal.Change
+	// Deletes that already happened are journaled even when a later one fails
+	defer func() { journal.Record(journal.Delete, api.RequestID(ctx), changes...) }()
+
+	for _, t := range targets {
+		item := delete_models.Item{Path: t.path, IsDir: t.isDir, IsSymlink: t.isSymlink}
+		if req.DryRun || req.Permanent {
+			item.Size = fsutil.DiskSize(t.path)
+		}
+		if !req.DryRun {
+			entry, err := remove(ctx, t.path, req.Recursive, req.Permanent)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) && len(req.Patterns) == 0 {
+					return nil, api.NewError(api.NotFound, "File or directory not found")
+				}
+				return nil, api.NewError(api.InternalServerError, fmt.Sprintf("Failed to delete %s: %v", t.path, err))
+			}
+			events.Publish(events.Remove, t.path)
+			if !req.Permanent {
+				// The trash measured the entry already
+				item.TrashID, item.Size = entry.ID, entry.Size
+			}
+
+			before := journal.Trashed(item.IsDir, item.Size, item.TrashID)
+			if req.Permanent {
+				before = journal.Discarded(item.IsDir, item.Size)
+			}
+			changes = append(changes, journal.Change{Path: t.path, Before: before})
+		}
+		res.Items = append(res.Items, item)
+		res.TotalBytes += item.Size
+	}
+	if len(req.Patterns) == 0 && len(res.Items) == 1 {
+		res.TrashID = res.Items[0].TrashID
+	}
+
+	return res, nil
+}
+
+// remove deletes path, moving it to the trash unless permanent is set, and
+// returns the trash entry.
+func remove(ctx context.Context, path string, recursive, permanent bool) (trash.Entry, error) {
+	if !permanent {
+		return trash.Put(path, api.RequestID(ctx))
+	}
+	if recursive {
+		return trash.Entry{}, workspace.RemoveAll(path)
+	}
+	return trash.Entry{}, workspace.Remove(path)
 }
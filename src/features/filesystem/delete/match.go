@@ -0,0 +1,76 @@
+package delete
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"agent-dev-environment/src/library/glob"
+	"agent-dev-environment/src/library/trash"
+)
+
+type target struct {
+	path      string
+	isDir     bool
+	isSymlink bool
+}
+
+// findMatches returns the entries below base matching any of the patterns.
+// A matching directory is returned whole rather than descended into, and
+// directories only match when recursive is set. The .git directory and the
+// trash are never matched.
+func findMatches(base string, patterns []string, recursive bool) ([]target, error) {
+	var filePatterns, dirPatterns []string
+	for _, pattern := range patterns {
+		if trimmed, ok := strings.CutSuffix(pattern, "/"); ok {
+			dirPatterns = append(dirPatterns, trimmed)
+		} else {
+			filePatterns = append(filePatterns, pattern)
+		}
+	}
+
+	var targets []target
+	err := filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == base {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if trash.Within(path) {
+			return skip(d)
+		}
+
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		matched := glob.MatchAnyPath(filePatterns, rel) || (d.IsDir() && glob.MatchAnyPath(dirPatterns, rel))
+		if !matched || (d.IsDir() && !recursive) {
+			return nil
+		}
+		if d.IsDir() && trash.Contains(path) {
+			// Deleting this directory would take the trash with it
+			return nil
+		}
+
+		targets = append(targets, target{path: path, isDir: d.IsDir(), isSymlink: d.Type()&fs.ModeSymlink != 0})
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return targets, err
+}
+
+func skip(d fs.DirEntry) error {
+	if d.IsDir() {
+		return filepath.SkipDir
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package link
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	link_models "agent-dev-environment/src/api/v1/filesystem/link"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/journal"
+	"agent-dev-environment/src/library/workspace"
+)
+
+func CreateHandler(ctx context.Context, req link_models.CreateRequest) (*link_models.Link, error) {
+	if err := api.CheckWrite(ctx, req.Path); err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(req.Path); err == nil {
+		return nil, api.NewError(api.Conflict, "Path already exists")
+	}
+
+	op := journal.Symlink
+	if req.Type == link_models.TypeHardlink {
+		op = journal.Hardlink
+		if err := checkHardlinkTarget(ctx, req.Target); err != nil {
+			return nil, err
+		}
+	} else if err := checkSymlinkTarget(req.Path, req.Target); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(req.Path), 0755); err != nil {
+		return nil, err
+	}
+	var err error
+	if op == journal.Hardlink {
+		err = os.Link(req.Target, req.Path)
+	} else {
+		err = os.Symlink(req.Target, req.Path)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, os.ErrExist):
+			return nil, api.NewError(api.Conflict, "Path already exists")
+		case errors.Is(err, syscall.EXDEV):
+			return nil, api.NewError(api.BadRequest, "Hard links cannot cross filesystems")
+		}
+		return nil, api.NewError(api.InternalServerError, "Failed to create link: "+err.Error())
+	}
+	events.Publish(events.Create, req.Path)
+	journal.Record(op, api.RequestID(ctx), journal.Change{Path: req.Path, After: journal.Stat(req.Path)})
+
+	return describe(req.Path)
+}
+
+// checkSymlinkTarget confines where a symlink leads the same way path
+// arguments are confined, so the link cannot be used to reach outside.
+// Targets that do not exist yet are allowed.
+func checkSymlinkTarget(path, target string) error {
+	resolved := target
+	if !filepath.IsAbs(target) {
+		// Not cleaned, so ".." applies after any symlink as the kernel would
+		resolved = filepath.Dir(path) + string(filepath.Separator) + target
+	}
+	if err := workspace.Check(resolved); err != nil {
+		return api.NewError(api.Forbidden, fmt.Sprintf("Target %q is outside the workspace", target))
+	}
+	return api.CheckRead(resolved)
+}
+
+// checkHardlinkTarget also requires write access to the target, since a hard
+// link is another name for the same content.
+func checkHardlinkTarget(ctx context.Context, target string) error {
+	info, err := os.Lstat(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return api.NewError(api.NotFound, "Target not found")
+		}
+		return err
+	}
+	if info.IsDir() {
+		return api.NewError(api.BadRequest, "Hard links to directories are not supported")
+	}
+	return api.CheckWrite(ctx, target)
+}
@@ -0,0 +1,63 @@
+package link
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"syscall"
+
+	link_models "agent-dev-environment/src/api/v1/filesystem/link"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/workspace"
+)
+
+func ReadHandler(ctx context.Context, req link_models.ReadRequest) (*link_models.Link, error) {
+	if _, err := os.Lstat(req.Path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, api.NewError(api.NotFound, "Path not found")
+		}
+		return nil, err
+	}
+	return describe(req.Path)
+}
+
+// describe reports what path is without following it, and where it leads.
+func describe(path string) (*link_models.Link, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := workspace.Resolve(path)
+	if err != nil {
+		return nil, api.NewError(api.BadRequest, "Cannot resolve path: "+err.Error())
+	}
+
+	res := &link_models.Link{Path: path, Type: linkType(info.Mode()), Resolved: resolved, Inside: workspace.Check(resolved) == nil}
+	if res.Type == link_models.TypeSymlink {
+		if res.Target, err = os.Readlink(path); err != nil {
+			return nil, err
+		}
+	}
+	if target, err := os.Stat(path); err == nil {
+		res.TargetExists = true
+		// Files outside the workspace are only reported to exist
+		if st, ok := target.Sys().(*syscall.Stat_t); ok && !target.IsDir() && res.Inside {
+			res.Links = uint64(st.Nlink)
+			res.Inode = uint64(st.Ino)
+		}
+	}
+	return res, nil
+}
+
+func linkType(mode fs.FileMode) string {
+	switch {
+	case mode&fs.ModeSymlink != 0:
+		return link_models.TypeSymlink
+	case mode.IsDir():
+		return link_models.TypeDir
+	case mode.IsRegular():
+		return link_models.TypeFile
+	default:
+		return link_models.TypeOther
+	}
+}
@@ -1,17 +1,23 @@
 package ls
 
 import (
-	"agent-dev-environment/src/api/v1"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
 	"agent-dev-environment/src/api/v1/filesystem/ls"
 	"agent-dev-environment/src/library/api"
-	"bytes"
-	"os"
-	"os/exec"
+	"agent-dev-environment/src/library/trash"
 )
 
-func Handler(req ls.Request) (*v1.CommandResponse, error) {
+func Handler(ctx context.Context, req ls.Request) (*ls.Response, error) {
 	// First verify the path exists
-	_, err := os.Stat(req.Path)
+	info, err := os.Stat(req.Path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, api.NewError(api.NotFound, "Path not found")
@@ -19,37 +25,194 @@ func Handler(req ls.Request) (*v1.CommandResponse, error) {
 		return nil, err
 	}
 
-	// Execute Linux ls command
-	output, err := executeLinuxLS(req.Path, req.Recursive, req.Long)
+	var entries []ls.Entry
+	if info.IsDir() {
+		maxDepth := 1
+		if req.Recursive {
+			maxDepth = ls.DefaultMaxDepth
+			if req.MaxDepth != nil {
+				maxDepth = *req.MaxDepth
+			}
+		}
+
+		l := lister{showHidden: req.ShowHidden, maxDepth: maxDepth}
+		// Listed in name order already, so a recursive walk can stop one
+		// entry past the page to tell whether there are more
+		if req.Recursive && req.Limit != nil && (req.SortBy == "" || req.SortBy == ls.SortByName) && !req.Reverse {
+			l.stopAfter = *req.Limit + 1
+			if req.Offset != nil {
+				l.stopAfter += *req.Offset
+			}
+		}
+		if err := l.list(req.Path, "", 1); err != nil {
+			return nil, err
+		}
+		entries = l.entries
+	} else {
+		linfo, err := os.Lstat(req.Path)
+		if err != nil {
+			return nil, err
+		}
+		entries = []ls.Entry{newEntry(req.Path, filepath.Base(req.Path), linfo)}
+	}
+
+	sortEntries(entries, req.SortBy, req.Reverse)
+
+	total := len(entries)
+	offset := 0
+	if req.Offset != nil {
+		offset = min(*req.Offset, total)
+	}
+	end := total
+	if req.Limit != nil {
+		end = min(offset+*req.Limit, total)
+	}
+
+	page := entries[offset:end]
+	if page == nil {
+		page = []ls.Entry{}
+	}
+
+	return &ls.Response{
+		Entries: page,
+		Total:   total,
+		HasMore: end < total,
+	}, nil
+}
+
+type lister struct {
+	showHidden bool
+	maxDepth   int
+	stopAfter  int // Entries to list before stopping, or 0 for all
+	entries    []ls.Entry
+}
+
+func (l *lister) full() bool {
+	return l.stopAfter > 0 && len(l.entries) >= l.stopAfter
+}
+
+func (l *lister) list(dir, rel string, depth int) error {
+	children, err := l.readDir(dir)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return &v1.CommandResponse{CommandOutput: output}, nil
+	for _, child := range children {
+		if l.full() {
+			return nil
+		}
+		path := filepath.Join(dir, child.Name())
+		name := filepath.Join(rel, child.Name())
+
+		info, err := child.Info()
+		if err != nil {
+			// The entry vanished between ReadDir and Lstat
+			continue
+		}
+		entry := newEntry(path, name, info)
+
+		if child.IsDir() {
+			grandchildren, err := l.readDir(path)
+			if err == nil {
+				count := len(grandchildren)
+				entry.ChildCount = &count
+			}
+		}
+		l.entries = append(l.entries, entry)
+
+		// Symlinked directories are reported but never followed
+		if child.IsDir() && depth < l.maxDepth {
+			// Unreadable or vanished directories are listed without their contents
+			err := l.list(path, name, depth+1)
+			if err != nil && !os.IsPermission(err) && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
-func executeLinuxLS(path string, recursive bool, long bool) (string, error) {
-	var args []string
+func (l *lister) readDir(dir string) ([]fs.DirEntry, error) {
+	children, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Trash bins are only reachable through the trash endpoints
+	children = slices.DeleteFunc(children, func(child fs.DirEntry) bool {
+		return trash.Within(filepath.Join(dir, child.Name()))
+	})
+	if l.showHidden {
+		return children, nil
+	}
 
-	// Build ls command arguments
-	if long {
-		args = append(args, "-l")
+	visible := children[:0]
+	for _, child := range children {
+		if !strings.HasPrefix(child.Name(), ".") {
+			visible = append(visible, child)
+		}
 	}
-	if recursive {
-		args = append(args, "-R")
+	return visible, nil
+}
+
+func newEntry(path, name string, info fs.FileInfo) ls.Entry {
+	entry := ls.Entry{
+		Name:       name,
+		Path:       path,
+		Type:       entryType(info.Mode()),
+		Size:       info.Size(),
+		Mode:       info.Mode().String(),
+		ModifiedAt: info.ModTime().UTC(),
+	}
+	if entry.Type == ls.TypeSymlink {
+		if target, err := os.Readlink(path); err == nil {
+			entry.SymlinkTarget = target
+		}
 	}
-	args = append(args, path)
+	return entry
+}
 
-	cmd := exec.Command("ls", args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+func entryType(mode fs.FileMode) string {
+	switch {
+	case mode&fs.ModeSymlink != 0:
+		return ls.TypeSymlink
+	case mode.IsDir():
+		return ls.TypeDir
+	case mode.IsRegular():
+		return ls.TypeFile
+	default:
+		return ls.TypeOther
+	}
+}
 
-	err := cmd.Run()
-	if err != nil {
-		return "", api.NewError(api.InternalServerError, "ls command failed: "+stderr.String())
+func sortEntries(entries []ls.Entry, sortBy string, reverse bool) {
+	less := func(a, b ls.Entry) bool {
+		switch sortBy {
+		case ls.SortBySize:
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+		case ls.SortByMtime:
+			if !a.ModifiedAt.Equal(b.ModifiedAt) {
+				return a.ModifiedAt.After(b.ModifiedAt)
+			}
+		case ls.SortByType:
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+		}
+		return nameLess(a.Name, b.Name)
 	}
 
-	return stdout.String(), nil
+	sort.SliceStable(entries, func(i, j int) bool {
+		if reverse {
+			return less(entries[j], entries[i])
+		}
+		return less(entries[i], entries[j])
+	})
 }
 
+// nameLess orders names a component at a time, so that "a/b" comes before
+// "a-c" and a directory's contents follow it directly, as they are listed.
+func nameLess(a, b string) bool {
+	return strings.ReplaceAll(a, string(filepath.Separator), "\x00") < strings.ReplaceAll(b, string(filepath.Separator), "\x00")
+}
@@ -1,14 +1,19 @@
 package mkdir
 
 import (
+	"context"
 	"os"
 
 	"agent-dev-environment/src/api/v1"
 	mkdir_models "agent-dev-environment/src/api/v1/filesystem/mkdir"
 	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/journal"
+	"agent-dev-environment/src/library/workspace"
 )
 
-func Handler(req mkdir_models.Request) (*v1.EmptyResponse, error) {
+func Handler(ctx context.Context, req mkdir_models.Request) (*v1.EmptyResponse, error) {
 	stat, err := os.Stat(req.Path)
 	if err == nil {
 		if !stat.IsDir() {
@@ -17,8 +22,14 @@ func Handler(req mkdir_models.Request) (*v1.EmptyResponse, error) {
 		return &v1.EmptyResponse{}, nil
 	}
 
-	if err := os.MkdirAll(req.Path, 0755); err != nil {
+	if err := api.CheckWrite(ctx, req.Path); err != nil {
 		return nil, err
 	}
+	created := fsutil.MissingDirs(req.Path)
+	if err := workspace.MkdirAll(req.Path, 0755); err != nil {
+		return nil, err
+	}
+	events.Publish(events.Create, req.Path)
+	journal.Record(journal.Mkdir, api.RequestID(ctx), journal.Dirs(created)...)
 	return &v1.EmptyResponse{}, nil
 }
@@ -1,27 +1,95 @@
 package move
 
 import (
-	"agent-dev-environment/src/library/api"
-	v1 "agent-dev-environment/src/api/v1"
-	models "agent-dev-environment/src/api/v1/filesystem/move"
+	"context"
+	"errors"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	models "agent-dev-environment/src/api/v1/filesystem/move"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/journal"
 )
 
-func Handler(req models.Request) (*v1.EmptyResponse, error) {
+func Handler(ctx context.Context, req models.Request) (*models.Response, error) {
 	// Check if source exists
-	if _, err := os.Stat(req.Source); os.IsNotExist(err) {
+	sourceInfo, err := os.Lstat(req.Source)
+	if os.IsNotExist(err) {
 		return nil, api.NewError(api.NotFound, "Source path does not exist")
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Like mv, an existing directory destination receives the source
+	destination := req.Destination
+	if info, err := os.Stat(destination); err == nil && info.IsDir() && !sameFile(req.Source, destination) {
+		destination = filepath.Join(destination, filepath.Base(req.Source))
+	}
 
-	// Check if destination already exists
-	if _, err := os.Stat(req.Destination); err == nil {
-		return nil, api.NewError(api.Conflict, "Destination path already exists")
+	if sourceInfo.IsDir() && isBelow(destination, req.Source) {
+		return nil, api.NewError(api.BadRequest, "Cannot move a directory into itself")
 	}
 
-	// Perform the move operation using os.Rename
-	if err := os.Rename(req.Source, req.Destination); err != nil {
+	if err := api.CheckTree(ctx, req.Source); err != nil {
+		return nil, err
+	}
+	if err := api.CheckTree(ctx, destination); err != nil {
+		return nil, err
+	}
+	if err := api.CheckMirror(ctx, req.Source, destination); err != nil {
+		return nil, err
+	}
+
+	// Whatever an overwrite replaces is kept so the move can be undone
+	replaced := journal.Absent
+	if req.Overwrite {
+		replaced = journal.SavedStat(destination)
+	}
+
+	crossDevice, err := fsutil.Move(req.Source, destination, req.Overwrite)
+	if err != nil {
+		journal.Release(replaced)
+		switch {
+		// ENOTEMPTY also satisfies fs.ErrExist, so it has to be told apart
+		// first. Some filesystems report EEXIST instead, which an overwrite
+		// only gets for a non-empty directory.
+		case errors.Is(err, syscall.ENOTEMPTY), req.Overwrite && errors.Is(err, fs.ErrExist):
+			return nil, api.NewError(api.Conflict, "Destination is a non-empty directory")
+		case errors.Is(err, fs.ErrExist):
+			return nil, api.NewError(api.Conflict, "Destination path already exists")
+		case errors.Is(err, syscall.EISDIR), errors.Is(err, syscall.ENOTDIR):
+			return nil, api.NewError(api.Conflict, "Cannot replace a directory with a file or a file with a directory")
+		}
 		return nil, api.NewError(api.InternalServerError, "Failed to move file: "+err.Error())
 	}
+	events.PublishRename(req.Source, destination)
+	journal.Record(journal.Move, api.RequestID(ctx), journal.Change{
+		Path:   destination,
+		From:   req.Source,
+		Before: replaced,
+		After:  journal.Stat(destination),
+	})
 
-	return &v1.EmptyResponse{}, nil
+	return &models.Response{Destination: destination, CrossDevice: crossDevice}, nil
+}
+
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+func isBelow(path, dir string) bool {
+	absPath, errPath := filepath.Abs(path)
+	absDir, errDir := filepath.Abs(dir)
+	if errPath != nil || errDir != nil {
+		return false
+	}
+	return strings.HasPrefix(absPath, absDir+string(filepath.Separator))
 }
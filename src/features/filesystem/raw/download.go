@@ -0,0 +1,55 @@
+package raw
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	raw_models "agent-dev-environment/src/api/v1/filesystem/raw"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/workspace"
+)
+
+// DownloadHandler streams a file's content as is. Range and conditional
+// requests are handled by http.ServeContent.
+func DownloadHandler(ctx context.Context, req raw_models.DownloadRequest, w http.ResponseWriter, r *http.Request) error {
+	file, err := workspace.OpenFile(req.Path, os.O_RDONLY, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return api.NewError(api.NotFound, "File not found")
+		}
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return api.NewError(api.BadRequest, "Path is not a regular file")
+	}
+
+	if req.Checksum {
+		h := sha256.New()
+		if _, err := io.Copy(h, file); err != nil {
+			return err
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		w.Header().Set(raw_models.ChecksumHeader, hex.EncodeToString(h.Sum(nil)))
+	}
+
+	// Lets clients resume with If-Range as long as the file is unchanged
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(req.Path)))
+	http.ServeContent(w, r, filepath.Base(req.Path), info.ModTime(), file)
+	return nil
+}
@@ -0,0 +1,112 @@
+package raw
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	raw_models "agent-dev-environment/src/api/v1/filesystem/raw"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/journal"
+	"agent-dev-environment/src/library/quota"
+	"agent-dev-environment/src/library/workspace"
+)
+
+// UploadHandler writes the request body to a temporary file next to
+// req.Path and renames it into place, so readers never see a partial file.
+func UploadHandler(ctx context.Context, req raw_models.UploadRequest, w http.ResponseWriter, r *http.Request) error {
+	if err := api.CheckWrite(ctx, req.Path); err != nil {
+		return err
+	}
+
+	mode := fs.FileMode(0o644)
+	existing, err := os.Lstat(req.Path)
+	switch {
+	case err == nil && existing.IsDir():
+		return api.NewError(api.BadRequest, "Path is a directory")
+	case err == nil && !req.Overwrite:
+		return api.NewError(api.Conflict, "File already exists")
+	case err == nil:
+		mode = existing.Mode().Perm()
+	}
+
+	dirs := fsutil.MissingDirs(filepath.Dir(req.Path))
+	if err := workspace.MkdirAll(filepath.Dir(req.Path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := workspace.CreateTemp(filepath.Dir(req.Path), "."+filepath.Base(req.Path)+".upload-*")
+	if err != nil {
+		return err
+	}
+	defer workspace.Remove(tmp.Name())
+
+	var body io.Reader = r.Body
+	available := quota.Available(req.Path)
+	if available < math.MaxInt64 {
+		// One byte past what the quota allows tells a file that fits from one that does not
+		body = io.LimitReader(r.Body, available+1)
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), body)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return api.NewError(api.BadRequest, "Failed to read upload: "+err.Error())
+	}
+	if size > available {
+		return api.QuotaError(quota.Exceeded(req.Path))
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	if req.SHA256 != "" && !strings.EqualFold(req.SHA256, sum) {
+		return api.NewError(api.BadRequest, fmt.Sprintf("Checksum mismatch: body has SHA256 %s", sum))
+	}
+	if err := api.Reserve(req.Path, size); err != nil {
+		return err
+	}
+	if err := workspace.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	created := existing == nil
+	var before journal.State
+	if created {
+		err = workspace.RenameNoReplace(tmp.Name(), req.Path)
+	} else {
+		before = journal.SavedStat(req.Path)
+		err = workspace.Rename(tmp.Name(), req.Path)
+	}
+	if err != nil {
+		journal.Release(before)
+		if errors.Is(err, fs.ErrExist) {
+			return api.NewError(api.Conflict, "File already exists")
+		}
+		return err
+	}
+
+	if created {
+		events.Publish(events.Create, req.Path)
+	} else {
+		events.Publish(events.Write, req.Path)
+	}
+	changes := append(journal.Dirs(dirs), journal.Change{Path: req.Path, Before: before, After: journal.Stat(req.Path)})
+	journal.Record(journal.Upload, api.RequestID(ctx), changes...)
+
+	api.Respond(w, &raw_models.UploadResponse{Path: req.Path, Size: size, SHA256: sum, Created: created})
+	return nil
+}
@@ -2,15 +2,17 @@ package read
 
 import (
 	"bufio"
+	"context"
 	"os"
 	"strings"
 
 	read_models "agent-dev-environment/src/api/v1/filesystem/read"
 	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/workspace"
 )
 
-func Handler(req read_models.Request) (*read_models.Response, error) {
-	file, err := os.Open(req.Path)
+func Handler(ctx context.Context, req read_models.Request) (*read_models.Response, error) {
+	file, err := workspace.OpenFile(req.Path, os.O_RDONLY, 0)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, api.NewError(api.NotFound, "File not found")
@@ -56,9 +58,19 @@ func Handler(req read_models.Request) (*read_models.Response, error) {
 	}
 
 	return &read_models.Response{
-		Content:    strings.Join(lines, "\n"),
-		TotalLines: totalLines,
-		HasMore:    hasMore,
-		LinesRead:  len(lines),
+		Content:       strings.Join(lines, "\n"),
+		TotalLines:    totalLines,
+		HasMore:       hasMore,
+		LinesRead:     len(lines),
+		SymlinkTarget: symlinkTarget(req.Path),
 	}, nil
 }
+
+// symlinkTarget returns what path points to, or "" if it is not a symlink.
+func symlinkTarget(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	return target
+}
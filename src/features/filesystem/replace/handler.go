@@ -1,21 +1,34 @@
 package replace
 
 import (
+	"context"
+	"io"
 	"os"
 	"strings"
 
 	replace_models "agent-dev-environment/src/api/v1/filesystem/replace"
 	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/journal"
+	"agent-dev-environment/src/library/workspace"
 )
 
-func Handler(req replace_models.Request) (*replace_models.Response, error) {
-	content, err := os.ReadFile(req.Path)
+func Handler(ctx context.Context, req replace_models.Request) (*replace_models.Response, error) {
+	if err := api.CheckWrite(ctx, req.Path); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(req.Path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, api.NewError(api.NotFound, "File not found")
 		}
 		return nil, err
 	}
+	content, err := readFile(req.Path)
+	if err != nil {
+		return nil, err
+	}
 
 	fileContent := string(content)
 	runes := []rune(fileContent)
@@ -82,12 +95,45 @@ func Handler(req replace_models.Request) (*replace_models.Response, error) {
 	prefix := string(runes[:bestMatch.index])
 	suffix := string(runes[bestMatch.index+oldLen:])
 	newContent := prefix + req.NewString + suffix
+	if err := api.Reserve(req.Path, int64(len(newContent))); err != nil {
+		return nil, err
+	}
+
+	if err := writeFile(req.Path, []byte(newContent)); err != nil {
+		return nil, err
+	}
+	events.Publish(events.Write, req.Path)
+	journal.Record(journal.Replace, api.RequestID(ctx), journal.Change{
+		Path:   req.Path,
+		Before: journal.SavedContent(content, info.Mode()),
+		After:  journal.Content([]byte(newContent)),
+	})
+	res := &replace_models.Response{Path: req.Path}
+	if target, err := os.Readlink(req.Path); err == nil {
+		res.SymlinkTarget = target
+	}
+	return res, nil
+}
 
-	err = os.WriteFile(req.Path, []byte(newContent), 0644)
+func readFile(path string) ([]byte, error) {
+	file, err := workspace.OpenFile(path, os.O_RDONLY, 0)
 	if err != nil {
 		return nil, err
 	}
-	return &replace_models.Response{Path: req.Path}, nil
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+func writeFile(path string, data []byte) error {
+	file, err := workspace.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 func levenshtein(r1, r2 []rune) int {
@@ -0,0 +1,43 @@
+package search
+
+import (
+	"agent-dev-environment/src/api/v1/filesystem/search"
+	"agent-dev-environment/src/library/logger"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// engine produces ripgrep-compatible output for a search request.
+type engine struct {
+	name   string
+	search func(req search.Request) (string, error)
+}
+
+var (
+	ripgrepEngine = engine{name: "ripgrep", search: executeRipgrep}
+	nativeEngine  = engine{name: "native", search: executeNative}
+)
+
+var activeEngine = nativeEngine
+
+// Init selects the search engine. "auto" uses ripgrep when it is on PATH and
+// falls back to the built-in engine otherwise.
+func Init(name string) {
+	switch strings.ToLower(name) {
+	case "auto":
+		if _, err := exec.LookPath("rg"); err == nil {
+			activeEngine = ripgrepEngine
+		} else {
+			activeEngine = nativeEngine
+		}
+	case "ripgrep", "rg":
+		activeEngine = ripgrepEngine
+	case "native":
+		activeEngine = nativeEngine
+	default:
+		panic(fmt.Sprintf("invalid SEARCH_ENGINE: %q. Must be 'auto', 'ripgrep' or 'native'", name))
+	}
+
+	logger.Printf("Using %s search engine", activeEngine.name)
+}
@@ -4,12 +4,12 @@ import (
 	"agent-dev-environment/src/api/v1"
 	"agent-dev-environment/src/api/v1/filesystem/search"
 	"agent-dev-environment/src/library/api"
-	"bytes"
+	"agent-dev-environment/src/library/trigram"
+	"context"
 	"os"
-	"os/exec"
 )
 
-func Handler(req search.Request) (*v1.CommandResponse, error) {
+func Handler(ctx context.Context, req search.Request) (*v1.CommandResponse, error) {
 	// First verify the path exists
 	_, err := os.Stat(req.Path)
 	if err != nil {
@@ -19,40 +19,17 @@ func Handler(req search.Request) (*v1.CommandResponse, error) {
 		return nil, err
 	}
 
-	// Execute ripgrep (rg) command
-	output, err := executeRipgrep(req)
-	if err != nil {
-		return nil, err
+	// The trigram index only helps the built-in engine, so prefer it when
+	// the index can serve this path
+	active := activeEngine
+	if trigram.Covers(req.Path) {
+		active = nativeEngine
 	}
 
-	return &v1.CommandResponse{CommandOutput: output}, nil
-}
-
-func executeRipgrep(req search.Request) (string, error) {
-	var args []string
-
-	if req.FilesWithMatches {
-		args = append(args, "--files-with-matches")
-	}
-	if req.IgnoreCase {
-		args = append(args, "-i")
-	}
-	
-	args = append(args, req.Pattern, req.Path)
-
-	cmd := exec.Command("rg", args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
+	output, err := active.search(req)
 	if err != nil {
-		// rg returns exit code 1 if no matches are found, which is not an error for us
-		if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == 1 {
-			return "", nil
-		}
-		return "", api.NewError(api.InternalServerError, "rg command failed: "+stderr.String())
+		return nil, err
 	}
 
-	return stdout.String(), nil
+	return &v1.CommandResponse{CommandOutput: output}, nil
 }
@@ -0,0 +1,184 @@
+package search
+
+import (
+	"agent-dev-environment/src/api/v1/filesystem/search"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/trigram"
+	"agent-dev-environment/src/library/walk"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type fileMatches struct {
+	path  string
+	lines []string
+}
+
+// executeNative searches with Go's RE2 engine and formats the result the way
+// ripgrep does when its output is not a terminal.
+func executeNative(req search.Request) (string, error) {
+	re, err := compilePattern(req)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(req.Path)
+	if err != nil {
+		return "", err
+	}
+
+	var mu sync.Mutex
+	var results []fileMatches
+
+	visit := func(path string) error {
+		lines, err := matchFile(path, re, req.FilesWithMatches)
+		if errors.Is(err, fs.ErrNotExist) {
+			// Removed since it was listed
+			return nil
+		}
+		if err != nil {
+			// Like rg, which fails the search when a file cannot be read
+			return api.NewError(api.InternalServerError, fmt.Sprintf("Failed to read %s: %v", path, err))
+		}
+		if len(lines) == 0 {
+			return nil
+		}
+
+		mu.Lock()
+		results = append(results, fileMatches{path: path, lines: lines})
+		mu.Unlock()
+		return nil
+	}
+
+	candidates, indexed := indexCandidates(req.Path, info, re)
+	if indexed {
+		for _, path := range candidates {
+			if err := visit(path); err != nil {
+				return "", err
+			}
+		}
+	} else {
+		opts := walk.Options{RespectIgnore: true}
+		err = walk.Walk(req.Path, opts, func(path string, d fs.DirEntry) error {
+			return visit(path)
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].path < results[j].path })
+
+	// Like ripgrep, a single file search prints bare lines
+	withPath := info.IsDir()
+
+	var sb strings.Builder
+	for _, result := range results {
+		if req.FilesWithMatches {
+			sb.WriteString(result.path)
+			sb.WriteByte('\n')
+			continue
+		}
+		for _, line := range result.lines {
+			if withPath {
+				sb.WriteString(result.path)
+				sb.WriteByte(':')
+			}
+			sb.WriteString(line)
+			sb.WriteByte('\n')
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// indexCandidates narrows a directory search to the files the trigram index
+// says may match. Paths are rewritten relative to root as given so the output
+// matches a full walk.
+func indexCandidates(root string, info fs.FileInfo, re *regexp.Regexp) ([]string, bool) {
+	if !info.IsDir() {
+		return nil, false
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, false
+	}
+	candidates, ok := trigram.Candidates(absRoot, re)
+	if !ok {
+		return nil, false
+	}
+
+	paths := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		rel, err := filepath.Rel(absRoot, candidate)
+		if err != nil {
+			return nil, false
+		}
+		paths = append(paths, strings.TrimSuffix(root, "/")+"/"+rel)
+	}
+	return paths, true
+}
+
+func compilePattern(req search.Request) (*regexp.Regexp, error) {
+	pattern := req.Pattern
+	if req.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, api.NewError(api.BadRequest, "Invalid pattern: "+err.Error())
+	}
+	return re, nil
+}
+
+// matchFile returns the matching lines of a text file, reading it a line at
+// a time. Binary files yield no matches. When firstOnly is set, scanning
+// stops at the first match.
+func matchFile(path string, re *regexp.Regexp, firstOnly bool) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReaderSize(f, 64<<10)
+	head, err := r.Peek(fsutil.BinarySniffLen)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if fsutil.IsBinary(head) {
+		return nil, nil
+	}
+
+	var lines []string
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			line = bytes.TrimSuffix(line, []byte("\n"))
+			if re.Match(line) {
+				lines = append(lines, string(line))
+				if firstOnly {
+					return lines, nil
+				}
+			}
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
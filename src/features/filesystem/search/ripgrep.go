@@ -0,0 +1,37 @@
+package search
+
+import (
+	"agent-dev-environment/src/api/v1/filesystem/search"
+	"agent-dev-environment/src/library/api"
+	"bytes"
+	"os/exec"
+)
+
+func executeRipgrep(req search.Request) (string, error) {
+	var args []string
+
+	if req.FilesWithMatches {
+		args = append(args, "--files-with-matches")
+	}
+	if req.IgnoreCase {
+		args = append(args, "-i")
+	}
+	
+	args = append(args, req.Pattern, req.Path)
+
+	cmd := exec.Command("rg", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		// rg returns exit code 1 if no matches are found, which is not an error for us
+		if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", api.NewError(api.InternalServerError, "rg command failed: "+stderr.String())
+	}
+
+	return stdout.String(), nil
+}
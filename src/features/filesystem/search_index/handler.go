@@ -0,0 +1,34 @@
+package search_index
+
+import (
+	"context"
+	"time"
+
+	"agent-dev-environment/src/api/v1"
+	models "agent-dev-environment/src/api/v1/filesystem/search_index"
+	"agent-dev-environment/src/library/trigram"
+)
+
+func StatusHandler(ctx context.Context, req v1.EmptyResponse) (*models.StatusResponse, error) {
+	stats := trigram.CurrentStats()
+	return &models.StatusResponse{
+		Enabled:         stats.Enabled,
+		State:           stats.State,
+		Root:            stats.Root,
+		Files:           stats.Files,
+		Trigrams:        stats.Trigrams,
+		Postings:        stats.Postings,
+		MemoryBytes:     stats.MemoryBytes,
+		BuildDurationMs: stats.BuildDuration.Milliseconds(),
+		BuiltAt:         optionalTime(stats.BuiltAt),
+		LastRefreshAt:   optionalTime(stats.LastRefreshedAt),
+		Error:           stats.Error,
+	}, nil
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
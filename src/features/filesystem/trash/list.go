@@ -0,0 +1,23 @@
+package trash
+
+import (
+	"context"
+
+	"agent-dev-environment/src/api/v1"
+	trash_models "agent-dev-environment/src/api/v1/filesystem/trash"
+	"agent-dev-environment/src/library/trash"
+)
+
+func ListHandler(ctx context.Context, req v1.EmptyResponse) (*trash_models.ListResponse, error) {
+	entries, err := trash.List()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &trash_models.ListResponse{Entries: make([]trash_models.Entry, 0, len(entries))}
+	for _, entry := range entries {
+		res.Entries = append(res.Entries, trash_models.Entry(entry))
+		res.TotalBytes += entry.Size
+	}
+	return res, nil
+}
@@ -0,0 +1,21 @@
+package trash
+
+import (
+	"context"
+	"errors"
+
+	trash_models "agent-dev-environment/src/api/v1/filesystem/trash"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/trash"
+)
+
+func PurgeHandler(ctx context.Context, req trash_models.PurgeRequest) (*trash_models.PurgeResponse, error) {
+	purged, freed, err := trash.Purge(req.IDs)
+	if err != nil {
+		if errors.Is(err, trash.ErrNotFound) {
+			return nil, api.NewError(api.NotFound, "Trash entry not found")
+		}
+		return nil, err
+	}
+	return &trash_models.PurgeResponse{Purged: purged, BytesFreed: freed}, nil
+}
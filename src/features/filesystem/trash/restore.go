@@ -0,0 +1,72 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"syscall"
+
+	trash_models "agent-dev-environment/src/api/v1/filesystem/trash"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/journal"
+	"agent-dev-environment/src/library/trash"
+)
+
+func RestoreHandler(ctx context.Context, req trash_models.RestoreRequest) (*trash_models.RestoreResponse, error) {
+	entry, err := trash.Get(req.ID)
+	if err != nil {
+		return nil, api.NewError(api.NotFound, "Trash entry not found")
+	}
+	item, err := trash.Item(req.ID)
+	if err != nil {
+		return nil, api.NewError(api.NotFound, "Trash entry not found")
+	}
+
+	destination := req.Destination
+	if destination == "" {
+		// Not among the request's paths, so confined here
+		destination = entry.OriginalPath
+		if err := api.Confine(destination); err != nil {
+			return nil, err
+		}
+	}
+	if err := api.CheckTree(ctx, destination); err != nil {
+		return nil, err
+	}
+	if err := api.CheckMirror(ctx, item, destination); err != nil {
+		return nil, err
+	}
+
+	// Whatever an overwrite replaces is kept so the restore can be undone
+	replaced := journal.Absent
+	if req.Overwrite {
+		replaced = journal.SavedStat(destination)
+	}
+
+	path, err := trash.Restore(req.ID, destination, req.Overwrite)
+	if err != nil {
+		journal.Release(replaced)
+		switch {
+		case errors.Is(err, trash.ErrNotFound):
+			return nil, api.NewError(api.NotFound, "Trash entry not found")
+		// As in move, ENOTEMPTY also satisfies fs.ErrExist
+		case errors.Is(err, syscall.ENOTEMPTY), req.Overwrite && errors.Is(err, fs.ErrExist):
+			return nil, api.NewError(api.Conflict, "Destination is a non-empty directory")
+		case errors.Is(err, fs.ErrExist):
+			return nil, api.NewError(api.Conflict, "Destination path already exists")
+		case errors.Is(err, syscall.EISDIR), errors.Is(err, syscall.ENOTDIR):
+			return nil, api.NewError(api.Conflict, "Cannot replace a directory with a file or a file with a directory")
+		}
+		return nil, api.NewError(api.InternalServerError, "Failed to restore: "+err.Error())
+	}
+	events.Publish(events.Create, path)
+	journal.Record(journal.Restore, api.RequestID(ctx), journal.Change{
+		Path:   path,
+		Before: replaced,
+		After:  journal.Stat(path),
+		Tree:   entry.IsDir,
+	})
+
+	return &trash_models.RestoreResponse{Path: path}, nil
+}
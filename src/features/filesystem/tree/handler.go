@@ -0,0 +1,237 @@
+package tree
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	tree_models "agent-dev-environment/src/api/v1/filesystem/tree"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/gitignore"
+	"agent-dev-environment/src/library/trash"
+)
+
+// countLimit bounds how many files are counted inside a collapsed directory.
+const countLimit = 100000
+
+func Handler(ctx context.Context, req tree_models.Request) (*tree_models.Response, error) {
+	info, err := os.Stat(req.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, api.NewError(api.NotFound, "Path not found")
+		}
+		return nil, err
+	}
+
+	b := builder{
+		maxDepth:       tree_models.DefaultMaxDepth,
+		maxEntries:     tree_models.DefaultMaxEntriesPerDir,
+		showHidden:     req.ShowHidden,
+		includeIgnored: req.IncludeIgnored,
+	}
+	if req.MaxDepth != nil {
+		b.maxDepth = *req.MaxDepth
+	}
+	if req.MaxEntriesPerDir != nil {
+		b.maxEntries = *req.MaxEntriesPerDir
+	}
+
+	root := &tree_models.Node{Name: req.Path, Type: nodeType(info.Mode())}
+	if info.IsDir() {
+		absPath, err := filepath.Abs(req.Path)
+		if err != nil {
+			return nil, api.NewError(api.BadRequest, "Invalid path: "+err.Error())
+		}
+		b.expand(root, absPath, gitignore.New(absPath), 0)
+	}
+
+	if req.Format == tree_models.FormatJSON {
+		return &tree_models.Response{Root: root}, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(label(root))
+	sb.WriteByte('\n')
+	render(&sb, root.Children, "")
+	return &tree_models.Response{Tree: sb.String()}, nil
+}
+
+type builder struct {
+	maxDepth       int
+	maxEntries     int
+	showHidden     bool
+	includeIgnored bool
+}
+
+// expand fills node with the children of dir. The matcher passed in already
+// contains the rules of dir's own ignore files.
+func (b *builder) expand(node *tree_models.Node, dir string, matcher *gitignore.Matcher, depth int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	// Directories first, then files, each alphabetically
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	type listed struct {
+		entry   fs.DirEntry
+		path    string
+		ignored bool
+	}
+	var kept []listed
+	for _, entry := range entries {
+		name := entry.Name()
+		if !b.showHidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		if trash.Within(path) {
+			continue
+		}
+
+		ignored := name == ".git" || (!b.includeIgnored && matcher.Ignored(path, entry.IsDir()))
+		if ignored && !entry.IsDir() {
+			continue
+		}
+		kept = append(kept, listed{entry: entry, path: path, ignored: ignored})
+	}
+
+	// Truncated before expanding, so omitted directories are never walked
+	omitted := 0
+	if len(kept) > b.maxEntries {
+		omitted = len(kept) - b.maxEntries
+		kept = kept[:b.maxEntries]
+	}
+
+	children := make([]*tree_models.Node, 0, len(kept)+1)
+	for _, l := range kept {
+		child := &tree_models.Node{Name: l.entry.Name(), Type: nodeType(l.entry.Type())}
+		if l.entry.IsDir() {
+			switch {
+			case l.ignored:
+				b.collapse(child, l.path, tree_models.CollapsedIgnored)
+			case depth+1 >= b.maxDepth:
+				b.collapse(child, l.path, tree_models.CollapsedDepth)
+			default:
+				b.expand(child, l.path, matcher.Descend(l.path), depth+1)
+			}
+		}
+		children = append(children, child)
+	}
+
+	if omitted > 0 {
+		children = append(children, &tree_models.Node{
+			Name:    fmt.Sprintf("… %s more entries", formatCount(omitted)),
+			Type:    tree_models.TypeSummary,
+			Omitted: omitted,
+		})
+	}
+	node.Children = children
+}
+
+func (b *builder) collapse(node *tree_models.Node, dir string, reason string) {
+	count, truncated := countFiles(dir)
+	if count == 0 && reason == tree_models.CollapsedDepth {
+		// Nothing is hidden, so there is nothing to summarise
+		return
+	}
+	node.Collapsed = reason
+	node.FileCount = count
+	node.Truncated = truncated
+}
+
+func countFiles(dir string) (int, bool) {
+	count := 0
+	truncated := false
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			count++
+			if count >= countLimit {
+				truncated = true
+				return filepath.SkipAll
+			}
+		}
+		return nil
+	})
+	return count, truncated
+}
+
+func render(sb *strings.Builder, nodes []*tree_models.Node, prefix string) {
+	for i, node := range nodes {
+		connector, childPrefix := "├── ", "│   "
+		if i == len(nodes)-1 {
+			connector, childPrefix = "└── ", "    "
+		}
+
+		sb.WriteString(prefix)
+		sb.WriteString(connector)
+		sb.WriteString(label(node))
+		sb.WriteByte('\n')
+		render(sb, node.Children, prefix+childPrefix)
+	}
+}
+
+func label(node *tree_models.Node) string {
+	if node.Type != tree_models.TypeDir {
+		return node.Name
+	}
+
+	name := strings.TrimSuffix(node.Name, "/") + "/"
+	if node.Collapsed == "" {
+		return name
+	}
+
+	files := formatCount(node.FileCount)
+	if node.Truncated {
+		files += "+"
+	}
+	noun := "files"
+	if node.FileCount == 1 {
+		noun = "file"
+	}
+	if node.Collapsed == tree_models.CollapsedIgnored {
+		return fmt.Sprintf("%s (%s %s, ignored)", name, files, noun)
+	}
+	return fmt.Sprintf("%s (%s %s)", name, files, noun)
+}
+
+func nodeType(mode fs.FileMode) string {
+	switch {
+	case mode&fs.ModeSymlink != 0:
+		return tree_models.TypeSymlink
+	case mode.IsDir():
+		return tree_models.TypeDir
+	case mode.IsRegular():
+		return tree_models.TypeFile
+	default:
+		return tree_models.TypeOther
+	}
+}
+
+// formatCount renders n with thousands separators, e.g. 41233 as "41,233".
+func formatCount(n int) string {
+	digits := strconv.Itoa(n)
+	var sb strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(d)
+	}
+	return sb.String()
+}
@@ -0,0 +1,28 @@
+package usage
+
+import (
+	"context"
+
+	"agent-dev-environment/src/api/v1"
+	usage_models "agent-dev-environment/src/api/v1/filesystem/usage"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/quota"
+)
+
+// Handler measures the current usage, including changes made by commands
+// since the last write through the API.
+func Handler(ctx context.Context, req v1.EmptyResponse) (*usage_models.Response, error) {
+	usage, limits, err := quota.Current()
+	if err != nil {
+		return nil, api.NewError(api.InternalServerError, "Failed to measure usage: "+err.Error())
+	}
+	return &usage_models.Response{
+		Root:         quota.Root(),
+		Bytes:        usage.Bytes,
+		Files:        usage.Files,
+		MaxBytes:     limits.MaxBytes,
+		MaxFileBytes: limits.MaxFileBytes,
+		MaxFiles:     limits.MaxFiles,
+		ScannedAt:    usage.ScannedAt,
+	}, nil
+}
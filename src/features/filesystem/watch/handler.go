@@ -0,0 +1,97 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	watch_models "agent-dev-environment/src/api/v1/filesystem/watch"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/watch"
+)
+
+// heartbeat keeps idle streams from being closed by proxies.
+const heartbeat = 15 * time.Second
+
+// Handler streams changes below req.Path as server-sent events until the
+// client disconnects.
+func Handler(ctx context.Context, req watch_models.Request, w http.ResponseWriter, r *http.Request) error {
+	info, err := os.Stat(req.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return api.NewError(api.NotFound, "Directory not found")
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return api.NewError(api.BadRequest, "Path is not a directory")
+	}
+
+	debounce := req.DebounceMs
+	if debounce == 0 {
+		debounce = watch_models.DefaultDebounceMs
+	}
+	watcher, err := watch.New(req.Path, watch.Options{
+		Include:       req.Include,
+		Exclude:       req.Exclude,
+		RespectIgnore: !req.IncludeIgnored,
+		Debounce:      time.Duration(debounce) * time.Millisecond,
+	})
+	if err != nil {
+		return api.NewError(api.InternalServerError, "Failed to watch directory: "+err.Error())
+	}
+	defer watcher.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	send := func(name string, data any) error {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if err := send("ready", map[string]string{"path": req.Path}); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return err
+			}
+			if err := rc.Flush(); err != nil {
+				return err
+			}
+		case batch, ok := <-watcher.Events():
+			if !ok {
+				return nil
+			}
+			for _, ev := range batch {
+				// Denied paths are not revealed, not even by name
+				if api.CheckRead(ev.Path) != nil {
+					continue
+				}
+				if err := send("change", watch_models.Event{Op: string(ev.Op), Path: ev.Path, IsDir: ev.IsDir}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
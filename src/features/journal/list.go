@@ -0,0 +1,50 @@
+package journal
+
+import (
+	"context"
+
+	journal_models "agent-dev-environment/src/api/v1/journal"
+	"agent-dev-environment/src/library/journal"
+)
+
+func ListHandler(ctx context.Context, req journal_models.ListRequest) (*journal_models.ListResponse, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = journal_models.DefaultLimit
+	}
+
+	entries := journal.List(req.Path, limit)
+	res := &journal_models.ListResponse{Entries: make([]journal_models.Entry, 0, len(entries))}
+	for _, entry := range entries {
+		res.Entries = append(res.Entries, toModel(entry))
+	}
+	return res, nil
+}
+
+func toModel(entry journal.Entry) journal_models.Entry {
+	res := journal_models.Entry{
+		ID:         entry.ID,
+		Op:         string(entry.Op),
+		RequestID:  entry.RequestID,
+		Time:       entry.Time,
+		Changes:    make([]journal_models.Change, 0, len(entry.Changes)),
+		Reversible: entry.Reversible,
+		UndoneAt:   entry.UndoneAt,
+	}
+	for _, c := range entry.Changes {
+		res.Changes = append(res.Changes, journal_models.Change{Path: c.Path, From: c.From, Kind: kind(c)})
+	}
+	return res
+}
+
+func kind(c journal.Change) string {
+	switch {
+	case c.From != "":
+		return "moved"
+	case !c.Before.Exists:
+		return "created"
+	case !c.After.Exists:
+		return "deleted"
+	}
+	return "modified"
+}
@@ -0,0 +1,96 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	journal_models "agent-dev-environment/src/api/v1/journal"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/journal"
+)
+
+func UndoHandler(ctx context.Context, req journal_models.UndoRequest) (*journal_models.UndoResponse, error) {
+	allow := func(path string) error { return api.CheckWrite(ctx, path) }
+
+	var undone []journal.Entry
+	var err error
+	if req.ID != "" {
+		var entry *journal.Entry
+		entry, err = journal.Undo(req.ID, api.RequestID(ctx), allow)
+		if entry != nil {
+			undone = append(undone, *entry)
+		}
+	} else {
+		count := req.Count
+		if count == 0 {
+			count = 1
+		}
+		undone, err = journal.UndoLast(count, api.RequestID(ctx), allow)
+	}
+
+	for _, entry := range undone {
+		publish(entry)
+	}
+	if err != nil {
+		return nil, undoError(err, len(undone))
+	}
+
+	res := &journal_models.UndoResponse{Undone: make([]journal_models.Entry, 0, len(undone))}
+	for _, entry := range undone {
+		res.Undone = append(res.Undone, toModel(entry))
+	}
+	return res, nil
+}
+
+func undoError(err error, undone int) error {
+	var appErr *api.AppError
+	if errors.As(err, &appErr) {
+		return err
+	}
+	if errors.Is(err, journal.ErrNotFound) {
+		return api.NewError(api.NotFound, "Journal entry not found")
+	}
+
+	var msg string
+	status := api.Conflict
+	var undoErr *journal.UndoError
+	var conflict *journal.ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		msg = fmt.Sprintf("Cannot undo operation %s: %s changed since", conflict.EntryID, conflict.Path)
+	case errors.Is(err, journal.ErrAlreadyUndone) && errors.As(err, &undoErr):
+		msg = fmt.Sprintf("Operation %s was already undone", undoErr.EntryID)
+	case errors.Is(err, journal.ErrIrreversible) && errors.As(err, &undoErr):
+		msg = fmt.Sprintf("Operation %s cannot be undone: its previous state was not kept", undoErr.EntryID)
+	case errors.As(err, &undoErr):
+		status = api.InternalServerError
+		msg = fmt.Sprintf("Failed to undo operation %s: %v", undoErr.EntryID, undoErr.Err)
+	default:
+		return api.NewError(api.InternalServerError, "Failed to undo: "+err.Error())
+	}
+	if undone > 0 {
+		msg += fmt.Sprintf(" (%d newer operations were undone)", undone)
+	}
+	return api.NewError(status, msg)
+}
+
+// publish reports the paths an undo changed.
+func publish(entry journal.Entry) {
+	for _, c := range entry.Changes {
+		switch {
+		case c.From != "":
+			events.PublishRename(c.Path, c.From)
+			if c.Before.Exists {
+				events.Publish(events.Create, c.Path)
+			}
+		case !c.Before.Exists:
+			events.Publish(events.Remove, c.Path)
+		case !c.After.Exists:
+			events.Publish(events.Create, c.Path)
+		default:
+			events.Publish(events.Write, c.Path)
+		}
+	}
+}
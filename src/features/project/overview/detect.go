@@ -0,0 +1,177 @@
+package overview
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+
+	models "agent-dev-environment/src/api/v1/project/overview"
+)
+
+var manifestKinds = map[string]string{
+	"go.mod":           "go",
+	"package.json":     "npm",
+	"Cargo.toml":       "cargo",
+	"pyproject.toml":   "python",
+	"setup.py":         "python",
+	"requirements.txt": "python",
+	"Makefile":         "make",
+	"GNUmakefile":      "make",
+	"mise.toml":        "mise",
+	".mise.toml":       "mise",
+}
+
+var (
+	goModule     = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+	tomlSection  = regexp.MustCompile(`^\[([^\]]+)\]\s*$`)
+	tomlName     = regexp.MustCompile(`^name\s*=\s*["']([^"']+)["']`)
+	goMain       = regexp.MustCompile(`(?m)^package\s+main\b`)
+	goMainFunc   = regexp.MustCompile(`(?m)^func\s+main\(\)`)
+	pythonScript = regexp.MustCompile(`(?m)^if\s+__name__\s*==\s*["']__main__["']\s*:`)
+)
+
+// testDirNames are directories whose contents are all considered tests.
+var testDirNames = map[string]bool{
+	"test":      true,
+	"tests":     true,
+	"__tests__": true,
+	"spec":      true,
+	"e2e":       true,
+}
+
+// manifestName extracts the module or package name a manifest declares.
+func manifestName(name string, content []byte) string {
+	switch name {
+	case "go.mod":
+		if m := goModule.FindSubmatch(content); m != nil {
+			return string(m[1])
+		}
+	case "package.json":
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(content, &pkg) == nil {
+			return pkg.Name
+		}
+	case "Cargo.toml":
+		return tomlPackageName(content, "package")
+	case "pyproject.toml":
+		if name := tomlPackageName(content, "project"); name != "" {
+			return name
+		}
+		return tomlPackageName(content, "tool.poetry")
+	}
+	return ""
+}
+
+// tomlPackageName reads the name key of a TOML table without a full parser.
+func tomlPackageName(content []byte, table string) string {
+	inTable := false
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if m := tomlSection.FindStringSubmatch(line); m != nil {
+			inTable = strings.TrimSpace(m[1]) == table
+			continue
+		}
+		if inTable {
+			if m := tomlName.FindStringSubmatch(line); m != nil {
+				return m[1]
+			}
+		}
+	}
+	return ""
+}
+
+// packageEntryPoints returns the main and bin scripts declared by the
+// package.json at rel, resolved relative to the project root.
+func packageEntryPoints(rel string, content []byte) []models.EntryPoint {
+	var pkg struct {
+		Main string          `json:"main"`
+		Bin  json.RawMessage `json:"bin"`
+	}
+	if json.Unmarshal(content, &pkg) != nil {
+		return nil
+	}
+
+	dir := path.Dir(rel)
+	var entries []models.EntryPoint
+	if pkg.Main != "" {
+		entries = append(entries, models.EntryPoint{Path: path.Join(dir, pkg.Main), Kind: "package.json main"})
+	}
+
+	// bin is either a single script or a map of command names to scripts
+	var bins []string
+	var single string
+	var named map[string]string
+	if json.Unmarshal(pkg.Bin, &single) == nil && single != "" {
+		bins = append(bins, single)
+	} else if json.Unmarshal(pkg.Bin, &named) == nil {
+		for _, script := range named {
+			bins = append(bins, script)
+		}
+	}
+	for _, script := range bins {
+		entries = append(entries, models.EntryPoint{Path: path.Join(dir, script), Kind: "package.json bin"})
+	}
+	return entries
+}
+
+// sourceEntryPoint reports whether the source file at rel is a program entry point.
+func sourceEntryPoint(rel string, content []byte) (string, bool) {
+	name := path.Base(rel)
+	switch path.Ext(name) {
+	case ".go":
+		if !strings.HasSuffix(name, "_test.go") && goMain.Match(content) && goMainFunc.Match(content) {
+			return "go main", true
+		}
+	case ".rs":
+		if rel == "src/main.rs" || strings.HasSuffix(rel, "/src/main.rs") {
+			return "rust main", true
+		}
+		if src := path.Dir(path.Dir(rel)); path.Base(path.Dir(rel)) == "bin" && (src == "src" || strings.HasSuffix(src, "/src")) {
+			return "rust bin", true
+		}
+	case ".py":
+		if name == "__main__.py" {
+			return "python __main__", true
+		}
+		if pythonScript.Match(content) {
+			return "python script", true
+		}
+	}
+	return "", false
+}
+
+// testRoot returns the directory a test file is grouped under: the outermost
+// enclosing test directory, or the file's own directory for test files
+// recognised by name. It returns false for files that are not tests.
+func testRoot(rel string) (string, bool) {
+	parts := strings.Split(path.Dir(rel), "/")
+	for i, part := range parts {
+		if testDirNames[part] {
+			return strings.Join(parts[:i+1], "/"), true
+		}
+	}
+	if isTestFile(path.Base(rel)) {
+		return path.Dir(rel), true
+	}
+	return "", false
+}
+
+func isTestFile(name string) bool {
+	base := strings.TrimSuffix(name, path.Ext(name))
+	switch path.Ext(name) {
+	case ".go":
+		return strings.HasSuffix(base, "_test")
+	case ".py":
+		return strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test")
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".mts", ".cts":
+		return strings.HasSuffix(base, ".test") || strings.HasSuffix(base, ".spec")
+	case ".rb":
+		return strings.HasSuffix(base, "_spec") || strings.HasSuffix(base, "_test")
+	case ".java", ".kt":
+		return strings.HasSuffix(base, "Test") || strings.HasSuffix(base, "Tests")
+	}
+	return false
+}
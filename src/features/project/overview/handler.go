@@ -0,0 +1,191 @@
+package overview
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	models "agent-dev-environment/src/api/v1/project/overview"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/walk"
+)
+
+// maxReadSize bounds the files read for line counts and detection. Larger
+// files still count towards file and disk usage totals.
+const maxReadSize = 8 << 20
+
+// Hidden files are included so that manifests such as .mise.toml are found
+var walkOptions = walk.Options{RespectIgnore: true, Hidden: true}
+
+type scan struct {
+	mu          sync.Mutex
+	languages   map[string]*models.Language
+	manifests   []models.Manifest
+	testDirs    map[string]int
+	entryPoints []models.EntryPoint
+	usage       map[string]*models.DirUsage
+	totalFiles  int
+	totalBytes  int64
+}
+
+func Handler(ctx context.Context, req models.Request) (*models.Response, error) {
+	info, err := os.Stat(req.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, api.NewError(api.NotFound, "Path not found")
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, api.NewError(api.BadRequest, "Path must be a directory")
+	}
+
+	s := &scan{
+		languages: map[string]*models.Language{},
+		testDirs:  map[string]int{},
+		usage:     map[string]*models.DirUsage{},
+	}
+	err = walk.Walk(req.Path, walkOptions, func(file string, d fs.DirEntry) error {
+		rel, err := filepath.Rel(req.Path, file)
+		if err != nil {
+			return nil
+		}
+		s.add(file, filepath.ToSlash(rel), d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.response(), nil
+}
+
+func (s *scan) add(file, rel string, d fs.DirEntry) {
+	info, err := d.Info()
+	if err != nil {
+		return
+	}
+	size := info.Size()
+	name := path.Base(rel)
+	lang := language(rel)
+	manifestKind, isManifest := manifestKinds[name]
+
+	var content []byte
+	if (lang != "" || isManifest) && size <= maxReadSize {
+		content, _ = os.ReadFile(file)
+		if fsutil.IsBinary(content) {
+			content = nil
+		}
+	}
+
+	var entryPoints []models.EntryPoint
+	if kind, ok := sourceEntryPoint(rel, content); ok {
+		entryPoints = append(entryPoints, models.EntryPoint{Path: rel, Kind: kind})
+	}
+	if name == "package.json" {
+		entryPoints = append(entryPoints, packageEntryPoints(rel, content)...)
+	}
+
+	top := "."
+	if i := strings.IndexByte(rel, '/'); i >= 0 {
+		top = rel[:i]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.totalFiles++
+	s.totalBytes += size
+
+	usage := s.usage[top]
+	if usage == nil {
+		usage = &models.DirUsage{Path: top}
+		s.usage[top] = usage
+	}
+	usage.Files++
+	usage.Bytes += size
+
+	if lang != "" {
+		stats := s.languages[lang]
+		if stats == nil {
+			stats = &models.Language{Name: lang}
+			s.languages[lang] = stats
+		}
+		stats.Files++
+		stats.Bytes += size
+		stats.Lines += countLines(content)
+	}
+
+	if isManifest {
+		s.manifests = append(s.manifests, models.Manifest{Path: rel, Kind: manifestKind, Name: manifestName(name, content)})
+	}
+	if dir, ok := testRoot(rel); ok {
+		s.testDirs[dir]++
+	}
+	s.entryPoints = append(s.entryPoints, entryPoints...)
+}
+
+func (s *scan) response() *models.Response {
+	resp := &models.Response{
+		Manifests:   s.manifests,
+		EntryPoints: s.entryPoints,
+		TotalFiles:  s.totalFiles,
+		TotalBytes:  s.totalBytes,
+	}
+	if resp.Manifests == nil {
+		resp.Manifests = []models.Manifest{}
+	}
+	if resp.EntryPoints == nil {
+		resp.EntryPoints = []models.EntryPoint{}
+	}
+
+	resp.Languages = []models.Language{}
+	for _, lang := range s.languages {
+		resp.Languages = append(resp.Languages, *lang)
+	}
+	sort.Slice(resp.Languages, func(i, j int) bool {
+		a, b := resp.Languages[i], resp.Languages[j]
+		if a.Lines != b.Lines {
+			return a.Lines > b.Lines
+		}
+		if a.Files != b.Files {
+			return a.Files > b.Files
+		}
+		return a.Name < b.Name
+	})
+
+	resp.TestDirs = []models.TestDir{}
+	for dir, files := range s.testDirs {
+		resp.TestDirs = append(resp.TestDirs, models.TestDir{Path: dir, Files: files})
+	}
+	sort.Slice(resp.TestDirs, func(i, j int) bool { return resp.TestDirs[i].Path < resp.TestDirs[j].Path })
+
+	resp.DiskUsage = []models.DirUsage{}
+	for _, usage := range s.usage {
+		resp.DiskUsage = append(resp.DiskUsage, *usage)
+	}
+	sort.Slice(resp.DiskUsage, func(i, j int) bool {
+		a, b := resp.DiskUsage[i], resp.DiskUsage[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return a.Path < b.Path
+	})
+
+	sort.Slice(resp.Manifests, func(i, j int) bool { return resp.Manifests[i].Path < resp.Manifests[j].Path })
+	sort.Slice(resp.EntryPoints, func(i, j int) bool {
+		a, b := resp.EntryPoints[i], resp.EntryPoints[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Kind < b.Kind
+	})
+
+	return resp
+}
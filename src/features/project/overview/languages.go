@@ -0,0 +1,82 @@
+package overview
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+var languagesByExt = map[string]string{
+	".go":     "Go",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".mts":    "TypeScript",
+	".cts":    "TypeScript",
+	".js":     "JavaScript",
+	".jsx":    "JavaScript",
+	".mjs":    "JavaScript",
+	".cjs":    "JavaScript",
+	".py":     "Python",
+	".rs":     "Rust",
+	".java":   "Java",
+	".kt":     "Kotlin",
+	".kts":    "Kotlin",
+	".c":      "C",
+	".h":      "C",
+	".cc":     "C++",
+	".cpp":    "C++",
+	".cxx":    "C++",
+	".hpp":    "C++",
+	".cs":     "C#",
+	".rb":     "Ruby",
+	".php":    "PHP",
+	".swift":  "Swift",
+	".scala":  "Scala",
+	".lua":    "Lua",
+	".sh":     "Shell",
+	".bash":   "Shell",
+	".zsh":    "Shell",
+	".sql":    "SQL",
+	".html":   "HTML",
+	".css":    "CSS",
+	".scss":   "SCSS",
+	".vue":    "Vue",
+	".svelte": "Svelte",
+	".md":     "Markdown",
+	".json":   "JSON",
+	".yaml":   "YAML",
+	".yml":    "YAML",
+	".toml":   "TOML",
+	".proto":  "Protocol Buffers",
+	".tf":     "HCL",
+}
+
+var languagesByName = map[string]string{
+	"Dockerfile":  "Dockerfile",
+	"Makefile":    "Makefile",
+	"GNUmakefile": "Makefile",
+}
+
+// language returns the language of the file at path, or "" if unknown.
+func language(path string) string {
+	name := filepath.Base(path)
+	if lang, ok := languagesByName[name]; ok {
+		return lang
+	}
+	if strings.HasPrefix(name, "Dockerfile.") {
+		return "Dockerfile"
+	}
+	return languagesByExt[strings.ToLower(filepath.Ext(name))]
+}
+
+// countLines counts lines the way wc -l would, plus a final unterminated line.
+func countLines(content []byte) int {
+	if len(content) == 0 {
+		return 0
+	}
+	lines := bytes.Count(content, []byte{'\n'})
+	if content[len(content)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
@@ -0,0 +1,39 @@
+package scratch
+
+import (
+	"context"
+	"time"
+
+	scratch_models "agent-dev-environment/src/api/v1/scratch"
+	"agent-dev-environment/src/library/api"
+	scratch_store "agent-dev-environment/src/library/scratch"
+)
+
+func CreateHandler(ctx context.Context, req scratch_models.CreateRequest) (*scratch_models.Dir, error) {
+	prefix := req.Prefix
+	if prefix == "" {
+		prefix = scratch_models.DefaultPrefix
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		// Validated already
+		ttl, _ = time.ParseDuration(req.TTL)
+	}
+
+	d, err := scratch_store.Create(prefix, api.Session(ctx), ttl)
+	if err != nil {
+		return nil, api.NewError(api.InternalServerError, "Failed to create scratch directory: "+err.Error())
+	}
+	res := model(d)
+	return &res, nil
+}
+
+func model(d scratch_store.Dir) scratch_models.Dir {
+	return scratch_models.Dir{
+		ID:        d.ID,
+		Path:      d.Path,
+		Session:   d.Session,
+		CreatedAt: d.CreatedAt,
+		ExpiresAt: d.ExpiresAt,
+	}
+}
@@ -0,0 +1,21 @@
+package scratch
+
+import (
+	"context"
+	"errors"
+
+	"agent-dev-environment/src/api/v1"
+	scratch_models "agent-dev-environment/src/api/v1/scratch"
+	"agent-dev-environment/src/library/api"
+	scratch_store "agent-dev-environment/src/library/scratch"
+)
+
+func DeleteHandler(ctx context.Context, req scratch_models.DeleteRequest) (*v1.EmptyResponse, error) {
+	if err := scratch_store.Delete(req.ID); err != nil {
+		if errors.Is(err, scratch_store.ErrNotFound) {
+			return nil, api.NewError(api.NotFound, "Scratch directory not found")
+		}
+		return nil, api.NewError(api.InternalServerError, "Failed to delete scratch directory: "+err.Error())
+	}
+	return &v1.EmptyResponse{}, nil
+}
@@ -0,0 +1,23 @@
+package scratch
+
+import (
+	"context"
+
+	"agent-dev-environment/src/api/v1"
+	scratch_models "agent-dev-environment/src/api/v1/scratch"
+	"agent-dev-environment/src/library/api"
+	scratch_store "agent-dev-environment/src/library/scratch"
+)
+
+func ListHandler(ctx context.Context, req v1.EmptyResponse) (*scratch_models.ListResponse, error) {
+	dirs, err := scratch_store.List()
+	if err != nil {
+		return nil, api.NewError(api.InternalServerError, "Failed to list scratch directories: "+err.Error())
+	}
+
+	res := &scratch_models.ListResponse{Dirs: make([]scratch_models.Dir, 0, len(dirs))}
+	for _, d := range dirs {
+		res.Dirs = append(res.Dirs, model(d))
+	}
+	return res, nil
+}
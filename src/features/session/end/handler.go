@@ -0,0 +1,22 @@
+package end
+
+import (
+	"context"
+
+	"agent-dev-environment/src/api/v1"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/scratch"
+	"agent-dev-environment/src/library/session"
+)
+
+// Handler ends the session named in the request, deleting the scratch
+// directories tied to it.
+func Handler(ctx context.Context, req v1.EmptyResponse) (*v1.EmptyResponse, error) {
+	id := api.Session(ctx)
+	if id == session.Default {
+		return nil, api.NewError(api.BadRequest, "The default session cannot be ended; send an "+api.SessionIDHeader+" header")
+	}
+	session.End(id)
+	scratch.Sweep()
+	return &v1.EmptyResponse{}, nil
+}
@@ -3,8 +3,10 @@ package reload_env
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"agent-dev-environment/src/api/v1"
@@ -12,9 +14,10 @@ import (
 	"agent-dev-environment/src/library/logger"
 )
 
-func Handler(req v1.EmptyResponse) (*v1.CommandResponse, error) {
+func Handler(ctx context.Context, req v1.EmptyResponse) (*v1.CommandResponse, error) {
 	// Execute mise run reload-env
 	cmd := exec.Command("mise", "run", "reload-env")
+	cmd.Dir = api.Cwd(ctx)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -26,7 +29,7 @@ func Handler(req v1.EmptyResponse) (*v1.CommandResponse, error) {
 
 	// After running the script, we load the .env file into the current process
 	// so that subsequent shell commands inherit these variables.
-	if err := loadDotEnv(".env"); err != nil {
+	if err := loadDotEnv(filepath.Join(cmd.Dir, ".env")); err != nil {
 		logger.Error("Failed to load .env file after reload-env", "error", err)
 		// We don't return error here because the command itself succeeded
 	}
@@ -1,23 +1,111 @@
 package run
 
 import (
-	"agent-dev-environment/src/api/v1"
 	"agent-dev-environment/src/api/v1/shell/run"
 	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/trigram"
 	"bytes"
+	"context"
+	"errors"
 	"os/exec"
+	"strings"
+	"syscall"
+	"time"
 )
 
-func Handler(req run.Request) (*v1.CommandResponse, error) {
-	cmd := exec.Command(req.Command, req.Args...)
+// waitDelay is how long output is still collected after a command exits or
+// is killed. Children it started may hold on to its output otherwise.
+const waitDelay = 2 * time.Second
+
+// Handler runs the command and reports how it ended. A command that fails
+// is still a successful request; only commands that cannot be started are
+// errors.
+func Handler(ctx context.Context, req run.Request) (*run.Response, error) {
+	if err := checkArgs(ctx, req.Command, req.Args); err != nil {
+		return nil, err
+	}
+
+	runCtx := ctx
+	if req.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, time.Duration(req.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(runCtx, req.Command, req.Args...)
+	cmd.Dir = api.Cwd(ctx)
+	cmd.WaitDelay = waitDelay
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
-	if err != nil {
-		return nil, api.NewError(api.InternalServerError, "command failed: "+stderr.String())
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, api.NewError(api.InternalServerError, "Failed to start command: "+err.Error())
 	}
+	// Exit codes and signals are read from the process state instead
+	cmd.Wait()
+	duration := time.Since(start)
+	// Commands may edit files behind the index's back
+	trigram.MarkStale()
 
-	return &v1.CommandResponse{CommandOutput: stdout.String()}, nil
+	res := &run.Response{
+		ExitCode:   cmd.ProcessState.ExitCode(),
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		DurationMs: duration.Milliseconds(),
+	}
+	if status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		res.Signal = status.Signal().String()
+		res.TimedOut = errors.Is(runCtx.Err(), context.DeadlineExceeded)
+	}
+	return res, nil
+}
+
+// checkArgs applies the protected-path rules to arguments that look like
+// paths. Commands are not parsed fully, so this is best effort: operands of
+// commands known to modify files are checked as writes, all others as reads.
+func checkArgs(ctx context.Context, command string, args []string) error {
+	var operands []string
+	inPlace := false
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			operands = append(operands, arg)
+			continue
+		}
+		if _, value, ok := strings.Cut(arg, "="); ok && value != "" {
+			operands = append(operands, value)
+		}
+		if arg == "-i" || strings.HasPrefix(arg, "--in-place") || (strings.HasPrefix(arg, "-i") && command == "sed") {
+			inPlace = true
+		}
+	}
+
+	for i, operand := range operands {
+		operand = api.Abs(ctx, operand)
+		write := false
+		switch command {
+		case "rm", "mv":
+			// Whole directories may be removed or moved away
+			if err := api.CheckTree(ctx, operand); err != nil {
+				return err
+			}
+			continue
+		case "touch", "mkdir", "tee":
+			write = true
+		case "cp":
+			write = i == len(operands)-1
+		case "sed":
+			write = inPlace
+		}
+
+		if write {
+			if err := api.CheckWrite(ctx, operand); err != nil {
+				return err
+			}
+		} else if err := api.CheckRead(operand); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,43 @@
+package snapshots
+
+import (
+	"context"
+	"os"
+
+	snapshot_models "agent-dev-environment/src/api/v1/snapshots"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/snapshot"
+)
+
+func CreateHandler(ctx context.Context, req snapshot_models.CreateRequest) (*snapshot_models.Snapshot, error) {
+	info, err := os.Stat(req.Path)
+	if os.IsNotExist(err) {
+		return nil, api.NewError(api.NotFound, "Path does not exist")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, api.NewError(api.BadRequest, "Path must be a directory")
+	}
+
+	m, err := snapshot.Create(req.Path, req.Label, api.RequestID(ctx), req.IncludeIgnored)
+	if err != nil {
+		return nil, api.NewError(api.InternalServerError, "Failed to create snapshot: "+err.Error())
+	}
+	res := summary(m)
+	return &res, nil
+}
+
+func summary(m *snapshot.Manifest) snapshot_models.Snapshot {
+	return snapshot_models.Snapshot{
+		ID:        m.ID,
+		Root:      m.Root,
+		Label:     m.Label,
+		RequestID: m.RequestID,
+		CreatedAt: m.CreatedAt,
+		Files:     len(m.Files),
+		Bytes:     m.Bytes,
+		NewBytes:  m.NewBytes,
+	}
+}
@@ -0,0 +1,21 @@
+package snapshots
+
+import (
+	"context"
+	"errors"
+
+	"agent-dev-environment/src/api/v1"
+	snapshot_models "agent-dev-environment/src/api/v1/snapshots"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/snapshot"
+)
+
+func DeleteHandler(ctx context.Context, req snapshot_models.DeleteRequest) (*v1.EmptyResponse, error) {
+	if err := snapshot.Delete(req.ID); err != nil {
+		if errors.Is(err, snapshot.ErrNotFound) {
+			return nil, api.NewError(api.NotFound, "Snapshot not found")
+		}
+		return nil, api.NewError(api.InternalServerError, "Failed to delete snapshot: "+err.Error())
+	}
+	return &v1.EmptyResponse{}, nil
+}
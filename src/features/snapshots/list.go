@@ -0,0 +1,21 @@
+package snapshots
+
+import (
+	"context"
+
+	snapshot_models "agent-dev-environment/src/api/v1/snapshots"
+	"agent-dev-environment/src/library/snapshot"
+)
+
+func ListHandler(ctx context.Context, req snapshot_models.ListRequest) (*snapshot_models.ListResponse, error) {
+	manifests, err := snapshot.List(req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &snapshot_models.ListResponse{Snapshots: make([]snapshot_models.Snapshot, 0, len(manifests))}
+	for i := range manifests {
+		res.Snapshots = append(res.Snapshots, summary(&manifests[i]))
+	}
+	return res, nil
+}
@@ -0,0 +1,63 @@
+package snapshots
+
+import (
+	"context"
+	"errors"
+
+	snapshot_models "agent-dev-environment/src/api/v1/snapshots"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/journal"
+	"agent-dev-environment/src/library/snapshot"
+	"agent-dev-environment/src/library/trash"
+)
+
+func RestoreHandler(ctx context.Context, req snapshot_models.RestoreRequest) (*snapshot_models.RestoreResponse, error) {
+	opts := snapshot.RestoreOptions{
+		Paths: req.Paths,
+		// Restored files replace what is there, symlinks included
+		Allow: func(path string) error {
+			if err := api.ConfineLink(path); err != nil {
+				return err
+			}
+			return api.CheckWrite(ctx, path)
+		},
+		Reserve: api.ReserveAll,
+	}
+	// Whatever the restore replaces is kept so it can be undone
+	var changes []journal.Change
+	opts.Change = func(path string) {
+		changes = append(changes, journal.Change{Path: path, Before: journal.SavedStat(path)})
+	}
+	opts.Trashed = func(path string, entry trash.Entry) {
+		changes = append(changes, journal.Change{Path: path, Before: journal.Trashed(entry.IsDir, entry.Size, entry.ID)})
+	}
+
+	result, err := snapshot.Restore(req.ID, api.RequestID(ctx), opts)
+	journal.Record(journal.SnapshotRestore, api.RequestID(ctx), journal.Completed(changes)...)
+	if err != nil {
+		var appErr *api.AppError
+		switch {
+		case errors.As(err, &appErr):
+			return nil, err
+		case errors.Is(err, snapshot.ErrNotFound):
+			return nil, api.NewError(api.NotFound, "Snapshot not found")
+		case errors.Is(err, snapshot.ErrOutsideRoot):
+			return nil, api.NewError(api.BadRequest, "Paths must be inside the snapshot root")
+		}
+		return nil, api.NewError(api.InternalServerError, "Failed to restore snapshot: "+err.Error())
+	}
+	for _, path := range result.Deleted {
+		events.Publish(events.Remove, path)
+	}
+	for _, path := range result.Restored {
+		events.Publish(events.Write, path)
+	}
+
+	return &snapshot_models.RestoreResponse{
+		BackupID:  result.BackupID,
+		Restored:  len(result.Restored),
+		Deleted:   len(result.Deleted),
+		Unchanged: result.Unchanged,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	search_models "agent-dev-environment/src/api/v1/symbols/search"
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/symbols"
+)
+
+func Handler(ctx context.Context, req search_models.Request) (*search_models.Response, error) {
+	absPath, err := filepath.Abs(req.Path)
+	if err != nil {
+		return nil, api.NewError(api.BadRequest, "Invalid path: "+err.Error())
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, api.NewError(api.NotFound, "Path not found")
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, api.NewError(api.BadRequest, "Path must be a directory")
+	}
+
+	query := symbols.Query{
+		Name:       req.Query,
+		Mode:       symbols.MatchExact,
+		PathPrefix: absPath,
+		Limit:      search_models.DefaultLimit,
+	}
+	if req.Match != "" {
+		query.Mode = symbols.MatchMode(req.Match)
+	}
+	if req.Limit != nil {
+		query.Limit = *req.Limit
+	}
+	for _, kind := range req.Kinds {
+		query.Kinds = append(query.Kinds, symbols.Kind(kind))
+	}
+
+	found, total := symbols.For(absPath).Search(query)
+
+	result := make([]search_models.Symbol, len(found))
+	for i, symbol := range found {
+		result[i] = search_models.Symbol{
+			Name:      symbol.Name,
+			Kind:      string(symbol.Kind),
+			Container: symbol.Container,
+			Path:      symbol.Path,
+			StartLine: symbol.StartLine,
+			EndLine:   symbol.EndLine,
+		}
+	}
+
+	return &search_models.Response{Symbols: result, Total: total}, nil
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"net/http"
+
+	"agent-dev-environment/src/library/api"
+)
+
+// ApprovedRules makes the protected-path rules approved in the
+// X-Approved-Rules header available to handlers.
+func ApprovedRules(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(api.WithApprovedRules(r.Context(), r.Header.Get(api.ApprovedRulesHeader))))
+	})
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	"agent-dev-environment/src/library/api"
+	"agent-dev-environment/src/library/session"
+)
+
+// Session makes the session named in the X-Session-ID header available to
+// handlers, so relative paths resolve against its working directory. Any
+// request keeps its session from being forgotten as idle.
+func Session(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session.Touch(r.Header.Get(api.SessionIDHeader))
+		next.ServeHTTP(w, r.WithContext(api.WithSession(r.Context(), r.Header.Get(api.SessionIDHeader))))
+	})
+}
@@ -1,22 +1,32 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"path/filepath"
 
 	"agent-dev-environment/src/api/v1"
 	"agent-dev-environment/src/library/logger"
+	"agent-dev-environment/src/library/trash"
+	"agent-dev-environment/src/library/workspace"
 )
 
 // Status Codes as constants for clarity
 const (
-	OK                  = http.StatusOK
-	Created             = http.StatusCreated
-	BadRequest          = http.StatusBadRequest
-	NotFound            = http.StatusNotFound
-	Conflict            = http.StatusConflict
-	InternalServerError = http.StatusInternalServerError
+	OK                   = http.StatusOK
+	Created              = http.StatusCreated
+	BadRequest           = http.StatusBadRequest
+	Forbidden            = http.StatusForbidden
+	NotFound             = http.StatusNotFound
+	Conflict             = http.StatusConflict
+	Gone                 = http.StatusGone
+	PreconditionRequired = http.StatusPreconditionRequired
+	ContentTooLarge      = http.StatusRequestEntityTooLarge
+	InternalServerError  = http.StatusInternalServerError
+	InsufficientStorage  = http.StatusInsufficientStorage
 )
 
 // AppError is a custom error type that carries an HTTP status code
@@ -38,8 +48,24 @@ type Validator interface {
 	Validate() error
 }
 
-// HandlerFunc is our "Clean Handler" signature
-type HandlerFunc[Req any, Res any] func(req Req) (*Res, error)
+// PathArgs is implemented by requests that name filesystem paths, so they
+// can be made absolute against the session's working directory and confined
+// to the workspace before reaching the handler
+type PathArgs interface {
+	Paths() []*string
+}
+
+// LinkPathArgs is implemented by requests that act on the paths they name
+// rather than on what those lead to, such as inspecting or deleting a
+// symlink. Only the directory holding each path is confined, so a link
+// pointing outside the workspace can still be handled.
+type LinkPathArgs interface {
+	LinkPaths() []*string
+}
+
+// HandlerFunc is our "Clean Handler" signature. The context carries
+// request-scoped values such as the request ID.
+type HandlerFunc[Req any, Res any] func(ctx context.Context, req Req) (*Res, error)
 
 // WrappedHandler converts a Clean Handler into a standard http.HandlerFunc
 func WrappedHandler[Req any, Res any](hf HandlerFunc[Req, Res]) http.HandlerFunc {
@@ -53,15 +79,12 @@ func WrappedHandler[Req any, Res any](hf HandlerFunc[Req, Res]) http.HandlerFunc
 			}
 		}
 
-		// Automatic validation if the request implements Validator
-		if v, ok := any(req).(Validator); ok {
-			if err := v.Validate(); err != nil {
-				handleError(w, err)
-				return
-			}
+		if err := prepare(r.Context(), &req); err != nil {
+			handleError(w, err)
+			return
 		}
 
-		res, err := hf(req)
+		res, err := hf(r.Context(), req)
 		if err != nil {
 			handleError(w, err)
 			return
@@ -71,16 +94,103 @@ func WrappedHandler[Req any, Res any](hf HandlerFunc[Req, Res]) http.HandlerFunc
 	}
 }
 
+// Invoke adapts a Clean Handler to take its request as raw JSON, running the
+// same validation and path checks as WrappedHandler. It lets one endpoint
+// dispatch to the handlers of others.
+func Invoke[Req any, Res any](hf HandlerFunc[Req, Res]) func(ctx context.Context, body json.RawMessage) (any, error) {
+	return func(ctx context.Context, body json.RawMessage) (any, error) {
+		var req Req
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, &req); err != nil {
+				return nil, NewError(BadRequest, "Invalid request body")
+			}
+		}
+		if err := prepare(ctx, &req); err != nil {
+			return nil, err
+		}
+		return hf(ctx, req)
+	}
+}
+
+// prepare validates a decoded request and resolves and checks its paths
+func prepare[Req any](ctx context.Context, req *Req) error {
+	// Automatic validation if the request implements Validator
+	if v, ok := any(*req).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Automatic resolution, confinement and deny rules if the request names paths
+	if p, ok := any(req).(PathArgs); ok {
+		if err := checkPaths(ctx, p.Paths(), Confine); err != nil {
+			return err
+		}
+	}
+	if p, ok := any(req).(LinkPathArgs); ok {
+		return checkPaths(ctx, p.LinkPaths(), ConfineLink)
+	}
+	return nil
+}
+
+func checkPaths(ctx context.Context, paths []*string, confine func(string) error) error {
+	for _, p := range paths {
+		if *p == "" {
+			continue
+		}
+		*p = Abs(ctx, *p)
+		path := *p
+		if err := confine(path); err != nil {
+			return err
+		}
+		if err := CheckRead(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Confine rejects absolute paths outside the workspace or inside the trash,
+// for paths handlers work out themselves rather than take from the request.
+func Confine(path string) error {
+	if err := workspace.Check(path); err != nil {
+		return NewError(Forbidden, fmt.Sprintf("Path %q is outside the workspace", path))
+	}
+	if resolved, err := workspace.Resolve(path); trash.Within(path) || (err == nil && trash.Within(resolved)) {
+		return NewError(Forbidden, fmt.Sprintf("Path %q is inside the trash; use filesystem/trash to restore it", path))
+	}
+	return nil
+}
+
+// ConfineLink is Confine for paths acted on themselves: a symlink may lead
+// outside the workspace, but the directory holding it may not, and the path
+// may not be inside the trash.
+func ConfineLink(path string) error {
+	if err := workspace.CheckLink(path); err != nil {
+		return NewError(Forbidden, fmt.Sprintf("Path %q is outside the workspace", path))
+	}
+	if dir, err := workspace.Resolve(filepath.Dir(path)); trash.Within(path) || (err == nil && trash.Within(filepath.Join(dir, filepath.Base(path)))) {
+		return NewError(Forbidden, fmt.Sprintf("Path %q is inside the trash; use filesystem/trash to restore it", path))
+	}
+	return nil
+}
+
 func handleError(w http.ResponseWriter, err error) {
+	code, message := ErrorResponse(err)
+	respondError(w, message, code)
+}
+
+// ErrorResponse returns the status code and message an error is reported
+// with. Errors other than AppError are logged and reported as internal.
+func ErrorResponse(err error) (int, string) {
 	var fErr *AppError
 	if errors.As(err, &fErr) {
-		respondError(w, fErr.Message, fErr.Code)
-		return
+		return fErr.Code, fErr.Message
 	}
 
 	// Fallback for unknown errors
 	logger.Error("Unexpected error", "error", err)
-	respondError(w, "Internal server error", InternalServerError)
+	return InternalServerError, "Internal server error"
 }
 
 func respond(w http.ResponseWriter, data any) {
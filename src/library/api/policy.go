@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"agent-dev-environment/src/library/policy"
+)
+
+// ApprovedRulesHeader lists, comma-separated, the patterns of
+// require-approval rules a human has approved for this request.
+const ApprovedRulesHeader = "X-Approved-Rules"
+
+type approvedRulesKey struct{}
+
+// WithApprovedRules returns a copy of ctx carrying the approved rule patterns.
+func WithApprovedRules(ctx context.Context, header string) context.Context {
+	var patterns []string
+	for _, pattern := range strings.Split(header, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return context.WithValue(ctx, approvedRulesKey{}, patterns)
+}
+
+// ApprovedRules returns the rule patterns approved for the request ctx belongs to.
+func ApprovedRules(ctx context.Context) []string {
+	patterns, _ := ctx.Value(approvedRulesKey{}).([]string)
+	return patterns
+}
+
+// CheckRead rejects any access to path if a deny rule matches it.
+func CheckRead(path string) error {
+	return policyError(policy.CheckRead(path))
+}
+
+// CheckWrite rejects changing path if a protected-path rule forbids it.
+func CheckWrite(ctx context.Context, path string) error {
+	return policyError(policy.CheckWrite(path, ApprovedRules(ctx)))
+}
+
+// CheckTree rejects changing path or anything below it if a protected-path
+// rule forbids it.
+func CheckTree(ctx context.Context, path string) error {
+	return policyError(policy.CheckTree(path, ApprovedRules(ctx)))
+}
+
+// CheckMirror rejects copying or moving src to dst if a protected-path rule
+// forbids writing dst or anything the copy would create below it.
+func CheckMirror(ctx context.Context, src, dst string) error {
+	return policyError(policy.CheckMirror(src, dst, ApprovedRules(ctx)))
+}
+
+func policyError(err error) error {
+	var violation *policy.Violation
+	if !errors.As(err, &violation) {
+		return err
+	}
+	if violation.Rule.Action == policy.RequireApproval {
+		return NewError(PreconditionRequired, fmt.Sprintf("Path %q requires approval by rule %q; retry with the %s header", violation.Path, violation.Rule.Pattern, ApprovedRulesHeader))
+	}
+	return NewError(Forbidden, fmt.Sprintf("Path %q is protected by rule %q (%s)", violation.Path, violation.Rule.Pattern, violation.Rule.Action))
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"agent-dev-environment/src/library/quota"
+)
+
+// Reserve rejects writing a file of size bytes at path if it would exceed
+// a disk quota, and otherwise counts it against the quota.
+func Reserve(path string, size int64) error {
+	return QuotaError(quota.Reserve(path, size))
+}
+
+// ReserveAll is Reserve for files written together, keyed by path.
+func ReserveAll(files map[string]int64) error {
+	return QuotaError(quota.ReserveAll(files))
+}
+
+// QuotaError turns a quota.ExceededError into a 507 Insufficient Storage
+// response. Other errors are returned as is.
+func QuotaError(err error) error {
+	var exceeded *quota.ExceededError
+	if !errors.As(err, &exceeded) {
+		return err
+	}
+	switch exceeded.Limit {
+	case quota.FileBytes:
+		return NewError(InsufficientStorage, fmt.Sprintf("File %q exceeds the limit of %d bytes per file", exceeded.Path, exceeded.Max))
+	case quota.Files:
+		return NewError(InsufficientStorage, fmt.Sprintf("Writing %q would exceed the workspace limit of %d files", exceeded.Path, exceeded.Max))
+	}
+	return NewError(InsufficientStorage, fmt.Sprintf("Writing %q would exceed the workspace limit of %d bytes", exceeded.Path, exceeded.Max))
+}
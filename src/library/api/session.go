@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+
+	"agent-dev-environment/src/library/session"
+)
+
+// SessionIDHeader names the session a request belongs to. Each session has
+// its own working directory; requests without it share the default one.
+const SessionIDHeader = "X-Session-ID"
+
+type sessionKey struct{}
+
+// WithSession returns a copy of ctx carrying the session ID.
+func WithSession(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, id)
+}
+
+// Session returns the ID of the session the request ctx belongs to.
+func Session(ctx context.Context) string {
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
+}
+
+// Cwd returns the working directory of the request's session.
+func Cwd(ctx context.Context) string {
+	return session.Cwd(Session(ctx))
+}
+
+// Abs resolves path against the working directory of the request's session.
+func Abs(ctx context.Context, path string) string {
+	return session.Abs(Session(ctx), path)
+}
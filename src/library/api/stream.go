@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"agent-dev-environment/src/library/logger"
+)
+
+// StreamHandlerFunc handles requests whose body or response is raw data,
+// such as archives, rather than JSON. The request parameters come from the
+// query string, and the handler writes the response itself.
+type StreamHandlerFunc[Req any] func(ctx context.Context, req Req, w http.ResponseWriter, r *http.Request) error
+
+// WrappedStreamHandler decodes Req from the query string and runs the same
+// validation and path checks as WrappedHandler. Errors returned before the
+// handler writes anything are reported as JSON like any other endpoint's.
+func WrappedStreamHandler[Req any](hf StreamHandlerFunc[Req]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		if err := decodeQuery(r.URL.Query(), &req); err != nil {
+			handleError(w, err)
+			return
+		}
+		if err := prepare(r.Context(), &req); err != nil {
+			handleError(w, err)
+			return
+		}
+
+		tw := &trackingWriter{ResponseWriter: w}
+		if err := hf(r.Context(), req, tw, r); err != nil {
+			if !tw.written {
+				handleError(w, err)
+				return
+			}
+			// Too late to report it; the client sees a truncated response
+			logger.Error("Stream failed", "path", r.URL.Path, "error", err)
+		}
+	}
+}
+
+// Respond writes data as a JSON response, for stream handlers that answer
+// with JSON once they have consumed the request body.
+func Respond(w http.ResponseWriter, data any) {
+	respond(w, data)
+}
+
+type trackingWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *trackingWriter) WriteHeader(code int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decodeQuery sets the string, bool, integer and string slice fields of the
+// struct req points to from the query parameters named by their json tags.
+func decodeQuery(query url.Values, req any) error {
+	v := reflect.ValueOf(req).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		values, ok := query[name]
+		if name == "" || name == "-" || !ok || len(values) == 0 {
+			continue
+		}
+
+		f := v.Field(i)
+		invalid := NewError(BadRequest, fmt.Sprintf("Invalid query parameter %q", name))
+		switch f.Kind() {
+		case reflect.String:
+			f.SetString(values[0])
+		case reflect.Bool:
+			b, err := strconv.ParseBool(values[0])
+			if err != nil {
+				return invalid
+			}
+			f.SetBool(b)
+		case reflect.Int, reflect.Int64:
+			n, err := strconv.ParseInt(values[0], 10, 64)
+			if err != nil {
+				return invalid
+			}
+			f.SetInt(n)
+		case reflect.Slice:
+			if f.Type().Elem().Kind() != reflect.String {
+				return invalid
+			}
+			f.Set(reflect.ValueOf(values))
+		default:
+			return invalid
+		}
+	}
+	return nil
+}
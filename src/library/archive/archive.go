@@ -0,0 +1,48 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	TarGz = "tar.gz"
+	Zip   = "zip"
+)
+
+// Formats are the archive formats Write and Extract support.
+var Formats = []string{TarGz, Zip}
+
+// MaxBytes bounds both an uploaded archive and the content extracted from it.
+var MaxBytes int64
+
+// spoolDir holds uploads that need random access before they are unpacked.
+var spoolDir string
+
+// Stats counts the entries written to or extracted from an archive.
+type Stats struct {
+	Files    int
+	Dirs     int
+	Symlinks int
+	Bytes    int64 // Size of the files' content
+}
+
+// Init sets the size limit for imports, in bytes, and spools uploads under
+// dataDir rather than in the workspace they are imported into.
+func Init(dataDir, maxBytes string) {
+	var err error
+	MaxBytes, err = strconv.ParseInt(maxBytes, 10, 64)
+	if err != nil || MaxBytes < 1 {
+		panic(fmt.Sprintf("invalid ARCHIVE_MAX_BYTES: %q. Must be a positive number of bytes", maxBytes))
+	}
+
+	spoolDir, err = filepath.Abs(filepath.Join(dataDir, "archive"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid DATA_DIR: %v", err))
+	}
+	if err := os.MkdirAll(spoolDir, 0o700); err != nil {
+		panic(fmt.Sprintf("could not create archive spool directory: %v", err))
+	}
+}
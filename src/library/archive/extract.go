@@ -0,0 +1,437 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"agent-dev-environment/src/library/workspace"
+)
+
+// ErrTooLarge is returned when the extracted content would exceed MaxBytes.
+var ErrTooLarge = errors.New("archive content exceeds the size limit")
+
+// UnsafeError reports an entry that would be written, or would point,
+// outside the target directory.
+type UnsafeError struct {
+	Name string
+}
+
+func (e *UnsafeError) Error() string {
+	return fmt.Sprintf("entry %q leads outside the target directory", e.Name)
+}
+
+// ConflictError reports an entry whose path already exists.
+type ConflictError struct {
+	Path string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s already exists", e.Path)
+}
+
+// ExtractOptions controls Extract.
+type ExtractOptions struct {
+	// Overwrite replaces existing files. Directories are always merged, but
+	// a file never replaces a directory or the other way around.
+	Overwrite bool
+	// Allow is asked about every path before any is written, and can veto it.
+	Allow func(path string) error
+	// Reserve is called once every path is allowed, with the size of each
+	// regular file about to be written keyed by its path, and can veto them.
+	Reserve func(files map[string]int64) error
+	// Change is called with each path in dest before it is created or
+	// replaced, parents first. Directories merged into are not reported.
+	Change func(path string)
+}
+
+// Extract unpacks the archive read from r into dest. Entries are unpacked
+// into a staging directory first, so an archive that turns out to be unsafe,
+// too large or conflicting leaves dest untouched. It returns the paths
+// written, in archive order.
+func Extract(r io.Reader, format, dest string, opts ExtractOptions) (Stats, []string, error) {
+	created := createdDirs(dest)
+	opts.change(created...)
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return Stats{}, nil, err
+	}
+	staging, err := os.MkdirTemp(dest, ".import-*")
+	if err != nil {
+		return Stats{}, nil, err
+	}
+	defer func() {
+		os.RemoveAll(staging)
+		// Directories made for the import are only removed if it left them empty
+		for _, dir := range created {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}()
+
+	x := &extractor{dest: dest, staging: staging}
+	// Compared with resolved paths, so symlinks above dest must not differ
+	if resolved, err := workspace.Resolve(staging); err == nil {
+		x.staging = resolved
+	}
+	switch format {
+	case TarGz:
+		err = x.tar(r)
+	case Zip:
+		err = x.zip(r)
+	default:
+		err = fmt.Errorf("unsupported archive format %q", format)
+	}
+	if err == nil {
+		err = x.checkLinks()
+	}
+	if err != nil {
+		return x.stats, nil, err
+	}
+
+	written, err := x.merge(opts)
+	return x.stats, written, err
+}
+
+type extractor struct {
+	dest    string
+	staging string
+	names   []string // Staged entries, in archive order
+	links   []string // Staged symlinks
+	stats   Stats
+}
+
+func (x *extractor) tar(r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = x.dir(hdr.Name, hdr.FileInfo().Mode())
+		case tar.TypeReg:
+			err = x.file(hdr.Name, hdr.FileInfo().Mode(), tr)
+		case tar.TypeSymlink:
+			err = x.symlink(hdr.Name, hdr.Linkname)
+		case tar.TypeLink:
+			err = x.hardlink(hdr.Name, hdr.Linkname)
+		}
+		// Devices, pipes and other special files are skipped
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// zip needs random access, so the upload is spooled to disk first.
+func (x *extractor) zip(r io.Reader) error {
+	spool, err := os.CreateTemp(spoolDir, "upload-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	size, err := io.Copy(spool, r)
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(spool, size)
+	if err != nil {
+		return err
+	}
+	os.Remove(spool.Name())
+
+	for _, f := range zr.File {
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			err = x.dir(f.Name, mode)
+		case mode&fs.ModeSymlink != 0:
+			err = x.zipSymlink(f)
+		case mode.IsRegular():
+			err = x.zipFile(f)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *extractor) zipFile(f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return x.file(f.Name, f.Mode(), rc)
+}
+
+func (x *extractor) zipSymlink(f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	target, err := io.ReadAll(io.LimitReader(rc, 4096))
+	if err != nil {
+		return err
+	}
+	return x.symlink(f.Name, string(target))
+}
+
+// stage returns where the entry called name is staged, after making sure it
+// stays inside the staging directory and clearing anything an earlier entry
+// of the same name left there.
+func (x *extractor) stage(name string, isDir bool) (string, string, error) {
+	rel := path.Clean(strings.TrimPrefix(filepath.ToSlash(name), "./"))
+	if rel == "." {
+		return "", "", nil
+	}
+	if !filepath.IsLocal(filepath.FromSlash(rel)) {
+		return "", "", &UnsafeError{Name: name}
+	}
+	target := filepath.Join(x.staging, filepath.FromSlash(rel))
+
+	// A symlink staged earlier must not redirect this entry
+	parent, err := workspace.Resolve(filepath.Dir(target))
+	if err != nil || !within(parent, x.staging) {
+		return "", "", &UnsafeError{Name: name}
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", "", err
+	}
+
+	if info, err := os.Lstat(target); err == nil {
+		if isDir && info.IsDir() {
+			return rel, target, nil
+		}
+		if err := os.RemoveAll(target); err != nil {
+			return "", "", err
+		}
+	} else {
+		x.names = append(x.names, rel)
+	}
+	return rel, target, nil
+}
+
+func (x *extractor) dir(name string, mode fs.FileMode) error {
+	rel, target, err := x.stage(name, true)
+	if err != nil || rel == "" {
+		return err
+	}
+	if err := os.Mkdir(target, mode.Perm()|0o700); err != nil && !os.IsExist(err) {
+		return err
+	}
+	x.stats.Dirs++
+	return nil
+}
+
+func (x *extractor) file(name string, mode fs.FileMode, content io.Reader) error {
+	rel, target, err := x.stage(name, false)
+	if err != nil || rel == "" {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode.Perm()|0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// One byte past the limit tells a file that reaches it from one that exceeds it
+	remaining := MaxBytes - x.stats.Bytes
+	n, err := io.Copy(f, io.LimitReader(content, remaining+1))
+	x.stats.Bytes += n
+	if err != nil {
+		return err
+	}
+	if x.stats.Bytes > MaxBytes {
+		return ErrTooLarge
+	}
+	x.stats.Files++
+	return nil
+}
+
+// symlink stages a link. Targets must be relative; they are checked once
+// every entry is staged, as later links can change where earlier ones lead.
+func (x *extractor) symlink(name, target string) error {
+	if target == "" || filepath.IsAbs(target) {
+		return &UnsafeError{Name: name}
+	}
+	rel, staged, err := x.stage(name, false)
+	if err != nil || rel == "" {
+		return err
+	}
+	if err := os.Symlink(target, staged); err != nil {
+		return err
+	}
+	x.links = append(x.links, rel)
+	x.stats.Symlinks++
+	return nil
+}
+
+// checkLinks makes sure every staged link leads somewhere inside the staging
+// directory, following links the way the kernel would. The staging
+// directory mirrors dest, so the links will stay inside dest once merged.
+func (x *extractor) checkLinks() error {
+	for _, rel := range x.links {
+		resolved, err := workspace.Resolve(filepath.Join(x.staging, filepath.FromSlash(rel)))
+		if err != nil || !within(resolved, x.staging) {
+			return &UnsafeError{Name: rel}
+		}
+	}
+	return nil
+}
+
+// hardlink stages another name for a file staged earlier in the archive.
+func (x *extractor) hardlink(name, linkname string) error {
+	source := path.Clean(strings.TrimPrefix(filepath.ToSlash(linkname), "./"))
+	if !filepath.IsLocal(filepath.FromSlash(source)) {
+		return &UnsafeError{Name: name}
+	}
+	sourcePath := filepath.Join(x.staging, filepath.FromSlash(source))
+	info, err := os.Lstat(sourcePath)
+	if err != nil || !info.Mode().IsRegular() {
+		return &UnsafeError{Name: name}
+	}
+
+	rel, staged, err := x.stage(name, false)
+	if err != nil || rel == "" {
+		return err
+	}
+	if err := os.Link(sourcePath, staged); err != nil {
+		return err
+	}
+	x.stats.Files++
+	return nil
+}
+
+// merge moves the staged entries into dest once every one of them has been
+// checked, so a conflict or a vetoed path leaves dest untouched.
+func (x *extractor) merge(opts ExtractOptions) ([]string, error) {
+	for _, rel := range x.names {
+		final := filepath.Join(x.dest, filepath.FromSlash(rel))
+		if opts.Allow != nil {
+			if err := opts.Allow(final); err != nil {
+				return nil, err
+			}
+		}
+		staged, err := os.Lstat(filepath.Join(x.staging, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		existing, err := os.Lstat(final)
+		if err != nil {
+			continue
+		}
+		if staged.IsDir() && existing.IsDir() {
+			continue
+		}
+		if !opts.Overwrite || staged.IsDir() || existing.IsDir() {
+			return nil, &ConflictError{Path: final}
+		}
+	}
+
+	if opts.Reserve != nil {
+		if err := opts.Reserve(x.sizes()); err != nil {
+			return nil, err
+		}
+	}
+
+	root, err := workspace.Resolve(x.dest)
+	if err != nil {
+		return nil, err
+	}
+	written := make([]string, 0, len(x.names))
+	for _, rel := range x.names {
+		staged := filepath.Join(x.staging, filepath.FromSlash(rel))
+		final := filepath.Join(x.dest, filepath.FromSlash(rel))
+
+		// Links merged earlier, or already in dest, must not redirect this entry
+		parent, err := workspace.Resolve(filepath.Dir(final))
+		if err != nil || !within(parent, root) {
+			return written, &UnsafeError{Name: rel}
+		}
+
+		info, err := os.Lstat(staged)
+		if err != nil {
+			return written, err
+		}
+		opts.change(createdDirs(filepath.Dir(final))...)
+		if err := os.MkdirAll(filepath.Dir(final), 0o755); err != nil {
+			return written, err
+		}
+		if _, err := os.Lstat(final); err != nil || !info.IsDir() {
+			opts.change(final)
+		}
+		if info.IsDir() {
+			if err := os.Mkdir(final, info.Mode().Perm()); err != nil && !os.IsExist(err) {
+				return written, err
+			}
+		} else if err := os.Rename(staged, final); err != nil {
+			return written, err
+		}
+		written = append(written, final)
+	}
+	return written, nil
+}
+
+// change reports paths to Change in reverse, so that of the directories
+// createdDirs lists deepest first, parents come first.
+func (opts ExtractOptions) change(paths ...string) {
+	if opts.Change == nil {
+		return
+	}
+	for i := len(paths) - 1; i >= 0; i-- {
+		opts.Change(paths[i])
+	}
+}
+
+// sizes returns the size of every staged regular file, keyed by the path
+// it will be moved to.
+func (x *extractor) sizes() map[string]int64 {
+	sizes := map[string]int64{}
+	for _, rel := range x.names {
+		info, err := os.Lstat(filepath.Join(x.staging, filepath.FromSlash(rel)))
+		if err == nil && info.Mode().IsRegular() {
+			sizes[filepath.Join(x.dest, filepath.FromSlash(rel))] = info.Size()
+		}
+	}
+	return sizes
+}
+
+// createdDirs returns the directories MkdirAll(dest) would create, deepest
+// first.
+func createdDirs(dest string) []string {
+	var dirs []string
+	for dir := dest; ; dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(dir); err == nil || dir == filepath.Dir(dir) {
+			return dirs
+		}
+		dirs = append(dirs, dir)
+	}
+}
+
+func within(p, dir string) bool {
+	return p == dir || strings.HasPrefix(p, dir+string(filepath.Separator))
+}
@@ -0,0 +1,195 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"agent-dev-environment/src/library/gitignore"
+)
+
+// Filter reports whether to include path. Excluding a directory excludes
+// everything below it.
+type Filter func(path string, isDir bool) bool
+
+// Write streams the contents of root to w as an archive in format, with
+// entry names relative to root. Symlinks are stored as links and never
+// followed. With excludeIgnored, files excluded by .gitignore and the .git
+// directory are left out.
+func Write(w io.Writer, root, format string, excludeIgnored bool, include Filter) (Stats, error) {
+	var aw entryWriter
+	switch format {
+	case TarGz:
+		aw = newTarWriter(w)
+	case Zip:
+		aw = &zipWriter{zw: zip.NewWriter(w)}
+	default:
+		return Stats{}, fmt.Errorf("unsupported archive format %q", format)
+	}
+
+	a := &archiver{w: aw, excludeIgnored: excludeIgnored, include: include}
+	info, err := os.Lstat(root)
+	if err != nil {
+		return a.stats, err
+	}
+	if info.IsDir() {
+		var matcher *gitignore.Matcher
+		if excludeIgnored {
+			matcher = gitignore.New(root)
+		}
+		err = a.dir(root, "", matcher)
+	} else {
+		err = a.entry(root, filepath.Base(root), info)
+	}
+	if err != nil {
+		return a.stats, err
+	}
+	return a.stats, aw.Close()
+}
+
+type archiver struct {
+	w              entryWriter
+	excludeIgnored bool
+	include        Filter
+	stats          Stats
+}
+
+// dir adds the entries of dir in name order, so archives of the same tree
+// are identical.
+func (a *archiver) dir(dir, rel string, matcher *gitignore.Matcher) error {
+	children, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		p := filepath.Join(dir, child.Name())
+		isDir := child.IsDir()
+		if a.excludeIgnored && ((isDir && child.Name() == ".git") || matcher.Ignored(p, isDir)) {
+			continue
+		}
+		if a.include != nil && !a.include(p, isDir) {
+			continue
+		}
+		info, err := child.Info()
+		if err != nil {
+			// Vanished since the directory was read
+			continue
+		}
+
+		name := path.Join(rel, child.Name())
+		if err := a.entry(p, name, info); err != nil {
+			return err
+		}
+		if isDir {
+			if err := a.dir(p, name, matcher.Descend(p)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a *archiver) entry(p, name string, info fs.FileInfo) error {
+	switch {
+	case info.IsDir():
+		a.stats.Dirs++
+		return a.w.Add(name+"/", info, "", nil)
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(p)
+		if err != nil {
+			return nil
+		}
+		a.stats.Symlinks++
+		return a.w.Add(name, info, target, nil)
+	case info.Mode().IsRegular():
+		f, err := os.Open(p)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+		a.stats.Files++
+		a.stats.Bytes += info.Size()
+		return a.w.Add(name, info, "", f)
+	}
+	// Devices, sockets and pipes have no portable representation
+	return nil
+}
+
+type entryWriter interface {
+	Add(name string, info fs.FileInfo, link string, content io.Reader) error
+	Close() error
+}
+
+type tarWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newTarWriter(w io.Writer) *tarWriter {
+	gz := gzip.NewWriter(w)
+	return &tarWriter{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+func (t *tarWriter) Add(name string, info fs.FileInfo, link string, content io.Reader) error {
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	// Owners mean nothing on the machine the archive is unpacked on
+	hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if content == nil {
+		return nil
+	}
+	// Only as much as the header announced, in case the file grew meanwhile
+	_, err = io.Copy(t.tw, io.LimitReader(content, hdr.Size))
+	return err
+}
+
+func (t *tarWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	return t.gz.Close()
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipWriter) Add(name string, info fs.FileInfo, link string, content io.Reader) error {
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if info.Mode().IsRegular() {
+		hdr.Method = zip.Deflate
+	}
+	w, err := z.zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	if link != "" {
+		// Zip stores a symlink's target as its content
+		_, err = io.WriteString(w, link)
+		return err
+	}
+	if content != nil {
+		_, err = io.Copy(w, content)
+	}
+	return err
+}
+
+func (z *zipWriter) Close() error {
+	return z.zw.Close()
+}
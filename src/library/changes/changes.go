@@ -0,0 +1,291 @@
+package changes
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-dev-environment/src/library/walk"
+)
+
+// racyWindow is how recently before a scan a file must have been modified
+// for its content to be hashed. A later write within the same timestamp
+// granularity would keep its size and modification time, so only the hash
+// can tell it apart, as with git's racily clean index entries.
+const racyWindow = 2 * time.Second
+
+// ErrNotFound is returned for cursors that do not exist or were pruned.
+var ErrNotFound = errors.New("cursor not found")
+
+// Fingerprint identifies the content of a regular file without keeping it.
+type Fingerprint struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Hash    string    `json:"hash,omitempty"` // Only for files modified just before the scan
+}
+
+// Cursor is the state of a directory at one point in time. Files are keyed
+// by slash-separated paths relative to Root.
+type Cursor struct {
+	ID             string                 `json:"id"`
+	Root           string                 `json:"root"`
+	IncludeIgnored bool                   `json:"include_ignored"`
+	CreatedAt      time.Time              `json:"created_at"`
+	Files          map[string]Fingerprint `json:"files"`
+}
+
+// Diff lists the files that changed between two cursors, as absolute
+// paths in lexical order.
+type Diff struct {
+	Created  []string
+	Modified []string
+	Deleted  []string
+}
+
+var (
+	mu       sync.Mutex
+	dir      string
+	dataDir  string
+	maxCount int
+)
+
+// Init sets where cursors are kept. Beyond count cursors for the same
+// directory, the oldest are deleted, so polling one directory never expires
+// the cursors of another.
+func Init(dataPath, count string) {
+	var err error
+	maxCount, err = strconv.Atoi(count)
+	if err != nil || maxCount < 1 {
+		panic(fmt.Sprintf("invalid CHANGES_MAX_CURSORS: %q. Must be a positive number", count))
+	}
+
+	dataDir, err = filepath.Abs(dataPath)
+	if err != nil {
+		panic(fmt.Sprintf("invalid DATA_DIR: %v", err))
+	}
+	dir = filepath.Join(dataDir, "changes")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		panic(fmt.Sprintf("could not create changes directory: %v", err))
+	}
+}
+
+// Start records the current state of root and returns a cursor for it.
+func Start(root string, includeIgnored bool) (*Cursor, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	cursor, err := scan(absRoot, includeIgnored, nil)
+	if err != nil {
+		return nil, err
+	}
+	return cursor, save(cursor)
+}
+
+// Since compares the state recorded by the cursor with the current one and
+// returns the changes along with a cursor for the current state. The given
+// cursor stays valid, so a request whose response was lost can be retried.
+func Since(id string) (*Cursor, *Diff, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	prev, err := load(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	cursor, err := scan(prev.Root, prev.IncludeIgnored, prev)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := save(cursor); err != nil {
+		return nil, nil, err
+	}
+	return cursor, diff(prev, cursor), nil
+}
+
+// Root returns the directory a cursor was taken for.
+func Root(id string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	cursor, err := load(id)
+	if err != nil {
+		return "", err
+	}
+	return cursor.Root, nil
+}
+
+// scan fingerprints every regular file below root. Files that were racy in
+// prev are hashed again so diff can compare them.
+func scan(root string, includeIgnored bool, prev *Cursor) (*Cursor, error) {
+	cursor := &Cursor{
+		ID:             newID(root),
+		Root:           root,
+		IncludeIgnored: includeIgnored,
+		CreatedAt:      time.Now().UTC(),
+		Files:          map[string]Fingerprint{},
+	}
+
+	var collectMu sync.Mutex
+	opts := walk.Options{RespectIgnore: !includeIgnored, Hidden: true}
+	err := walk.Walk(root, opts, func(path string, d fs.DirEntry) error {
+		if strings.HasPrefix(path, dataDir+string(filepath.Separator)) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		fp := Fingerprint{Size: info.Size(), ModTime: info.ModTime()}
+		racy := !fp.ModTime.Before(cursor.CreatedAt.Add(-racyWindow))
+		if prev != nil && prev.Files[rel].Hash != "" {
+			racy = true
+		}
+		if racy {
+			if fp.Hash, err = hashFile(path); err != nil {
+				// Files that vanish mid-scan are left out
+				return nil
+			}
+		}
+
+		collectMu.Lock()
+		cursor.Files[rel] = fp
+		collectMu.Unlock()
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		// The root itself is gone: everything below it was deleted
+		err = nil
+	}
+	return cursor, err
+}
+
+func diff(prev, cur *Cursor) *Diff {
+	d := &Diff{Created: []string{}, Modified: []string{}, Deleted: []string{}}
+	for rel, fp := range cur.Files {
+		old, ok := prev.Files[rel]
+		switch {
+		case !ok:
+			d.Created = append(d.Created, absolute(cur.Root, rel))
+		case old.Size != fp.Size || !old.ModTime.Equal(fp.ModTime) || (old.Hash != "" && old.Hash != fp.Hash):
+			d.Modified = append(d.Modified, absolute(cur.Root, rel))
+		}
+	}
+	for rel := range prev.Files {
+		if _, ok := cur.Files[rel]; !ok {
+			d.Deleted = append(d.Deleted, absolute(cur.Root, rel))
+		}
+	}
+	sort.Strings(d.Created)
+	sort.Strings(d.Modified)
+	sort.Strings(d.Deleted)
+	return d
+}
+
+func absolute(root, rel string) string {
+	return filepath.Join(root, filepath.FromSlash(rel))
+}
+
+// save writes the cursor and deletes the oldest ones for the same root
+// beyond maxCount. Callers hold mu.
+func save(cursor *Cursor) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	tmp := cursorPath(cursor.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, cursorPath(cursor.ID)); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	ids := []string{}
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), ".json"); ok && strings.HasPrefix(id, rootKey(cursor.Root)+"-") {
+			ids = append(ids, id)
+		}
+	}
+	// IDs sort by creation time
+	sort.Strings(ids)
+	for len(ids) > maxCount {
+		if err := os.Remove(cursorPath(ids[0])); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		ids = ids[1:]
+	}
+	return nil
+}
+
+func load(id string) (*Cursor, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return nil, ErrNotFound
+	}
+	data, err := os.ReadFile(cursorPath(id))
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrNotFound
+	}
+	return &cursor, nil
+}
+
+func cursorPath(id string) string {
+	return filepath.Join(dir, id+".json")
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// newID names the root the cursor is for, then sorts by creation time and
+// stays unique within the same instant.
+func newID(root string) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return rootKey(root) + "-" + time.Now().UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(b)
+}
+
+// rootKey identifies root in cursor IDs without revealing it.
+func rootKey(root string) string {
+	sum := sha256.Sum256([]byte(root))
+	return hex.EncodeToString(sum[:8])
+}
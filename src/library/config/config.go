@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
 const ENV_PREFIX = "AGENT_DEV_ENVIRONMENT_"
@@ -15,3 +16,20 @@ func GetValue(key string) string {
 
 	return value
 }
+
+// GetValueOrDefault returns the value of an optional environment variable,
+// falling back to the given default when it is not set.
+func GetValueOrDefault(key string, fallback string) string {
+	value, ok := os.LookupEnv(ENV_PREFIX + key)
+	if !ok || value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+// DataDir returns the directory where the server keeps its own state, such
+// as the trash.
+func DataDir() string {
+	return GetValueOrDefault("DATA_DIR", filepath.Join(os.TempDir(), "agent-dev-environment"))
+}
@@ -0,0 +1,127 @@
+package events
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+type Op string
+
+const (
+	Create Op = "create"
+	Write  Op = "write"
+	Remove Op = "remove"
+	Rename Op = "rename"
+)
+
+// Event describes a change made to the filesystem through the API.
+// Paths are absolute; OldPath is only set for renames.
+type Event struct {
+	Op      Op
+	Path    string
+	OldPath string
+}
+
+type Subscriber func(Event)
+
+var (
+	mu          sync.RWMutex
+	subscribers []Subscriber
+)
+
+// Subscribe registers fn to be called for every published event. Subscribers
+// run synchronously on the publishing request, so they must be quick; slower
+// ones use SubscribeAsync.
+func Subscribe(fn Subscriber) {
+	mu.Lock()
+	defer mu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+// Publish notifies subscribers that path changed. Relative paths are made
+// absolute against the current working directory.
+func Publish(op Op, path string) {
+	publish(Event{Op: op, Path: absolute(path)})
+}
+
+// PublishRename notifies subscribers that oldPath was moved to path.
+func PublishRename(oldPath, path string) {
+	publish(Event{Op: Rename, Path: absolute(path), OldPath: absolute(oldPath)})
+}
+
+// Async is a subscriber run on a goroutine of its own, for work too slow for
+// the publishing request, such as reindexing.
+type Async struct {
+	fn        Subscriber
+	mu        sync.Mutex
+	cond      *sync.Cond
+	queue     []Event
+	published uint64
+	handled   uint64
+}
+
+// SubscribeAsync registers fn to be called for every published event, in
+// order, but off the publishing request.
+func SubscribeAsync(fn Subscriber) *Async {
+	a := &Async{fn: fn}
+	a.cond = sync.NewCond(&a.mu)
+	go a.run()
+	Subscribe(a.enqueue)
+	return a
+}
+
+// Wait blocks until every event published before the call was handled, so
+// readers see the effect of changes that already returned.
+func (a *Async) Wait() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	target := a.published
+	for a.handled < target {
+		a.cond.Wait()
+	}
+}
+
+func (a *Async) enqueue(event Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.queue = append(a.queue, event)
+	a.published++
+	a.cond.Broadcast()
+}
+
+func (a *Async) run() {
+	for {
+		a.mu.Lock()
+		for len(a.queue) == 0 {
+			a.cond.Wait()
+		}
+		batch := a.queue
+		a.queue = nil
+		a.mu.Unlock()
+
+		for _, event := range batch {
+			a.fn(event)
+		}
+
+		a.mu.Lock()
+		a.handled += uint64(len(batch))
+		a.cond.Broadcast()
+		a.mu.Unlock()
+	}
+}
+
+func publish(event Event) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, fn := range subscribers {
+		fn(event)
+	}
+}
+
+func absolute(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package fsutil
+
+import (
+	"io/fs"
+	"time"
+)
+
+// accessTime falls back to the modification time where the platform's stat
+// layout is not known.
+func accessTime(info fs.FileInfo) time.Time {
+	return info.ModTime()
+}
@@ -0,0 +1,249 @@
+package fsutil
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"agent-dev-environment/src/library/workspace"
+)
+
+// maxSymlinkDepth bounds how many directory symlinks are followed in a row,
+// which also stops symlink loops.
+const maxSymlinkDepth = 40
+
+// CopyOptions controls Copy.
+type CopyOptions struct {
+	// Overwrite replaces existing files and merges into existing directories.
+	Overwrite bool
+	// PreserveMode copies permission bits exactly instead of applying the umask.
+	PreserveMode bool
+	// PreserveTimes copies modification and access times.
+	PreserveTimes bool
+	// FollowSymlinks copies what symlinks point to instead of the links themselves.
+	FollowSymlinks bool
+	// Exclude is called with each entry's slash-separated path relative to the
+	// source. Excluded directories are skipped entirely.
+	Exclude func(rel string, isDir bool) bool
+	// Reserve is called before each regular file is written, with its
+	// destination and size. An error stops the copy.
+	Reserve func(dst string, size int64) error
+	// Change is called before each file or symlink is written and before
+	// each directory is created, with its destination. Directories merged
+	// into are not reported.
+	Change func(dst string)
+}
+
+// CopyStats summarises a copy.
+type CopyStats struct {
+	Files    int
+	Bytes    int64
+	Symlinks int
+	Excluded int
+}
+
+// ErrCopyIntoSelf is returned when the destination lies inside the source directory.
+var ErrCopyIntoSelf = errors.New("cannot copy a directory into itself")
+
+// ErrCopyOntoSelf is returned when the destination is the source file itself,
+// under the same name or through a hard link.
+var ErrCopyOntoSelf = errors.New("cannot copy a file onto itself")
+
+// Copy copies the file, symlink or directory tree at src to dst. Without
+// Overwrite, an existing destination fails with an error satisfying
+// errors.Is(err, fs.ErrExist).
+func Copy(src, dst string, opts CopyOptions) (CopyStats, error) {
+	c := copier{opts: opts}
+
+	info, err := c.stat(src)
+	if err != nil {
+		return c.stats, err
+	}
+	if info.IsDir() {
+		// Compared once symlinks are resolved, so a destination reached
+		// through a link into the source is caught too
+		resolvedSrc, err := workspace.Resolve(src)
+		if err != nil {
+			return c.stats, err
+		}
+		resolvedDst, err := workspace.Resolve(dst)
+		if err != nil {
+			return c.stats, err
+		}
+		if resolvedDst == resolvedSrc || isBelow(resolvedDst, resolvedSrc) {
+			return c.stats, ErrCopyIntoSelf
+		}
+	}
+
+	err = c.copy(src, dst, "", info, 0)
+	return c.stats, err
+}
+
+type copier struct {
+	opts  CopyOptions
+	stats CopyStats
+}
+
+func (c *copier) stat(path string) (fs.FileInfo, error) {
+	if c.opts.FollowSymlinks {
+		return os.Stat(path)
+	}
+	return os.Lstat(path)
+}
+
+func (c *copier) copy(src, dst, rel string, info fs.FileInfo, depth int) error {
+	switch {
+	case info.IsDir():
+		return c.copyDir(src, dst, rel, info, depth)
+	case info.Mode()&fs.ModeSymlink != 0:
+		return c.copySymlink(src, dst)
+	case info.Mode().IsRegular():
+		return c.copyFile(src, dst, info)
+	}
+	// Sockets, devices and pipes are not copied
+	return nil
+}
+
+func (c *copier) copyDir(src, dst, rel string, info fs.FileInfo, depth int) error {
+	if depth > maxSymlinkDepth {
+		return &fs.PathError{Op: "copy", Path: src, Err: errors.New("too many levels of symbolic links")}
+	}
+
+	existing, err := os.Lstat(dst)
+	switch {
+	case err == nil && !existing.IsDir():
+		return &fs.PathError{Op: "copy", Path: dst, Err: fs.ErrExist}
+	case err == nil && !c.opts.Overwrite:
+		return &fs.PathError{Op: "copy", Path: dst, Err: fs.ErrExist}
+	case err != nil && !os.IsNotExist(err):
+		return err
+	case err != nil:
+		c.change(dst)
+		if err := workspace.Mkdir(dst, info.Mode().Perm()|0o700); err != nil {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		childSrc := filepath.Join(src, entry.Name())
+		childRel := entry.Name()
+		if rel != "" {
+			childRel = rel + "/" + entry.Name()
+		}
+
+		childInfo, err := c.stat(childSrc)
+		if err != nil {
+			return err
+		}
+		if c.opts.Exclude != nil && c.opts.Exclude(childRel, childInfo.IsDir()) {
+			c.stats.Excluded++
+			continue
+		}
+
+		childDepth := depth
+		if c.opts.FollowSymlinks && entry.Type()&fs.ModeSymlink != 0 {
+			childDepth++
+		}
+		if err := c.copy(childSrc, filepath.Join(dst, entry.Name()), childRel, childInfo, childDepth); err != nil {
+			return err
+		}
+	}
+
+	// Applied last so that restrictive modes do not block writing the contents
+	return c.finish(dst, info)
+}
+
+func (c *copier) copyFile(src, dst string, info fs.FileInfo) error {
+	// Truncating the destination would empty the source too
+	if existing, err := os.Lstat(dst); err == nil && os.SameFile(existing, info) {
+		return &fs.PathError{Op: "copy", Path: dst, Err: ErrCopyOntoSelf}
+	}
+	if c.opts.Reserve != nil {
+		if err := c.opts.Reserve(dst, info.Size()); err != nil {
+			return err
+		}
+	}
+	in, err := workspace.OpenFile(src, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	c.change(dst)
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !c.opts.Overwrite {
+		flags |= os.O_EXCL
+	} else if existing, err := os.Lstat(dst); err == nil && existing.Mode()&fs.ModeSymlink != 0 {
+		// Replace the link itself rather than writing through it
+		if err := workspace.Remove(dst); err != nil {
+			return err
+		}
+	}
+	out, err := workspace.OpenFile(dst, flags, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	c.stats.Files++
+	c.stats.Bytes += n
+	return c.finish(dst, info)
+}
+
+func (c *copier) copySymlink(src, dst string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	c.change(dst)
+	if c.opts.Overwrite {
+		if existing, err := os.Lstat(dst); err == nil && !existing.IsDir() {
+			if err := workspace.Remove(dst); err != nil {
+				return err
+			}
+		}
+	}
+	if err := workspace.Symlink(target, dst); err != nil {
+		return err
+	}
+	c.stats.Symlinks++
+	return nil
+}
+
+func (c *copier) change(dst string) {
+	if c.opts.Change != nil {
+		c.opts.Change(dst)
+	}
+}
+
+func (c *copier) finish(dst string, info fs.FileInfo) error {
+	if c.opts.PreserveMode {
+		if err := workspace.Chmod(dst, info.Mode()&(fs.ModePerm|fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky)); err != nil {
+			return err
+		}
+	}
+	if c.opts.PreserveTimes {
+		if err := workspace.Chtimes(dst, accessTime(info), info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isBelow(path, dir string) bool {
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
@@ -0,0 +1,35 @@
+package fsutil
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+)
+
+// BinarySniffLen is how much of a file is inspected for NUL bytes, matching git's heuristic.
+const BinarySniffLen = 8000
+
+// IsBinary reports whether content looks like binary data.
+func IsBinary(content []byte) bool {
+	sniff := content
+	if len(sniff) > BinarySniffLen {
+		sniff = sniff[:BinarySniffLen]
+	}
+	return bytes.IndexByte(sniff, 0) >= 0
+}
+
+// MissingDirs returns path and those of its parents that do not exist yet,
+// outermost first: the directories os.MkdirAll(path) would create.
+func MissingDirs(path string) []string {
+	var dirs []string
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(dir); err == nil {
+			break
+		}
+		dirs = append([]string{dir}, dirs...)
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	return dirs
+}
@@ -0,0 +1,54 @@
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"agent-dev-environment/src/library/workspace"
+)
+
+// Move renames src to dst, falling back to copy-then-delete when they are on
+// different filesystems, which it reports with crossDevice. Without
+// overwrite, an existing dst fails with an error satisfying
+// errors.Is(err, fs.ErrExist).
+func Move(src, dst string, overwrite bool) (crossDevice bool, err error) {
+	err = rename(src, dst, overwrite)
+	if !errors.Is(err, syscall.EXDEV) {
+		return false, err
+	}
+	return true, moveAcrossDevices(src, dst, overwrite)
+}
+
+func rename(src, dst string, overwrite bool) error {
+	if overwrite {
+		return workspace.Rename(src, dst)
+	}
+	return workspace.RenameNoReplace(src, dst)
+}
+
+// moveAcrossDevices copies src next to dst and renames it into place, so dst
+// never holds a partial copy. src is only removed once the copy is in place.
+func moveAcrossDevices(src, dst string, overwrite bool) error {
+	if !overwrite {
+		if _, err := os.Lstat(dst); err == nil {
+			return &os.LinkError{Op: "rename", Old: src, New: dst, Err: fs.ErrExist}
+		}
+	}
+
+	staging := filepath.Join(filepath.Dir(dst), fmt.Sprintf(".%s.move-%d", filepath.Base(dst), time.Now().UnixNano()))
+	_, err := Copy(src, staging, CopyOptions{PreserveMode: true, PreserveTimes: true})
+	if err == nil {
+		err = rename(staging, dst, overwrite)
+	}
+	if err != nil {
+		workspace.RemoveAll(staging)
+		return err
+	}
+
+	return workspace.RemoveAll(src)
+}
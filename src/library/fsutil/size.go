@@ -0,0 +1,22 @@
+package fsutil
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// DiskSize sums the sizes of the files below path, or of path itself if it
+// is a file, without following symlinks.
+func DiskSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
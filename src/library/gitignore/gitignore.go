@@ -0,0 +1,170 @@
+package gitignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"agent-dev-environment/src/library/glob"
+)
+
+// IgnoreFiles are read from every directory, in order of increasing precedence.
+var IgnoreFiles = []string{".gitignore", ".ignore"}
+
+type rule struct {
+	re      *regexp.Regexp
+	source  string
+	negate  bool
+	dirOnly bool
+}
+
+// Matcher holds the ignore rules of one directory and links to the rules of
+// its parent, mirroring how git resolves nested .gitignore files: deeper files
+// take precedence and, within a file, the last matching pattern wins.
+type Matcher struct {
+	parent *Matcher
+	dir    string
+	rules  []rule
+}
+
+// New returns a matcher for root. Ignore files of enclosing directories are
+// loaded up to the root of the git repository containing root, if any.
+func New(root string) *Matcher {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		abs = root
+	}
+	if info, err := os.Stat(abs); err == nil && !info.IsDir() {
+		abs = filepath.Dir(abs)
+	}
+
+	var chain []string
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		chain = append(chain, dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+		if dir == filepath.Dir(dir) {
+			// Not inside a repository: only the root's own files apply
+			chain = chain[:1]
+			break
+		}
+	}
+
+	var m *Matcher
+	for i := len(chain) - 1; i >= 0; i-- {
+		m = m.Descend(chain[i])
+		if i == len(chain)-1 {
+			m = m.withExcludeFile(filepath.Join(chain[i], ".git", "info", "exclude"))
+		}
+	}
+	return m
+}
+
+// Descend returns a matcher for the child directory dir, which includes the
+// rules of any ignore files found in it. It is safe to call on a nil Matcher.
+func (m *Matcher) Descend(dir string) *Matcher {
+	var rules []rule
+	for _, name := range IgnoreFiles {
+		rules = append(rules, readRules(filepath.Join(dir, name))...)
+	}
+	if len(rules) == 0 && m != nil {
+		return m
+	}
+	return &Matcher{parent: m, dir: dir, rules: rules}
+}
+
+func (m *Matcher) withExcludeFile(path string) *Matcher {
+	rules := readRules(path)
+	if len(rules) == 0 {
+		return m
+	}
+	return &Matcher{parent: m, dir: m.dir, rules: append(rules, m.rules...)}
+}
+
+// Ignored reports whether the absolute path is excluded by the loaded rules.
+func (m *Matcher) Ignored(path string, isDir bool) bool {
+	_, ignored := m.Match(path, isDir)
+	return ignored
+}
+
+// Match is like Ignored but also returns the pattern that decided the outcome.
+func (m *Matcher) Match(path string, isDir bool) (string, bool) {
+	for cur := m; cur != nil; cur = cur.parent {
+		rel, err := filepath.Rel(cur.dir, path)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+
+		for i := len(cur.rules) - 1; i >= 0; i-- {
+			r := cur.rules[i]
+			if r.dirOnly && !isDir {
+				continue
+			}
+			if r.re.MatchString(rel) {
+				return r.source, !r.negate
+			}
+		}
+	}
+	return "", false
+}
+
+func readRules(path string) []rule {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var rules []rule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if r, ok := parseLine(scanner.Text()); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+func parseLine(line string) (rule, bool) {
+	line = strings.TrimRight(line, "\r")
+	if !strings.HasSuffix(line, "\\ ") {
+		line = strings.TrimRight(line, " \t")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	r := rule{source: line}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+
+	// Patterns without an inner slash match at any depth below the ignore file
+	if strings.HasPrefix(line, "/") {
+		line = strings.TrimLeft(line, "/")
+	} else if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+
+	re, err := glob.Compile(line)
+	if err != nil {
+		return rule{}, false
+	}
+	r.re = re
+	return r, true
+}
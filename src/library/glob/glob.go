@@ -0,0 +1,173 @@
+package glob
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var cache sync.Map
+
+// Compile translates a glob pattern into an anchored regular expression.
+// Supported syntax: '*' (any run of non-separator characters), '**' (any
+// number of path segments), '?', character classes '[...]' and brace
+// alternatives '{a,b}'. Paths are always matched with '/' separators.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := cache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	expr, err := translate(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+
+	cache.Store(pattern, re)
+	return re, nil
+}
+
+// Match reports whether name matches the glob pattern. Invalid patterns never match.
+func Match(pattern, name string) bool {
+	re, err := Compile(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(name)
+}
+
+// MatchAny reports whether name matches at least one of the patterns.
+func MatchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath matches a slash-separated relative path the way ignore files do:
+// patterns containing a '/' are matched against the whole path, others
+// against the last path element only.
+func MatchPath(pattern, path string) bool {
+	if !strings.Contains(pattern, "/") {
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			path = path[i+1:]
+		}
+	}
+	return Match(strings.TrimPrefix(pattern, "/"), path)
+}
+
+// MatchAnyPath reports whether path matches at least one of the patterns using MatchPath.
+func MatchAnyPath(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if MatchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the pattern cannot be compiled.
+func Validate(pattern string) error {
+	_, err := Compile(pattern)
+	return err
+}
+
+func translate(pattern string) (string, error) {
+	var sb strings.Builder
+	runes := []rune(pattern)
+	braceDepth := 0
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch c {
+		case '*':
+			start := i
+			for i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+			}
+			if i == start {
+				sb.WriteString("[^/]*")
+				continue
+			}
+
+			// '**' only spans directories when it is a whole path segment
+			atSegmentStart := start == 0 || runes[start-1] == '/'
+			switch {
+			case atSegmentStart && i+1 < len(runes) && runes[i+1] == '/':
+				sb.WriteString("(?:.*/)?")
+				i++
+			case atSegmentStart && i+1 == len(runes):
+				sb.WriteString(".*")
+			default:
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := i + 1
+			if end < len(runes) && (runes[end] == '!' || runes[end] == '^') {
+				end++
+			}
+			if end < len(runes) && runes[end] == ']' {
+				end++
+			}
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end >= len(runes) {
+				sb.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+
+			class := runes[i+1 : end]
+			sb.WriteByte('[')
+			if len(class) > 0 && (class[0] == '!' || class[0] == '^') {
+				sb.WriteByte('^')
+				class = class[1:]
+			}
+			for _, r := range class {
+				if r == '\\' || r == '[' || r == ']' {
+					sb.WriteByte('\\')
+				}
+				sb.WriteRune(r)
+			}
+			sb.WriteByte(']')
+			i = end
+		case '{':
+			braceDepth++
+			sb.WriteString("(?:")
+		case '}':
+			if braceDepth == 0 {
+				sb.WriteString(regexp.QuoteMeta("}"))
+				continue
+			}
+			braceDepth--
+			sb.WriteByte(')')
+		case ',':
+			if braceDepth > 0 {
+				sb.WriteByte('|')
+			} else {
+				sb.WriteByte(',')
+			}
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+			}
+			sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if braceDepth != 0 {
+		return "", fmt.Errorf("invalid glob %q: unbalanced braces", pattern)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,295 @@
+package journal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-dev-environment/src/library/logger"
+)
+
+type Op string
+
+const (
+	CreateFile      Op = "create_file"
+	Replace         Op = "replace"
+	Move            Op = "move"
+	Delete          Op = "delete"
+	Mkdir           Op = "mkdir"
+	Symlink         Op = "symlink"
+	Hardlink        Op = "hardlink"
+	Upload          Op = "upload"
+	Restore         Op = "restore"
+	Copy            Op = "copy"
+	Codemod         Op = "codemod"
+	Import          Op = "import"
+	SnapshotRestore Op = "snapshot_restore"
+)
+
+// ErrNotFound is returned for journal entry IDs that do not exist.
+var ErrNotFound = errors.New("journal entry not found")
+
+// Change is what one operation did to one path. For moves, From is the
+// source and Path the destination. Tree marks a directory the operation put
+// in place along with everything in it, which undo moves to the trash whole.
+type Change struct {
+	Path   string `json:"path"`
+	From   string `json:"from,omitempty"`
+	Before State  `json:"before"`
+	After  State  `json:"after"`
+	Tree   bool   `json:"tree,omitempty"`
+}
+
+// Entry records one mutating request.
+type Entry struct {
+	ID         string     `json:"id"`
+	Op         Op         `json:"op"`
+	RequestID  string     `json:"request_id,omitempty"`
+	Time       time.Time  `json:"time"`
+	Changes    []Change   `json:"changes"`
+	Reversible bool       `json:"reversible"` // False when the previous state was not kept, as for permanent deletes
+	UndoneAt   *time.Time `json:"undone_at,omitempty"`
+}
+
+var (
+	mu         sync.Mutex
+	dir        string
+	maxEntries int
+	entries    []*Entry // Oldest first
+	pending    = map[string]int{}
+)
+
+// Init loads the journal kept under dataDir. Beyond count entries, the
+// oldest are forgotten.
+func Init(dataDir, count string) {
+	var err error
+	maxEntries, err = strconv.Atoi(count)
+	if err != nil || maxEntries < 1 {
+		panic(fmt.Sprintf("invalid JOURNAL_MAX_ENTRIES: %q. Must be a positive number", count))
+	}
+
+	dir, err = filepath.Abs(filepath.Join(dataDir, "journal"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid DATA_DIR: %v", err))
+	}
+	for _, sub := range []string{"blobs", "entries"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			panic(fmt.Sprintf("could not create journal directory: %v", err))
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	entries = loadEntries()
+	prune()
+}
+
+// Record adds an entry for a completed operation. Failing to record never
+// fails the operation itself, so errors are only logged.
+func Record(op Op, requestID string, changes ...Change) {
+	if dir == "" || len(changes) == 0 {
+		return
+	}
+
+	// Held while saving content so garbage collection cannot remove it
+	// before the entry referencing it is written
+	mu.Lock()
+	defer mu.Unlock()
+
+	entry := &Entry{ID: newID(), Op: op, RequestID: requestID, Time: time.Now().UTC(), Reversible: true}
+	for _, c := range changes {
+		c.Path = absolute(c.Path)
+		if c.From != "" {
+			c.From = absolute(c.From)
+		}
+		if err := c.Before.save(); err != nil {
+			logger.Error("Failed to save journal content", "path", c.Path, "error", err)
+			c.Before.Saved = false
+		}
+		entry.Reversible = entry.Reversible && c.Before.restorable()
+		entry.Changes = append(entry.Changes, c)
+	}
+
+	if err := writeEntry(entry); err != nil {
+		logger.Error("Failed to record journal entry", "op", op, "error", err)
+		return
+	}
+	entries = append(entries, entry)
+	prune()
+}
+
+// Completed fills in what each change left behind once the operation is
+// over, dropping paths it never got to write. A directory it created stands
+// for everything in it, which undo moves to the trash whole. Parents must
+// come before their contents.
+func Completed(changes []Change) []Change {
+	var result []Change
+	trees := map[string]bool{}
+	for _, c := range changes {
+		if trees[filepath.Dir(c.Path)] {
+			// Inside a directory the operation created, and so part of it
+			trees[c.Path] = true
+			continue
+		}
+		c.After = Stat(c.Path)
+		if !c.Before.Exists && !c.After.Exists {
+			continue
+		}
+		if !c.Before.Exists && c.After.IsDir {
+			c.Tree = true
+			trees[c.Path] = true
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// Dirs records the directories at paths as created by the operation, such
+// as those fsutil.MissingDirs reported before they were made.
+func Dirs(paths []string) []Change {
+	changes := make([]Change, 0, len(paths))
+	for _, path := range paths {
+		changes = append(changes, Change{Path: path, After: Stat(path)})
+	}
+	return changes
+}
+
+// List returns up to limit entries, newest first. A non-empty path restricts
+// the result to entries that touched it, something below it or a directory
+// containing it.
+func List(path string, limit int) []Entry {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if path != "" {
+		path = absolute(path)
+	}
+	result := []Entry{}
+	for i := len(entries) - 1; i >= 0 && len(result) < limit; i-- {
+		if path == "" || touches(entries[i], path) {
+			result = append(result, *entries[i])
+		}
+	}
+	return result
+}
+
+func touches(entry *Entry, path string) bool {
+	for _, c := range entry.Changes {
+		if related(c.Path, path) || (c.From != "" && related(c.From, path)) {
+			return true
+		}
+	}
+	return false
+}
+
+func related(a, b string) bool {
+	return within(a, b) || within(b, a)
+}
+
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// prune forgets the oldest entries beyond maxEntries and the content only
+// they referenced. Callers hold mu.
+func prune() {
+	if len(entries) <= maxEntries {
+		return
+	}
+	for _, entry := range entries[:len(entries)-maxEntries] {
+		if err := os.Remove(entryPath(entry.ID)); err != nil && !os.IsNotExist(err) {
+			logger.Error("Failed to prune journal entry", "id", entry.ID, "error", err)
+		}
+	}
+	entries = append([]*Entry(nil), entries[len(entries)-maxEntries:]...)
+	collectGarbage()
+}
+
+func collectGarbage() {
+	referenced := map[string]bool{}
+	for hash := range pending {
+		referenced[hash] = true
+	}
+	for _, entry := range entries {
+		for _, c := range entry.Changes {
+			if c.Before.Saved {
+				referenced[c.Before.Hash] = true
+			}
+		}
+	}
+
+	blobs, err := os.ReadDir(filepath.Join(dir, "blobs"))
+	if err != nil {
+		return
+	}
+	for _, blob := range blobs {
+		if !referenced[blob.Name()] {
+			os.Remove(filepath.Join(dir, "blobs", blob.Name()))
+		}
+	}
+}
+
+func loadEntries() []*Entry {
+	files, err := os.ReadDir(filepath.Join(dir, "entries"))
+	if err != nil {
+		return nil
+	}
+
+	loaded := []*Entry{}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "entries", file.Name()))
+		if err != nil {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			continue
+		}
+		loaded = append(loaded, &entry)
+	}
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].Time.Before(loaded[j].Time) })
+	return loaded
+}
+
+func writeEntry(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp := entryPath(entry.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, entryPath(entry.ID))
+}
+
+func entryPath(id string) string {
+	return filepath.Join(dir, "entries", id+".json")
+}
+
+func absolute(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
+
+// newID sorts by recording time and stays unique within the same instant.
+func newID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,216 @@
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"agent-dev-environment/src/library/logger"
+)
+
+// State is what a path held before or after an operation. Only what undo
+// needs is recorded: a content hash for files the API wrote, and the saved
+// content or trash entry for files undo has to bring back.
+type State struct {
+	Exists  bool        `json:"exists"`
+	IsDir   bool        `json:"is_dir,omitempty"`
+	Mode    fs.FileMode `json:"mode,omitempty"`
+	Size    int64       `json:"size,omitempty"`
+	ModTime time.Time   `json:"mod_time,omitzero"`
+	Hash    string      `json:"hash,omitempty"`
+	Saved   bool        `json:"saved,omitempty"`    // Content is kept in the journal
+	TrashID string      `json:"trash_id,omitempty"` // Content is kept in the trash
+	Lost    bool        `json:"lost,omitempty"`     // Content was discarded for good
+
+	data    []byte // Content to save when the entry is recorded
+	pending bool   // Content was saved ahead and is held until recorded or released
+}
+
+// Absent is the state of a path that does not exist.
+var Absent = State{}
+
+// Content describes a file holding data.
+func Content(data []byte) State {
+	sum := sha256.Sum256(data)
+	return State{Exists: true, Size: int64(len(data)), Hash: hex.EncodeToString(sum[:])}
+}
+
+// SavedContent describes a file holding data and keeps data so undo can
+// write it back.
+func SavedContent(data []byte, mode fs.FileMode) State {
+	s := Content(data)
+	s.Mode = mode.Perm()
+	s.Saved = true
+	s.data = data
+	return s
+}
+
+// Stat describes what is at path now. Files are compared by size and
+// modification time, which a rename preserves.
+func Stat(path string) State {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return Absent
+	}
+	s := State{Exists: true, IsDir: info.IsDir(), Mode: info.Mode().Perm()}
+	if !s.IsDir {
+		s.Size = info.Size()
+		s.ModTime = info.ModTime()
+	}
+	return s
+}
+
+// SavedStat is Stat, and for a regular file also keeps its current content
+// so undo can write it back after the file is replaced.
+func SavedStat(path string) State {
+	s := Stat(path)
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() || dir == "" {
+		return s
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	hash, err := storeFile(path)
+	if err != nil {
+		logger.Error("Failed to save journal content", "path", path, "error", err)
+		return s
+	}
+	// Kept from garbage collection until the entry referencing it is recorded
+	pending[hash]++
+	s.Hash = hash
+	s.Saved = true
+	s.pending = true
+	return s
+}
+
+// Release gives up content SavedStat kept for an operation that failed
+// before it could be recorded, so garbage collection can remove it.
+func Release(states ...State) {
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range states {
+		states[i].release()
+	}
+}
+
+// Trashed describes a path that was moved to the trash.
+func Trashed(isDir bool, size int64, trashID string) State {
+	return State{Exists: true, IsDir: isDir, Size: size, TrashID: trashID}
+}
+
+// Discarded describes a path that was deleted without keeping its content.
+func Discarded(isDir bool, size int64) State {
+	return State{Exists: true, IsDir: isDir, Size: size, Lost: true}
+}
+
+// restorable reports whether undo can bring this state back.
+func (s State) restorable() bool {
+	return !s.Exists || (!s.Lost && (s.IsDir || s.Saved || s.TrashID != ""))
+}
+
+// matches reports whether path still holds this state.
+func (s State) matches(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return !s.Exists
+	}
+	if !s.Exists || info.IsDir() != s.IsDir {
+		return false
+	}
+	if s.IsDir {
+		return true
+	}
+	if s.Hash != "" {
+		hash, err := hashFile(path)
+		return err == nil && hash == s.Hash
+	}
+	return info.Size() == s.Size && info.ModTime().Equal(s.ModTime)
+}
+
+// save stores the content the state asked to keep. Callers hold mu.
+func (s *State) save() error {
+	s.release()
+	if !s.Saved || s.data == nil {
+		return nil
+	}
+	return writeBlob(s.Hash, s.data)
+}
+
+// release drops the hold SavedStat put on the state's content. Callers hold mu.
+func (s *State) release() {
+	if !s.pending {
+		return
+	}
+	s.pending = false
+	if pending[s.Hash]--; pending[s.Hash] <= 0 {
+		delete(pending, s.Hash)
+	}
+}
+
+func blobPath(hash string) string {
+	return filepath.Join(dir, "blobs", hash)
+}
+
+func writeBlob(hash string, data []byte) error {
+	if _, err := os.Stat(blobPath(hash)); err == nil {
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Join(dir, "blobs"), ".incoming-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), blobPath(hash))
+}
+
+// storeFile copies the file at path into the blob store and returns its hash.
+func storeFile(path string) (string, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Join(dir, "blobs"), ".incoming-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), in)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	hash := hex.EncodeToString(h.Sum(nil))
+	return hash, os.Rename(tmp.Name(), blobPath(hash))
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,241 @@
+package journal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/trash"
+	"agent-dev-environment/src/library/workspace"
+)
+
+var (
+	// ErrIrreversible is returned for entries whose previous state was not kept.
+	ErrIrreversible = errors.New("operation cannot be undone")
+	// ErrAlreadyUndone is returned for entries that were already undone.
+	ErrAlreadyUndone = errors.New("operation was already undone")
+)
+
+// ConflictError reports that a path changed after the operation being
+// undone, so undoing it would lose that change.
+type ConflictError struct {
+	EntryID string
+	Path    string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s changed since operation %s", e.Path, e.EntryID)
+}
+
+// UndoError wraps the error that stopped an undo with the entry it concerned.
+type UndoError struct {
+	EntryID string
+	Err     error
+}
+
+func (e *UndoError) Error() string {
+	return fmt.Sprintf("operation %s: %v", e.EntryID, e.Err)
+}
+
+func (e *UndoError) Unwrap() error {
+	return e.Err
+}
+
+// Undo reverts the entry with the given ID. Files removed by undo go to the
+// trash under requestID. allow is asked about every path the undo would
+// change before any is touched, and can veto it.
+func Undo(id, requestID string, allow func(path string) error) (*Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, entry := range entries {
+		if entry.ID == id {
+			if err := undo(entry, requestID, allow); err != nil {
+				return nil, err
+			}
+			return entry, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// UndoLast reverts the newest count entries not yet undone, newest first.
+// It stops at the first entry that cannot be undone and returns the entries
+// undone before it along with the error.
+func UndoLast(count int, requestID string, allow func(path string) error) ([]Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	undone := []Entry{}
+	for i := len(entries) - 1; i >= 0 && len(undone) < count; i-- {
+		if entries[i].UndoneAt != nil {
+			continue
+		}
+		if err := undo(entries[i], requestID, allow); err != nil {
+			return undone, err
+		}
+		undone = append(undone, *entries[i])
+	}
+	return undone, nil
+}
+
+// undo checks every change before applying any, so a conflict leaves the
+// filesystem untouched. Callers hold mu.
+func undo(entry *Entry, requestID string, allow func(path string) error) error {
+	fail := func(err error) error { return &UndoError{EntryID: entry.ID, Err: err} }
+
+	if entry.UndoneAt != nil {
+		return fail(ErrAlreadyUndone)
+	}
+	if !entry.Reversible {
+		return fail(ErrIrreversible)
+	}
+
+	for i := len(entry.Changes) - 1; i >= 0; i-- {
+		c := entry.Changes[i]
+		if err := check(entry, c); err != nil {
+			return fail(err)
+		}
+		if err := allow(c.Path); err != nil {
+			return err
+		}
+		if c.From != "" {
+			if err := allow(c.From); err != nil {
+				return err
+			}
+		}
+	}
+	for i := len(entry.Changes) - 1; i >= 0; i-- {
+		if err := revert(entry, entry.Changes[i], requestID); err != nil {
+			return fail(err)
+		}
+	}
+
+	now := time.Now().UTC()
+	entry.UndoneAt = &now
+	return writeEntry(entry)
+}
+
+func check(entry *Entry, c Change) error {
+	if !c.After.matches(c.Path) {
+		return &ConflictError{EntryID: entry.ID, Path: c.Path}
+	}
+	if !c.Before.Exists && c.After.IsDir && !c.Tree && !onlyCreated(entry, c.Path) {
+		return &ConflictError{EntryID: entry.ID, Path: c.Path}
+	}
+	if c.From != "" {
+		if _, err := os.Lstat(c.From); err == nil {
+			return &ConflictError{EntryID: entry.ID, Path: c.From}
+		}
+	}
+	if c.Before.TrashID != "" && !inTrash(c.Before.TrashID) {
+		return fmt.Errorf("%s is no longer in the trash", c.Path)
+	}
+	return nil
+}
+
+func revert(entry *Entry, c Change, requestID string) error {
+	if c.From != "" {
+		if err := workspace.MkdirAll(filepath.Dir(c.From), 0o755); err != nil {
+			return err
+		}
+		if _, err := fsutil.Move(c.Path, c.From, false); err != nil {
+			return err
+		}
+		return restore(c.Path, c.Before)
+	}
+
+	if c.Tree && c.After.IsDir {
+		if _, err := trash.Put(c.Path, requestID); err != nil {
+			return err
+		}
+		return restore(c.Path, c.Before)
+	}
+	if !c.Before.Exists {
+		if c.After.IsDir {
+			err := workspace.Remove(c.Path)
+			if errors.Is(err, syscall.ENOTEMPTY) || errors.Is(err, syscall.EEXIST) {
+				return &ConflictError{EntryID: entry.ID, Path: c.Path}
+			}
+			return err
+		}
+		// Files are only ever removed into the trash, so undo can be undone
+		_, err := trash.Put(c.Path, requestID)
+		return err
+	}
+	return restore(c.Path, c.Before)
+}
+
+// restore brings back the state path had before the operation.
+func restore(path string, s State) error {
+	switch {
+	case !s.Exists:
+		return nil
+	case s.TrashID != "":
+		_, err := trash.Restore(s.TrashID, path, false)
+		return err
+	case s.IsDir:
+		return workspace.MkdirAll(path, 0o755)
+	}
+
+	data, err := os.ReadFile(blobPath(s.Hash))
+	if err != nil {
+		return fmt.Errorf("saved content missing for %s: %w", path, err)
+	}
+	tmp, err := workspace.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".undo-*")
+	if err != nil {
+		return err
+	}
+	defer workspace.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := workspace.Chmod(tmp.Name(), s.Mode.Perm()); err != nil {
+		return err
+	}
+	return workspace.Rename(tmp.Name(), path)
+}
+
+// onlyCreated reports whether the directory at path holds nothing but
+// directories the same entry created, which undo removes first.
+func onlyCreated(entry *Entry, path string) bool {
+	names, err := os.ReadDir(path)
+	if err != nil {
+		return false
+	}
+	for _, name := range names {
+		child := filepath.Join(path, name.Name())
+		created := false
+		for _, c := range entry.Changes {
+			if c.Path == child && !c.Before.Exists {
+				created = true
+				break
+			}
+		}
+		if !created {
+			return false
+		}
+	}
+	return true
+}
+
+func inTrash(id string) bool {
+	entries, err := trash.List()
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package policy
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"agent-dev-environment/src/library/glob"
+	"agent-dev-environment/src/library/workspace"
+)
+
+type Action string
+
+const (
+	// Deny blocks reading and writing.
+	Deny Action = "deny"
+	// ReadOnly blocks writing.
+	ReadOnly Action = "readonly"
+	// RequireApproval blocks writing unless the request approves the rule.
+	RequireApproval Action = "require-approval"
+)
+
+// Rule protects the paths matching Pattern. Patterns starting with '/' are
+// matched against absolute paths; others against every trailing part of the
+// path, so ".env" matches any .env file and ".git/hooks/**" any hooks
+// directory's contents.
+type Rule struct {
+	Pattern string
+	Action  Action
+}
+
+// Violation is returned for operations a rule does not allow.
+type Violation struct {
+	Rule Rule
+	Path string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s is protected by rule %q (%s)", v.Path, v.Rule.Pattern, v.Rule.Action)
+}
+
+var rules []Rule
+
+// Init parses a comma-separated list of pattern=action rules. "none"
+// disables protection.
+func Init(spec string) {
+	rules = nil
+	if spec == "none" {
+		return
+	}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pattern, action, ok := strings.Cut(item, "=")
+		pattern, action = strings.TrimSpace(pattern), strings.TrimSpace(action)
+		if !ok || pattern == "" || glob.Validate(strings.TrimPrefix(pattern, "/")) != nil {
+			panic(fmt.Sprintf("invalid PROTECTED_PATHS rule: %q. Must be pattern=action", item))
+		}
+		switch Action(action) {
+		case Deny, ReadOnly, RequireApproval:
+		default:
+			panic(fmt.Sprintf("invalid PROTECTED_PATHS action: %q. Must be 'deny', 'readonly' or 'require-approval'", action))
+		}
+		rules = append(rules, Rule{Pattern: pattern, Action: Action(action)})
+	}
+}
+
+// CheckRead returns a Violation if a deny rule matches path.
+func CheckRead(path string) error {
+	return check(path, func(rule Rule) bool { return rule.Action == Deny })
+}
+
+// CheckWrite returns a Violation if a rule forbids changing path. Rules
+// whose patterns are listed in approved do not block it.
+func CheckWrite(path string, approved []string) error {
+	return check(path, func(rule Rule) bool {
+		return rule.Action != RequireApproval || !slices.Contains(approved, rule.Pattern)
+	})
+}
+
+// CheckTree is CheckWrite for path and everything below it, for operations
+// such as deleting or moving a directory.
+func CheckTree(path string, approved []string) error {
+	return CheckMirror(path, path, approved)
+}
+
+// CheckMirror is CheckWrite for dst and every path below it that copying
+// or moving src there would write.
+func CheckMirror(src, dst string, approved []string) error {
+	if err := CheckWrite(dst, approved); err != nil || len(rules) == 0 {
+		return err
+	}
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == src {
+			return nil
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return nil
+		}
+		return CheckWrite(filepath.Join(dst, rel), approved)
+	})
+	if _, ok := err.(*Violation); ok {
+		return err
+	}
+	return nil
+}
+
+// check returns a Violation for the first rule matching path, as given or
+// with symlinks resolved, for which blocks is true.
+func check(path string, blocks func(Rule) bool) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	candidates := []string{}
+	if abs, err := filepath.Abs(path); err == nil {
+		candidates = append(candidates, filepath.ToSlash(abs))
+	}
+	if resolved, err := workspace.Resolve(path); err == nil {
+		candidates = append(candidates, filepath.ToSlash(resolved))
+	}
+
+	for _, rule := range rules {
+		if !blocks(rule) {
+			continue
+		}
+		for _, candidate := range candidates {
+			if matches(rule.Pattern, candidate) {
+				return &Violation{Rule: rule, Path: path}
+			}
+		}
+	}
+	return nil
+}
+
+func matches(pattern, abs string) bool {
+	if strings.HasPrefix(pattern, "/") {
+		return glob.Match(pattern, abs)
+	}
+	parts := strings.Split(strings.TrimPrefix(abs, "/"), "/")
+	for i := range parts {
+		if glob.Match(pattern, strings.Join(parts[i:], "/")) {
+			return true
+		}
+	}
+	return false
+}
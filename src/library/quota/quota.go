@@ -0,0 +1,306 @@
+package quota
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-dev-environment/src/library/trash"
+)
+
+// rescanAfter is how long a measured usage is trusted. Writes through the
+// API keep it current in between; changes made by commands are only seen
+// by the next scan, which runs in the background.
+const rescanAfter = 10 * time.Second
+
+type Limit string
+
+const (
+	FileBytes Limit = "file_bytes"
+	Bytes     Limit = "bytes"
+	Files     Limit = "files"
+)
+
+// Limits of zero are not enforced.
+type Limits struct {
+	MaxBytes     int64
+	MaxFileBytes int64
+	MaxFiles     int64
+}
+
+// Usage is what the regular files below the root take up.
+type Usage struct {
+	Bytes     int64
+	Files     int64
+	ScannedAt time.Time
+}
+
+// ExceededError is returned for writes that would exceed a limit.
+type ExceededError struct {
+	Limit Limit
+	Max   int64
+	Path  string
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("writing %s would exceed the %s limit of %d", e.Path, e.Limit, e.Max)
+}
+
+var (
+	mu      sync.Mutex
+	root    string
+	dataDir string
+	limits  Limits
+	usage   Usage
+
+	scanning  bool
+	scanned   chan struct{} // Closed when the scan in progress ends
+	scanErr   error         // Of the last scan
+	sinceScan Usage         // Reserved while the scan in progress runs
+)
+
+// Init limits the size of any file written, and the total size and number
+// of files below rootPath. The server's data directory and the trash are not
+// counted.
+func Init(rootPath, dataPath, maxBytes, maxFileBytes, maxFiles string) {
+	var err error
+	if root, err = filepath.Abs(rootPath); err != nil {
+		panic(fmt.Sprintf("invalid quota root: %v", err))
+	}
+	if dataDir, err = filepath.Abs(dataPath); err != nil {
+		panic(fmt.Sprintf("invalid DATA_DIR: %v", err))
+	}
+	limits = Limits{
+		MaxBytes:     parse("QUOTA_MAX_BYTES", maxBytes),
+		MaxFileBytes: parse("QUOTA_MAX_FILE_BYTES", maxFileBytes),
+		MaxFiles:     parse("QUOTA_MAX_FILES", maxFiles),
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	usage = Usage{}
+	if limits.MaxBytes > 0 || limits.MaxFiles > 0 {
+		// Measured ahead of the first write, which would otherwise wait for it
+		startScan()
+	}
+}
+
+func parse(name, value string) int64 {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		panic(fmt.Sprintf("invalid %s: %q. Must be a number, 0 for no limit", name, value))
+	}
+	return n
+}
+
+// Root returns the directory whose total size and file count are limited.
+func Root() string {
+	return root
+}
+
+// Current returns the limits and the usage below the root, measured now.
+func Current() (Usage, Limits, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if scanning {
+		// Started before the call, so it may have missed recent changes
+		wait(scanned)
+	}
+	wait(startScan())
+	if scanErr != nil {
+		return Usage{}, limits, scanErr
+	}
+	return usage, limits, nil
+}
+
+// Available returns the largest file that can be written at path, replacing
+// what is there, without exceeding a limit.
+func Available(path string) int64 {
+	mu.Lock()
+	defer mu.Unlock()
+
+	available := int64(math.MaxInt64)
+	if limits.MaxFileBytes > 0 {
+		available = limits.MaxFileBytes
+	}
+	if limits.MaxBytes > 0 && within(path) && refresh() == nil {
+		replaced, _ := existing(path)
+		available = min(available, max(limits.MaxBytes-usage.Bytes+replaced, 0))
+	}
+	return available
+}
+
+// Exceeded returns the error for a file of more than Available(path) bytes,
+// for writers that stop reading once they reach it.
+func Exceeded(path string) error {
+	if limits.MaxFileBytes > 0 && Available(path) == limits.MaxFileBytes {
+		return &ExceededError{Limit: FileBytes, Max: limits.MaxFileBytes, Path: path}
+	}
+	return &ExceededError{Limit: Bytes, Max: limits.MaxBytes, Path: path}
+}
+
+// Reserve returns an ExceededError if writing a file of size bytes at path,
+// replacing what is there, would exceed a limit. Otherwise the file is
+// counted as written.
+func Reserve(path string, size int64) error {
+	return ReserveAll(map[string]int64{path: size})
+}
+
+// ReserveAll is Reserve for several files written together, which are
+// either all counted or not at all.
+func ReserveAll(files map[string]int64) error {
+	if limits == (Limits{}) {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	var bytes, count int64
+	for path, size := range files {
+		if limits.MaxFileBytes > 0 && size > limits.MaxFileBytes {
+			return &ExceededError{Limit: FileBytes, Max: limits.MaxFileBytes, Path: path}
+		}
+		if within(path) {
+			replaced, exists := existing(path)
+			bytes += size - replaced
+			if !exists {
+				count++
+			}
+		}
+	}
+	if bytes <= 0 && count == 0 {
+		return nil
+	}
+	if limits.MaxBytes == 0 && limits.MaxFiles == 0 {
+		return nil
+	}
+
+	if err := refresh(); err != nil {
+		return err
+	}
+	path := first(files)
+	if limits.MaxBytes > 0 && bytes > 0 && usage.Bytes+bytes > limits.MaxBytes {
+		return &ExceededError{Limit: Bytes, Max: limits.MaxBytes, Path: path}
+	}
+	if limits.MaxFiles > 0 && count > 0 && usage.Files+count > limits.MaxFiles {
+		return &ExceededError{Limit: Files, Max: limits.MaxFiles, Path: path}
+	}
+	usage.Bytes += bytes
+	usage.Files += count
+	if scanning {
+		sinceScan.Bytes += bytes
+		sinceScan.Files += count
+	}
+	return nil
+}
+
+// refresh starts measuring the usage again once the last measurement is
+// stale, and goes on with the usage kept up to date by reservations. Only
+// before the first measurement is in does it wait for it. Callers hold mu.
+func refresh() error {
+	if time.Since(usage.ScannedAt) < rescanAfter {
+		return nil
+	}
+	done := startScan()
+	if !usage.ScannedAt.IsZero() {
+		return nil
+	}
+	wait(done)
+	return scanErr
+}
+
+// startScan measures the usage below the root in the background, unless a
+// scan is already running, and returns a channel closed once it is done.
+// Files reserved meanwhile are counted on top of what it measures, even if
+// it saw them too, so the usage errs on the high side until the next scan.
+// Callers hold mu.
+func startScan() <-chan struct{} {
+	if scanning {
+		return scanned
+	}
+	scanning = true
+	scanned = make(chan struct{})
+	sinceScan = Usage{}
+
+	go func() {
+		measured, err := scan()
+
+		mu.Lock()
+		defer mu.Unlock()
+		scanErr = err
+		if err == nil {
+			usage = Usage{Bytes: measured.Bytes + sinceScan.Bytes, Files: measured.Files + sinceScan.Files, ScannedAt: measured.ScannedAt}
+		}
+		scanning = false
+		close(scanned)
+	}()
+	return scanned
+}
+
+// wait releases mu until done is closed. Callers hold mu.
+func wait(done <-chan struct{}) {
+	mu.Unlock()
+	<-done
+	mu.Lock()
+}
+
+// scan measures the usage below the root.
+func scan() (Usage, error) {
+	measured := Usage{ScannedAt: time.Now()}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable or vanished entries cannot be counted
+			return nil
+		}
+		if d.IsDir() && (path == dataDir || trash.Within(path)) {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			measured.Bytes += info.Size()
+			measured.Files++
+		}
+		return nil
+	})
+	return measured, err
+}
+
+// existing returns the size of the regular file at path, which a write
+// would replace.
+func existing(path string) (int64, bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, false
+	}
+	if !info.Mode().IsRegular() {
+		return 0, true
+	}
+	return info.Size(), true
+}
+
+func within(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	return abs == root || strings.HasPrefix(abs, root+string(filepath.Separator)) || root == string(filepath.Separator)
+}
+
+// first returns the lexically first path, so errors name a stable one.
+func first(files map[string]int64) string {
+	var result string
+	for path := range files {
+		if result == "" || path < result {
+			result = path
+		}
+	}
+	return result
+}
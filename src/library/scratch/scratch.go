@@ -0,0 +1,210 @@
+package scratch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-dev-environment/src/library/logger"
+	"agent-dev-environment/src/library/session"
+	"agent-dev-environment/src/library/workspace"
+)
+
+// sweepInterval is how often expired directories are deleted when nothing
+// else touches the scratch area.
+const sweepInterval = time.Minute
+
+// ErrNotFound is returned for scratch directory IDs that do not exist.
+var ErrNotFound = errors.New("scratch directory not found")
+
+// Dir is a scratch directory. It is deleted once its session ends or, if it
+// has one, once ExpiresAt passes.
+type Dir struct {
+	ID        string     `json:"id"`
+	Path      string     `json:"path"`
+	Session   string     `json:"session"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// expired reports whether d should be deleted.
+func (d Dir) expired(now time.Time) bool {
+	if d.ExpiresAt != nil {
+		return now.After(*d.ExpiresAt)
+	}
+	return !session.Active(d.Session)
+}
+
+var (
+	mu      sync.Mutex
+	dirsDir string // Holds the scratch directories; writable through the API
+	metaDir string // Holds their metadata, out of the API's reach
+)
+
+// Init keeps scratch directories under dataDir, allows paths inside them
+// even when confined to a workspace, and starts deleting expired ones.
+// Directories tied to a session are deleted right away, as sessions do not
+// outlive the server.
+func Init(dataDir string) {
+	base, err := filepath.Abs(filepath.Join(dataDir, "scratch"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid DATA_DIR: %v", err))
+	}
+	dirsDir, metaDir = filepath.Join(base, "dirs"), filepath.Join(base, "meta")
+	for _, d := range []string{dirsDir, metaDir} {
+		if err := os.MkdirAll(d, 0o700); err != nil {
+			panic(fmt.Sprintf("could not create scratch directory: %v", err))
+		}
+	}
+	if err := workspace.Allow(dirsDir); err != nil {
+		panic(fmt.Sprintf("could not allow scratch directory: %v", err))
+	}
+
+	mu.Lock()
+	dirs, _ := list()
+	for _, d := range dirs {
+		if d.ExpiresAt == nil {
+			remove(d)
+		}
+	}
+	mu.Unlock()
+
+	go func() {
+		for range time.Tick(sweepInterval) {
+			Sweep()
+		}
+	}()
+}
+
+// Create makes a new, empty directory named after prefix. It is tied to
+// the session with the given ID, or expires after ttl if that is positive.
+func Create(prefix, sessionID string, ttl time.Duration) (Dir, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	sweep()
+
+	path, err := os.MkdirTemp(dirsDir, prefix+"-*")
+	if err != nil {
+		return Dir{}, err
+	}
+	d := Dir{ID: filepath.Base(path), Path: path, Session: sessionID, CreatedAt: time.Now().UTC()}
+	if ttl > 0 {
+		expires := d.CreatedAt.Add(ttl)
+		d.ExpiresAt = &expires
+	}
+	if err := writeMeta(d); err != nil {
+		os.RemoveAll(path)
+		return Dir{}, err
+	}
+	return d, nil
+}
+
+// List returns the scratch directories that have not expired, newest first.
+func List() ([]Dir, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	sweep()
+	return list()
+}
+
+// Delete removes a scratch directory and everything in it.
+func Delete(id string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	d, err := load(id)
+	if err != nil {
+		return err
+	}
+	return remove(d)
+}
+
+// Sweep deletes the directories whose session ended or whose TTL passed.
+func Sweep() {
+	mu.Lock()
+	defer mu.Unlock()
+	sweep()
+}
+
+// sweep is Sweep for callers that hold mu.
+func sweep() {
+	dirs, err := list()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for _, d := range dirs {
+		if d.expired(now) {
+			if err := remove(d); err != nil {
+				logger.Error("Failed to delete scratch directory", "path", d.Path, "error", err)
+			}
+		}
+	}
+}
+
+func list() ([]Dir, error) {
+	entries, err := os.ReadDir(metaDir)
+	if err != nil {
+		return nil, err
+	}
+	dirs := []Dir{}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if d, err := load(id); err == nil {
+			dirs = append(dirs, d)
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].CreatedAt.After(dirs[j].CreatedAt) })
+	return dirs, nil
+}
+
+func load(id string) (Dir, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return Dir{}, ErrNotFound
+	}
+	data, err := os.ReadFile(metaPath(id))
+	if err != nil {
+		return Dir{}, ErrNotFound
+	}
+	var d Dir
+	if err := json.Unmarshal(data, &d); err != nil {
+		return Dir{}, ErrNotFound
+	}
+	d.ID = id
+	d.Path = filepath.Join(dirsDir, id)
+	return d, nil
+}
+
+// remove deletes the directory before its metadata, so a failure leaves it
+// listed rather than orphaned.
+func remove(d Dir) error {
+	if err := os.RemoveAll(d.Path); err != nil {
+		return err
+	}
+	return os.Remove(metaPath(d.ID))
+}
+
+func writeMeta(d Dir) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	tmp := metaPath(d.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, metaPath(d.ID))
+}
+
+func metaPath(id string) string {
+	return filepath.Join(metaDir, id+".json")
+}
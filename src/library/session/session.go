@@ -0,0 +1,147 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default is the session of requests that do not name one.
+const Default = ""
+
+type session struct {
+	cwd      string
+	lastUsed time.Time
+}
+
+var (
+	mu          sync.Mutex
+	sessions    = map[string]*session{}
+	startDir    string
+	idleTimeout time.Duration
+	maxSessions int
+)
+
+// Init starts every session in the server's working directory. Sessions
+// other than the default one are forgotten after being idle for timeout,
+// and beyond count of them, the least recently used is forgotten first.
+func Init(timeout, count string) {
+	var err error
+	idleTimeout, err = time.ParseDuration(timeout)
+	if err != nil || idleTimeout <= 0 {
+		panic(fmt.Sprintf("invalid SESSION_IDLE_TIMEOUT: %q. Must be a positive Go duration", timeout))
+	}
+	maxSessions, err = strconv.Atoi(count)
+	if err != nil || maxSessions < 1 {
+		panic(fmt.Sprintf("invalid SESSION_MAX_COUNT: %q. Must be a positive number", count))
+	}
+	startDir, err = os.Getwd()
+	if err != nil {
+		panic(fmt.Sprintf("could not get working directory: %v", err))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	sessions = map[string]*session{}
+}
+
+// Cwd returns the working directory of the session with the given ID.
+func Cwd(id string) string {
+	mu.Lock()
+	defer mu.Unlock()
+	return get(id).cwd
+}
+
+// Chdir sets the working directory of the session with the given ID. dir
+// must be absolute.
+func Chdir(id, dir string) {
+	mu.Lock()
+	defer mu.Unlock()
+	get(id).cwd = dir
+}
+
+// Abs resolves path against the working directory of the session with the
+// given ID.
+func Abs(id, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(Cwd(id), path)
+}
+
+// Touch marks the session with the given ID as used, starting it if it is new.
+func Touch(id string) {
+	mu.Lock()
+	defer mu.Unlock()
+	get(id)
+}
+
+// Active reports whether the session with the given ID was used within the
+// idle timeout and has not ended. The default session never ends.
+func Active(id string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	forgetIdle()
+	_, ok := sessions[id]
+	return ok || id == Default
+}
+
+// End forgets the session with the given ID. Its next request starts a new
+// session in the server's working directory.
+func End(id string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(sessions, id)
+}
+
+// get returns the session, starting it if it is new, and forgets idle ones.
+// Callers hold mu.
+func get(id string) *session {
+	forgetIdle()
+	s, ok := sessions[id]
+	if !ok {
+		if id != Default {
+			forgetOldest()
+		}
+		s = &session{cwd: startDir}
+		sessions[id] = s
+	}
+	s.lastUsed = time.Now()
+	return s
+}
+
+// forgetIdle forgets sessions other than the default one that were idle for
+// longer than the timeout. Callers hold mu.
+func forgetIdle() {
+	now := time.Now()
+	for id, s := range sessions {
+		if id != Default && now.Sub(s.lastUsed) > idleTimeout {
+			delete(sessions, id)
+		}
+	}
+}
+
+// forgetOldest makes room for one more session by forgetting the least
+// recently used ones beyond maxSessions. Callers hold mu.
+func forgetOldest() {
+	for {
+		count := 0
+		oldest := ""
+		for id, s := range sessions {
+			if id == Default {
+				continue
+			}
+			count++
+			if oldest == "" || s.lastUsed.Before(sessions[oldest].lastUsed) {
+				oldest = id
+			}
+		}
+		if count < maxSessions {
+			return
+		}
+		delete(sessions, oldest)
+	}
+}
@@ -0,0 +1,279 @@
+package snapshot
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/trash"
+	"agent-dev-environment/src/library/workspace"
+)
+
+// ErrOutsideRoot is returned for restore paths outside the snapshot root.
+var ErrOutsideRoot = errors.New("path is outside the snapshot root")
+
+// RestoreResult summarises a restore.
+type RestoreResult struct {
+	Root      string
+	BackupID  string   // Snapshot of the state the restore replaced
+	Restored  []string // Absolute paths written
+	Deleted   []string // Absolute paths removed
+	Unchanged int
+}
+
+// RestoreOptions controls Restore.
+type RestoreOptions struct {
+	// Paths limits the restore to files at or below them.
+	Paths []string
+	// Allow is asked about every file the restore would write or delete
+	// before any is touched, and can veto the restore.
+	Allow func(path string) error
+	// Reserve is called with the size of every file to be written, keyed by
+	// path, once Allow has passed them all. An error stops the restore
+	// before anything is touched.
+	Reserve func(files map[string]int64) error
+	// Change is called before each file is written or deleted and before
+	// each directory is created or removed, with its path.
+	Change func(path string)
+	// Trashed is called after a directory standing where the snapshot has a
+	// file is moved to the trash, with its entry.
+	Trashed func(path string, entry trash.Entry)
+}
+
+// Restore makes the files below the snapshot root match the snapshot. When
+// opts.Paths are given, only files at or below them are touched. Files created
+// since the snapshot are deleted, except those the snapshot would have
+// skipped as ignored, and so are directories left empty that it did not
+// hold. A directory standing where the snapshot has a file is moved to the
+// trash, with whatever ignored files it still holds. The state being replaced is snapshotted first so the
+// restore itself can be undone.
+func Restore(id, requestID string, opts RestoreOptions) (*RestoreResult, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	m, err := load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes := make([]string, 0, len(opts.Paths))
+	for _, p := range opts.Paths {
+		rel, err := relativeTo(m.Root, p)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, rel)
+	}
+	selected := func(rel string) bool {
+		if len(prefixes) == 0 {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if prefix == "." || rel == prefix || strings.HasPrefix(rel, prefix+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	change := func(path string) {
+		if opts.Change != nil {
+			opts.Change(path)
+		}
+	}
+
+	for _, dir := range fsutil.MissingDirs(m.Root) {
+		change(dir)
+	}
+	if err := workspace.MkdirAll(m.Root, 0o755); err != nil {
+		return nil, err
+	}
+	backup, err := create(m.Root, "before restoring "+id, requestID, m.IncludeIgnored)
+	if err != nil {
+		return nil, err
+	}
+	result := &RestoreResult{Root: m.Root, BackupID: backup.ID}
+
+	wanted := map[string]File{}
+	for _, file := range m.Files {
+		if selected(file.Path) {
+			wanted[file.Path] = file
+		}
+	}
+
+	current := map[string]File{}
+	for _, file := range backup.Files {
+		current[file.Path] = file
+	}
+
+	var stale []string
+	for rel := range current {
+		if _, ok := wanted[rel]; !ok && selected(rel) {
+			stale = append(stale, rel)
+		}
+	}
+
+	// Older snapshots did not record directories, so they leave them be
+	// except for removing those that deleting files left empty
+	recordsDirs := m.Dirs != nil
+	wantedDirs := map[string]bool{}
+	for _, rel := range m.Dirs {
+		if selected(rel) {
+			wantedDirs[rel] = true
+		}
+	}
+	var staleDirs, missingDirs []string
+	if recordsDirs {
+		currentDirs := map[string]bool{}
+		for _, rel := range backup.Dirs {
+			currentDirs[rel] = true
+			if !wantedDirs[rel] && selected(rel) {
+				staleDirs = append(staleDirs, rel)
+			}
+		}
+		for rel := range wantedDirs {
+			if !currentDirs[rel] {
+				missingDirs = append(missingDirs, rel)
+			}
+		}
+	}
+	keep := func(dir string) bool {
+		rel, err := filepath.Rel(m.Root, dir)
+		return err == nil && wantedDirs[filepath.ToSlash(rel)]
+	}
+	var changed []string
+	for rel, file := range wanted {
+		if cur, ok := current[rel]; ok && cur.Hash == file.Hash && cur.Mode == file.Mode {
+			result.Unchanged++
+			continue
+		}
+		changed = append(changed, rel)
+	}
+
+	sizes := map[string]int64{}
+	for _, rel := range changed {
+		sizes[filepath.Join(m.Root, filepath.FromSlash(rel))] = wanted[rel].Size
+	}
+	if err := check(slices.Concat(stale, changed, staleDirs, missingDirs), m.Root, sizes, opts); err != nil {
+		// Nothing was replaced, so the backup is not needed
+		os.Remove(manifestPath(backup.ID))
+		return nil, err
+	}
+
+	for _, rel := range stale {
+		target := filepath.Join(m.Root, filepath.FromSlash(rel))
+		change(target)
+		if err := workspace.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		removeEmptyParents(m.Root, target, keep, change)
+		result.Deleted = append(result.Deleted, target)
+	}
+
+	// Deepest first, so parents are empty by the time they are reached.
+	// Directories still holding ignored files stay.
+	sort.Sort(sort.Reverse(sort.StringSlice(staleDirs)))
+	for _, rel := range staleDirs {
+		dir := filepath.Join(m.Root, filepath.FromSlash(rel))
+		if empty(dir) {
+			change(dir)
+			workspace.Remove(dir)
+		}
+	}
+	// Shallowest first, so each parent is reported before its children
+	sort.Strings(missingDirs)
+	for _, rel := range missingDirs {
+		dir := filepath.Join(m.Root, filepath.FromSlash(rel))
+		change(dir)
+		if err := workspace.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, rel := range changed {
+		target := filepath.Join(m.Root, filepath.FromSlash(rel))
+		if info, err := os.Lstat(target); err == nil && info.IsDir() {
+			// Its captured files were deleted above, so what is left the
+			// snapshot never held and is kept in the trash
+			entry, err := trash.Put(target, requestID)
+			if err != nil {
+				return nil, err
+			}
+			if opts.Trashed != nil {
+				opts.Trashed(target, entry)
+			}
+			result.Deleted = append(result.Deleted, target)
+		}
+		for _, dir := range fsutil.MissingDirs(filepath.Dir(target)) {
+			change(dir)
+		}
+		change(target)
+		if err := writeBlob(target, wanted[rel]); err != nil {
+			return nil, err
+		}
+		result.Restored = append(result.Restored, target)
+	}
+
+	// The backup may push the restored snapshot past the limit, so pruning
+	// waits until its content has been used
+	return result, prune()
+}
+
+// check runs opts.Allow on every path the restore touches, then
+// opts.Reserve on the files it writes.
+func check(rels []string, root string, sizes map[string]int64, opts RestoreOptions) error {
+	if opts.Allow != nil {
+		for _, rel := range rels {
+			if err := opts.Allow(filepath.Join(root, filepath.FromSlash(rel))); err != nil {
+				return err
+			}
+		}
+	}
+	if opts.Reserve != nil && len(sizes) > 0 {
+		return opts.Reserve(sizes)
+	}
+	return nil
+}
+
+// removeEmptyParents removes the directories between path and root that
+// deleting path left empty, stopping at the first one to keep. change is
+// called before each removal.
+func removeEmptyParents(root, path string, keep func(dir string) bool, change func(path string)) {
+	for dir := filepath.Dir(path); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if keep(dir) || !empty(dir) {
+			return
+		}
+		change(dir)
+		if workspace.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
+func empty(dir string) bool {
+	names, err := os.ReadDir(dir)
+	return err == nil && len(names) == 0
+}
+
+// relativeTo converts p, absolute or relative to root, into a clean
+// slash-separated path relative to root.
+func relativeTo(root, p string) (string, error) {
+	if filepath.IsAbs(p) {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return "", ErrOutsideRoot
+		}
+		p = rel
+	}
+	rel := path.Clean(filepath.ToSlash(p))
+	if rel == ".." || strings.HasPrefix(rel, "../") || strings.HasPrefix(rel, "/") {
+		return "", ErrOutsideRoot
+	}
+	return rel, nil
+}
@@ -0,0 +1,314 @@
+package snapshot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-dev-environment/src/library/walk"
+)
+
+// ErrNotFound is returned for snapshot IDs that do not exist.
+var ErrNotFound = errors.New("snapshot not found")
+
+// File is a regular file captured by a snapshot.
+type File struct {
+	Path    string      `json:"path"` // Slash-separated, relative to the snapshot root
+	Hash    string      `json:"hash"`
+	Size    int64       `json:"size"`
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mod_time"`
+}
+
+// Manifest describes a snapshot. File contents live in the shared blob
+// store, so a snapshot only costs the bytes that changed since earlier ones.
+type Manifest struct {
+	ID             string    `json:"id"`
+	Root           string    `json:"root"`
+	Label          string    `json:"label,omitempty"`
+	RequestID      string    `json:"request_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	IncludeIgnored bool      `json:"include_ignored"`
+	Files          []File    `json:"files"`
+	// Dirs lists every directory captured, so empty ones are restored too.
+	// Snapshots taken before directories were recorded have none.
+	Dirs     []string `json:"dirs"`
+	Bytes    int64    `json:"bytes"`
+	NewBytes int64    `json:"new_bytes"` // Content first stored by this snapshot
+}
+
+var (
+	mu       sync.Mutex
+	dir      string
+	dataDir  string
+	maxCount int
+	// Manifests never change once written, so parsed ones are kept
+	cache = map[string]*Manifest{}
+)
+
+// Init sets where snapshots are kept. Beyond count snapshots, the oldest
+// are deleted.
+func Init(dataPath, count string) {
+	var err error
+	maxCount, err = strconv.Atoi(count)
+	if err != nil || maxCount < 1 {
+		panic(fmt.Sprintf("invalid SNAPSHOT_MAX_COUNT: %q. Must be a positive number", count))
+	}
+
+	dataDir, err = filepath.Abs(dataPath)
+	if err != nil {
+		panic(fmt.Sprintf("invalid DATA_DIR: %v", err))
+	}
+	dir = filepath.Join(dataDir, "snapshots")
+	for _, sub := range []string{"blobs", "manifests"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			panic(fmt.Sprintf("could not create snapshot directory: %v", err))
+		}
+	}
+}
+
+// Create captures every regular file below root. Ignored files are left out
+// unless includeIgnored is set; the .git directory is never captured.
+func Create(root, label, requestID string, includeIgnored bool) (*Manifest, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	m, err := create(absRoot, label, requestID, includeIgnored)
+	if err != nil {
+		return nil, err
+	}
+	return m, prune()
+}
+
+func create(root, label, requestID string, includeIgnored bool) (*Manifest, error) {
+	// Files unchanged since the previous snapshot of this root are not re-read
+	previous := map[string]File{}
+	if last := latest(root); last != nil {
+		for _, file := range last.Files {
+			previous[file.Path] = file
+		}
+	}
+
+	m := &Manifest{
+		ID:             newID(),
+		Root:           root,
+		Label:          label,
+		RequestID:      requestID,
+		CreatedAt:      time.Now().UTC(),
+		IncludeIgnored: includeIgnored,
+		Files:          []File{},
+		Dirs:           []string{},
+	}
+
+	var collectMu sync.Mutex
+	err := eachFile(root, includeIgnored, func(path, rel string, info fs.FileInfo) error {
+		if info.IsDir() {
+			collectMu.Lock()
+			m.Dirs = append(m.Dirs, rel)
+			collectMu.Unlock()
+			return nil
+		}
+		file := File{Path: rel, Size: info.Size(), Mode: info.Mode(), ModTime: info.ModTime()}
+
+		var added int64
+		if prev, ok := previous[file.Path]; ok && unchanged(prev, file) {
+			file.Hash = prev.Hash
+		} else {
+			var err error
+			file.Hash, added, err = storeFile(path)
+			if err != nil {
+				// Files that vanish or cannot be read mid-walk are left out
+				return nil
+			}
+		}
+
+		collectMu.Lock()
+		m.Files = append(m.Files, file)
+		m.Bytes += file.Size
+		m.NewBytes += added
+		collectMu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	sort.Strings(m.Dirs)
+	if err := writeManifest(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// List returns the snapshots, newest first. A non-empty root restricts the
+// result to snapshots of that directory.
+func List(root string) ([]Manifest, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	manifests, err := listManifests()
+	if err != nil || root == "" {
+		return manifests, err
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []Manifest{}
+	for _, m := range manifests {
+		if m.Root == absRoot {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered, nil
+}
+
+// Delete removes a snapshot and any content only it referenced.
+func Delete(id string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, err := load(id); err != nil {
+		return err
+	}
+	if err := os.Remove(manifestPath(id)); err != nil {
+		return err
+	}
+	return collectGarbage()
+}
+
+// prune deletes the oldest snapshots beyond maxCount. Callers hold mu.
+func prune() error {
+	manifests, err := listManifests()
+	if err != nil || len(manifests) <= maxCount {
+		return err
+	}
+	for _, m := range manifests[maxCount:] {
+		if err := os.Remove(manifestPath(m.ID)); err != nil {
+			return err
+		}
+	}
+	return collectGarbage()
+}
+
+func unchanged(prev, cur File) bool {
+	return prev.Size == cur.Size && prev.Mode == cur.Mode && prev.ModTime.Equal(cur.ModTime)
+}
+
+// eachFile calls fn for the regular files and directories below root that
+// snapshots capture, with their slash-separated path relative to root. The
+// server's own data directory is skipped when it lies inside root.
+func eachFile(root string, includeIgnored bool, fn func(path, rel string, info fs.FileInfo) error) error {
+	opts := walk.Options{RespectIgnore: !includeIgnored, Hidden: true, Dirs: true}
+	return walk.Walk(root, opts, func(path string, d fs.DirEntry) error {
+		if path == dataDir || strings.HasPrefix(path, dataDir+string(filepath.Separator)) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		return fn(path, filepath.ToSlash(rel), info)
+	})
+}
+
+// latest returns the newest snapshot of root, or nil.
+func latest(root string) *Manifest {
+	manifests, err := listManifests()
+	if err != nil {
+		return nil
+	}
+	for _, m := range manifests {
+		if m.Root == root {
+			return &m
+		}
+	}
+	return nil
+}
+
+func listManifests() ([]Manifest, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, "manifests"))
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := []Manifest{}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		m, err := load(id)
+		if err != nil {
+			continue
+		}
+		manifests = append(manifests, *m)
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].CreatedAt.After(manifests[j].CreatedAt) })
+	return manifests, nil
+}
+
+func load(id string) (*Manifest, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return nil, ErrNotFound
+	}
+	if m, ok := cache[id]; ok {
+		if _, err := os.Stat(manifestPath(id)); err == nil {
+			return m, nil
+		}
+		delete(cache, id)
+	}
+	data, err := os.ReadFile(manifestPath(id))
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, ErrNotFound
+	}
+	cache[id] = &m
+	return &m, nil
+}
+
+func writeManifest(m *Manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	tmp := manifestPath(m.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, manifestPath(m.ID))
+}
+
+func manifestPath(id string) string {
+	return filepath.Join(dir, "manifests", id+".json")
+}
+
+// newID sorts by creation time and stays unique within the same instant.
+func newID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
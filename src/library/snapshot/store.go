@@ -0,0 +1,140 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"agent-dev-environment/src/library/workspace"
+)
+
+// blobPath returns where the content with the given hash is stored.
+func blobPath(hash string) string {
+	return filepath.Join(dir, "blobs", hash[:2], hash[2:])
+}
+
+// storeFile hashes the file at path and adds its content to the blob store
+// if it is not there yet. It returns the hash and how many bytes were added.
+func storeFile(path string) (string, int64, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer in.Close()
+
+	// Stream into a temporary blob while hashing so large files are read once
+	tmp, err := os.CreateTemp(filepath.Join(dir, "blobs"), ".incoming-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), in)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+	dst := blobPath(hash)
+	if _, err := os.Stat(dst); err == nil {
+		return hash, 0, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return "", 0, err
+	}
+	return hash, n, nil
+}
+
+// hashFile returns the sha256 of the file at path without storing it.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeBlob atomically replaces path with the stored content of file,
+// restoring its mode and modification time.
+func writeBlob(path string, file File) error {
+	in, err := os.Open(blobPath(file.Hash))
+	if err != nil {
+		return fmt.Errorf("snapshot content missing for %s: %w", file.Path, err)
+	}
+	defer in.Close()
+
+	if err := workspace.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := workspace.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".restore-*")
+	if err != nil {
+		return err
+	}
+	defer workspace.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, in)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := workspace.Chmod(tmp.Name(), file.Mode.Perm()); err != nil {
+		return err
+	}
+	if err := workspace.Chtimes(tmp.Name(), time.Now(), file.ModTime); err != nil {
+		return err
+	}
+	return workspace.Rename(tmp.Name(), path)
+}
+
+// collectGarbage removes blobs no longer referenced by any manifest.
+func collectGarbage() error {
+	manifests, err := listManifests()
+	if err != nil {
+		return err
+	}
+	referenced := map[string]bool{}
+	for _, m := range manifests {
+		for _, file := range m.Files {
+			referenced[file.Hash] = true
+		}
+	}
+
+	prefixes, err := os.ReadDir(filepath.Join(dir, "blobs"))
+	if err != nil {
+		return err
+	}
+	for _, prefix := range prefixes {
+		if !prefix.IsDir() {
+			continue
+		}
+		blobs, err := os.ReadDir(filepath.Join(dir, "blobs", prefix.Name()))
+		if err != nil {
+			return err
+		}
+		for _, blob := range blobs {
+			if !referenced[prefix.Name()+blob.Name()] {
+				os.Remove(filepath.Join(dir, "blobs", prefix.Name(), blob.Name()))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package symbols
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// parseGo extracts top-level declarations with full go/ast parsing. Files
+// with syntax errors still yield whatever declarations could be parsed.
+func parseGo(path string, content []byte) []Symbol {
+	fset := token.NewFileSet()
+	file, _ := parser.ParseFile(fset, path, content, parser.SkipObjectResolution)
+	if file == nil {
+		return nil
+	}
+
+	var symbols []Symbol
+	add := func(name string, kind Kind, container string, node ast.Node) {
+		if name == "_" || name == "" {
+			return
+		}
+		symbols = append(symbols, Symbol{
+			Name:      name,
+			Kind:      kind,
+			Container: container,
+			Path:      path,
+			StartLine: fset.Position(node.Pos()).Line,
+			EndLine:   fset.Position(node.End()).Line,
+		})
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				add(d.Name.Name, KindMethod, receiverName(d.Recv.List[0].Type), d)
+			} else {
+				add(d.Name.Name, KindFunc, "", d)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					kind := KindType
+					if _, ok := s.Type.(*ast.InterfaceType); ok {
+						kind = KindInterface
+					}
+					// Single-spec declarations span their "type" keyword
+					var node ast.Node = s
+					if len(d.Specs) == 1 {
+						node = d
+					}
+					add(s.Name.Name, kind, "", node)
+				case *ast.ValueSpec:
+					kind := KindVar
+					if d.Tok == token.CONST {
+						kind = KindConst
+					}
+					for _, name := range s.Names {
+						add(name.Name, kind, "", s)
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	case *ast.IndexListExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
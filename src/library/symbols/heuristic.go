@@ -0,0 +1,194 @@
+package symbols
+
+import (
+	"regexp"
+	"strings"
+)
+
+// pattern is a ctags-style rule: the first capture group of re is the symbol name.
+type pattern struct {
+	re   *regexp.Regexp
+	kind Kind
+	// topLevel patterns only match unindented lines
+	topLevel bool
+	// memberOnly patterns only match inside a container block
+	memberOnly bool
+	// container marks blocks whose functions are reported as methods
+	container bool
+	// hidden patterns track containers without being reported themselves
+	hidden bool
+}
+
+type language struct {
+	patterns []pattern
+	// indentBlocks delimits blocks by indentation instead of braces
+	indentBlocks bool
+	// charQuotes treats single quotes as char literals rather than strings
+	charQuotes bool
+}
+
+const ident = `([A-Za-z_$][\w$]*)`
+
+var typescript = language{patterns: []pattern{
+	{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?class\s+` + ident), kind: KindClass, container: true},
+	{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?interface\s+` + ident), kind: KindInterface},
+	{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?type\s+` + ident + `\s*(?:<[^=]*>)?\s*=`), kind: KindType},
+	{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?(?:const\s+)?enum\s+` + ident), kind: KindType},
+	{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:async\s+)?function\s*\*?\s*` + ident), kind: KindFunc},
+	{re: regexp.MustCompile(`^(?:export\s+)?const\s+` + ident + `\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*(?::[^=]+)?=>|[A-Za-z_$][\w$]*\s*=>)`), kind: KindFunc, topLevel: true},
+	{re: regexp.MustCompile(`^(?:export\s+)?const\s+` + ident), kind: KindConst, topLevel: true},
+	{re: regexp.MustCompile(`^(?:export\s+)?(?:let|var)\s+` + ident), kind: KindVar, topLevel: true},
+	{re: regexp.MustCompile(`^\s+(?:(?:public|private|protected|static|async|readonly|override|abstract|get|set)\s+)*\*?` + ident + `\s*(?:<[^>]*>)?\s*\([^)]*\)?\s*(?::\s*[^{;=]+)?\{?\s*$`), kind: KindMethod, memberOnly: true},
+}}
+
+var python = language{indentBlocks: true, patterns: []pattern{
+	{re: regexp.MustCompile(`^\s*class\s+` + ident), kind: KindClass, container: true},
+	{re: regexp.MustCompile(`^\s*(?:async\s+)?def\s+` + ident), kind: KindFunc},
+	{re: regexp.MustCompile(`^([A-Z][A-Z0-9_]*)\s*(?::[^=]+)?=[^=]`), kind: KindConst, topLevel: true},
+}}
+
+var rust = language{charQuotes: true, patterns: []pattern{
+	{re: regexp.MustCompile(`^\s*impl(?:<[^>]*>)?\s+(?:[\w:<>, &']+\s+for\s+)?(?:[\w]+::)*` + ident), kind: KindType, container: true, hidden: true},
+	{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:unsafe\s+)?trait\s+` + ident), kind: KindInterface, container: true},
+	{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+"[^"]*"\s+)?fn\s+` + ident), kind: KindFunc},
+	{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:struct|enum|union)\s+` + ident), kind: KindType},
+	{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?type\s+` + ident), kind: KindType},
+	{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const|static(?:\s+mut)?)\s+` + ident), kind: KindConst},
+	{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?mod\s+` + ident), kind: KindModule},
+}}
+
+var keywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"return": true, "function": true, "else": true, "do": true, "with": true,
+}
+
+type openContainer struct {
+	name    string
+	endLine int
+}
+
+// parseHeuristic scans line by line with the language's patterns, which is
+// fast and tolerant of syntax the patterns do not understand.
+func parseHeuristic(path string, content []byte, lang language) []Symbol {
+	lines := strings.Split(string(content), "\n")
+	var symbols []Symbol
+	var containers []openContainer
+
+	for i, line := range lines {
+		lineNo := i + 1
+		for len(containers) > 0 && containers[len(containers)-1].endLine < lineNo {
+			containers = containers[:len(containers)-1]
+		}
+
+		indented := len(line) > 0 && (line[0] == ' ' || line[0] == '\t')
+		for _, p := range lang.patterns {
+			if p.topLevel && indented {
+				continue
+			}
+			if p.memberOnly && len(containers) == 0 {
+				continue
+			}
+			m := p.re.FindStringSubmatch(line)
+			if m == nil || keywords[m[1]] {
+				continue
+			}
+
+			endLine := blockEnd(lines, i, lang)
+			kind := p.kind
+			container := ""
+			if len(containers) > 0 {
+				container = containers[len(containers)-1].name
+				if kind == KindFunc {
+					kind = KindMethod
+				}
+			}
+
+			if !p.hidden {
+				symbols = append(symbols, Symbol{
+					Name:      m[1],
+					Kind:      kind,
+					Container: container,
+					Path:      path,
+					StartLine: lineNo,
+					EndLine:   endLine,
+				})
+			}
+			if p.container {
+				containers = append(containers, openContainer{name: m[1], endLine: endLine})
+			}
+			break
+		}
+	}
+	return symbols
+}
+
+// blockEnd returns the 1-based line where the block starting at index start ends.
+func blockEnd(lines []string, start int, lang language) int {
+	if lang.indentBlocks {
+		return indentBlockEnd(lines, start)
+	}
+	return braceBlockEnd(lines, start, lang.charQuotes)
+}
+
+func indentBlockEnd(lines []string, start int) int {
+	indent := indentation(lines[start])
+	end := start
+	for i := start + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if indentation(lines[i]) <= indent {
+			break
+		}
+		end = i
+	}
+	return end + 1
+}
+
+// braceBlockEnd balances braces from the start line, skipping string literals
+// and line comments. Declarations that end before any brace opens are one-liners.
+func braceBlockEnd(lines []string, start int, charQuotes bool) int {
+	depth := 0
+	opened := false
+	for i := start; i < len(lines) && i-start <= 5000; i++ {
+		line := lines[i]
+		var quote byte
+	scan:
+		for j := 0; j < len(line); j++ {
+			c := line[j]
+			switch {
+			case quote != 0:
+				if c == '\\' {
+					j++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '`' || (c == '\'' && !charQuotes):
+				quote = c
+			case c == '\'':
+				// Only char literals such as 'a' or '\n'; Rust lifetimes have no closing quote
+				if end := strings.IndexByte(line[j+1:], '\''); end >= 0 && end <= 2 {
+					j += end + 1
+				}
+			case c == '/' && j+1 < len(line) && line[j+1] == '/':
+				break scan
+			case c == '{':
+				depth++
+				opened = true
+			case c == '}':
+				depth--
+			case c == ';' && !opened:
+				return i + 1
+			}
+		}
+		if opened && depth <= 0 {
+			return i + 1
+		}
+	}
+	// Unbalanced blocks are reported as one-liners rather than spanning the file
+	return start + 1
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
@@ -0,0 +1,380 @@
+package symbols
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/walk"
+	"agent-dev-environment/src/library/workspace"
+)
+
+type Kind string
+
+const (
+	KindFunc      Kind = "func"
+	KindMethod    Kind = "method"
+	KindType      Kind = "type"
+	KindInterface Kind = "interface"
+	KindClass     Kind = "class"
+	KindConst     Kind = "const"
+	KindVar       Kind = "var"
+	KindModule    Kind = "module"
+)
+
+type MatchMode string
+
+const (
+	MatchExact  MatchMode = "exact"
+	MatchPrefix MatchMode = "prefix"
+	MatchFuzzy  MatchMode = "fuzzy"
+)
+
+// maxFileSize skips generated or vendored blobs that would bloat the index.
+const maxFileSize = 2 << 20
+
+// maxIndexes bounds how many indexes are kept, the least recently used being
+// dropped first. Paths inside the workspace share one index per root, so
+// this only matters for unconfined paths.
+const maxIndexes = 8
+
+var walkOptions = walk.Options{RespectIgnore: true}
+
+type Symbol struct {
+	Name      string
+	Kind      Kind
+	Container string // Receiver, class or trait the symbol belongs to
+	Path      string
+	StartLine int
+	EndLine   int
+}
+
+type Query struct {
+	Name  string
+	Mode  MatchMode
+	Kinds []Kind
+	// PathPrefix restricts results to a subtree of the index root
+	PathPrefix string
+	Limit      int
+}
+
+// Supported reports whether symbols can be extracted from the file at path.
+func Supported(path string) bool {
+	switch filepath.Ext(path) {
+	case ".go", ".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs", ".py", ".rs":
+		return true
+	}
+	return false
+}
+
+// Parse extracts the symbols declared in content, choosing a parser by file extension.
+func Parse(path string, content []byte) []Symbol {
+	switch filepath.Ext(path) {
+	case ".go":
+		return parseGo(path, content)
+	case ".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs":
+		return parseHeuristic(path, content, typescript)
+	case ".py":
+		return parseHeuristic(path, content, python)
+	case ".rs":
+		return parseHeuristic(path, content, rust)
+	}
+	return nil
+}
+
+// Index maps every supported file below root to its symbols.
+type Index struct {
+	root     string
+	filter   *walk.Filter
+	built    sync.Once
+	lastUsed time.Time // Guarded by registryMu
+
+	mu    sync.RWMutex
+	files map[string][]Symbol
+}
+
+var (
+	registryMu sync.Mutex
+	indexes    = map[string]*Index{}
+	subscribe  sync.Once
+	updates    *events.Async
+)
+
+// For returns an index covering path, which callers narrow down with
+// Query.PathPrefix. Paths inside a workspace root share the index of that
+// root; others reuse the index of an enclosing directory when there is one.
+// The index is built on first use and then kept up to date from filesystem
+// events published by the API, which are handled in the background but
+// always before For returns.
+func For(path string) *Index {
+	subscribe.Do(func() { updates = events.SubscribeAsync(handleEvent) })
+	updates.Wait()
+
+	registryMu.Lock()
+	root := rootFor(path)
+	idx := lookup(root)
+	if idx == nil {
+		idx = &Index{root: root, filter: walk.NewFilter(root, walkOptions), files: map[string][]Symbol{}}
+		// The new index covers everything those below it did
+		for dir := range indexes {
+			if within(dir, root) {
+				delete(indexes, dir)
+			}
+		}
+		indexes[root] = idx
+		evict()
+	}
+	idx.lastUsed = time.Now()
+	registryMu.Unlock()
+
+	idx.built.Do(idx.build)
+	return idx
+}
+
+// rootFor returns the workspace root path lies within, or path itself when
+// it lies in none.
+func rootFor(path string) string {
+	for _, root := range workspace.Roots() {
+		if within(path, root) {
+			return root
+		}
+	}
+	return path
+}
+
+// evict drops the least recently used indexes beyond maxIndexes. Callers
+// hold registryMu.
+func evict() {
+	for len(indexes) > maxIndexes {
+		var oldest *Index
+		for _, idx := range indexes {
+			if oldest == nil || idx.lastUsed.Before(oldest.lastUsed) {
+				oldest = idx
+			}
+		}
+		delete(indexes, oldest.root)
+	}
+}
+
+func lookup(root string) *Index {
+	for dir := root; ; dir = filepath.Dir(dir) {
+		if idx, ok := indexes[dir]; ok {
+			return idx
+		}
+		if dir == filepath.Dir(dir) {
+			return nil
+		}
+	}
+}
+
+func (idx *Index) build() {
+	idx.indexTree(idx.root)
+}
+
+func (idx *Index) indexTree(path string) {
+	walk.Walk(path, walkOptions, func(path string, d fs.DirEntry) error {
+		if Supported(path) {
+			idx.indexFile(path)
+		}
+		return nil
+	})
+}
+
+func (idx *Index) indexFile(path string) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxFileSize {
+		idx.remove(path)
+		return
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		idx.remove(path)
+		return
+	}
+
+	symbols := Parse(path, content)
+	idx.mu.Lock()
+	idx.files[path] = symbols
+	idx.mu.Unlock()
+}
+
+// remove drops path and, if it was a directory, everything below it.
+func (idx *Index) remove(path string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	delete(idx.files, path)
+	prefix := path + string(filepath.Separator)
+	for file := range idx.files {
+		if strings.HasPrefix(file, prefix) {
+			delete(idx.files, file)
+		}
+	}
+}
+
+func (idx *Index) update(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		idx.remove(path)
+		return
+	}
+	if !idx.filter.Included(path, info.IsDir()) {
+		return
+	}
+	if info.IsDir() {
+		idx.indexTree(path)
+	} else if Supported(path) {
+		idx.indexFile(path)
+	}
+}
+
+func (idx *Index) contains(path string) bool {
+	return within(path, idx.root)
+}
+
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+func handleEvent(event events.Event) {
+	registryMu.Lock()
+	var affected []*Index
+	for _, idx := range indexes {
+		affected = append(affected, idx)
+	}
+	registryMu.Unlock()
+
+	for _, idx := range affected {
+		if rootRemoved(idx, event) {
+			registryMu.Lock()
+			delete(indexes, idx.root)
+			registryMu.Unlock()
+			continue
+		}
+		if walk.IgnoreFile(event.Path) || event.Op == events.Remove || event.Op == events.Rename {
+			// Ignore rules may have changed or moved with a directory
+			idx.filter.Reset()
+		}
+		if event.OldPath != "" && idx.contains(event.OldPath) {
+			idx.remove(event.OldPath)
+		}
+		if !idx.contains(event.Path) {
+			continue
+		}
+		if event.Op == events.Remove {
+			idx.remove(event.Path)
+		} else {
+			idx.update(event.Path)
+		}
+	}
+}
+
+// rootRemoved reports whether the event deleted or moved away the index root.
+func rootRemoved(idx *Index, event events.Event) bool {
+	gone := event.Path
+	if event.Op == events.Rename {
+		gone = event.OldPath
+	} else if event.Op != events.Remove {
+		return false
+	}
+	return idx.root == gone || strings.HasPrefix(idx.root, gone+string(filepath.Separator))
+}
+
+// Search returns the symbols matching q, best matches first, and the total
+// number of matches before q.Limit was applied.
+func (idx *Index) Search(q Query) ([]Symbol, int) {
+	kinds := map[Kind]bool{}
+	for _, kind := range q.Kinds {
+		kinds[kind] = true
+	}
+
+	type scored struct {
+		symbol Symbol
+		score  int
+	}
+	var matches []scored
+
+	idx.mu.RLock()
+	for path, symbols := range idx.files {
+		if q.PathPrefix != "" && path != q.PathPrefix && !strings.HasPrefix(path, q.PathPrefix+string(filepath.Separator)) {
+			continue
+		}
+		for _, symbol := range symbols {
+			if len(kinds) > 0 && !kinds[symbol.Kind] {
+				continue
+			}
+			if score, ok := matchScore(symbol.Name, q.Name, q.Mode); ok {
+				matches = append(matches, scored{symbol: symbol, score: score})
+			}
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score < b.score
+		}
+		if len(a.symbol.Name) != len(b.symbol.Name) {
+			return len(a.symbol.Name) < len(b.symbol.Name)
+		}
+		if a.symbol.Path != b.symbol.Path {
+			return a.symbol.Path < b.symbol.Path
+		}
+		return a.symbol.StartLine < b.symbol.StartLine
+	})
+
+	total := len(matches)
+	if q.Limit > 0 && len(matches) > q.Limit {
+		matches = matches[:q.Limit]
+	}
+
+	result := make([]Symbol, len(matches))
+	for i, match := range matches {
+		result[i] = match.symbol
+	}
+	return result, total
+}
+
+// matchScore returns a rank for name against query, lower being better.
+// Exact matches rank first, then prefixes, then fuzzy subsequences ordered
+// by how spread out the matched characters are. Case-insensitive matches
+// rank just below their case-sensitive equivalent.
+func matchScore(name, query string, mode MatchMode) (int, bool) {
+	lowerName, lowerQuery := strings.ToLower(name), strings.ToLower(query)
+
+	switch {
+	case name == query:
+		return 0, true
+	case mode == MatchExact:
+		return 0, false
+	case lowerName == lowerQuery:
+		return 1, true
+	case strings.HasPrefix(name, query):
+		return 2, true
+	case strings.HasPrefix(lowerName, lowerQuery):
+		return 3, true
+	case mode == MatchPrefix:
+		return 0, false
+	}
+
+	// Fuzzy: every query character must appear in order
+	gaps := 0
+	pos := 0
+	for i, c := range lowerQuery {
+		found := strings.IndexRune(lowerName[pos:], c)
+		if found < 0 {
+			return 0, false
+		}
+		if i > 0 {
+			gaps += found
+		}
+		pos += found + len(string(c))
+	}
+	return 4 + gaps, true
+}
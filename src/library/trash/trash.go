@@ -0,0 +1,391 @@
+package trash
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/logger"
+	"agent-dev-environment/src/library/workspace"
+)
+
+const (
+	itemName = "item"
+	metaName = "meta.json"
+)
+
+// BinName is the directory at the top of each workspace root that holds
+// what is deleted below it, so deleting is a rename on the same filesystem.
+const BinName = ".agent-trash"
+
+// ErrNotFound is returned for trash IDs that do not exist.
+var ErrNotFound = errors.New("trash entry not found")
+
+// Entry describes one deleted file or directory.
+type Entry struct {
+	ID           string    `json:"id"`
+	OriginalPath string    `json:"original_path"`
+	DeletedAt    time.Time `json:"deleted_at"`
+	RequestID    string    `json:"request_id,omitempty"`
+	IsDir        bool      `json:"is_dir"`
+	Size         int64     `json:"size"`
+}
+
+// bin is a directory holding trash entries for the paths below root.
+type bin struct {
+	root string // Empty for the bin in the data directory, which takes the rest
+	dir  string
+}
+
+var (
+	mu       sync.Mutex
+	bins     []bin // Deepest root first, the data directory's bin last
+	maxBytes int64
+	maxAge   time.Duration
+)
+
+// Init keeps trashed items in a bin at the top of each of roots, or of the
+// working directory when there are none, and those deleted elsewhere in
+// dataDir. Entries older than age, and the oldest
+// entries once the trash exceeds size bytes, are purged.
+func Init(dataDir string, roots []string, size, age string) {
+	var err error
+	maxBytes, err = strconv.ParseInt(size, 10, 64)
+	if err != nil || maxBytes < 0 {
+		panic(fmt.Sprintf("invalid TRASH_MAX_BYTES: %q. Must be a number of bytes", size))
+	}
+	maxAge, err = time.ParseDuration(age)
+	if err != nil || maxAge < 0 {
+		panic(fmt.Sprintf("invalid TRASH_MAX_AGE: %q. Must be a duration such as '168h'", age))
+	}
+
+	dataBin, err := filepath.Abs(filepath.Join(dataDir, "trash"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid DATA_DIR: %v", err))
+	}
+	if err := os.MkdirAll(dataBin, 0o700); err != nil {
+		panic(fmt.Sprintf("could not create trash directory: %v", err))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	bins = nil
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	for _, root := range roots {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			panic(fmt.Sprintf("invalid trash root %q: %v", root, err))
+		}
+		// Created by the first delete below the root
+		bins = append(bins, bin{root: absRoot, dir: filepath.Join(absRoot, BinName)})
+	}
+	sort.Slice(bins, func(i, j int) bool { return len(bins[i].root) > len(bins[j].root) })
+	bins = append(bins, bin{dir: dataBin})
+	for _, b := range bins {
+		// Items are moved in and out of bins outside the workspace too
+		workspace.Exempt(b.dir)
+	}
+	prune()
+}
+
+// createBin creates a bin in a workspace root. Its ignore file keeps it out
+// of git, searches and every walk that respects ignore rules.
+func createBin(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*\n"), 0o600)
+}
+
+// binFor returns the bin for path: the one at the top of the deepest root
+// containing it, or the data directory's.
+func binFor(path string) bin {
+	for _, b := range bins[:len(bins)-1] {
+		if isBelow(path, b.root) {
+			return b
+		}
+	}
+	return bins[len(bins)-1]
+}
+
+// Put moves path into the trash and returns its entry.
+func Put(path, requestID string) (Entry, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{
+		ID:           newID(),
+		OriginalPath: absPath,
+		DeletedAt:    time.Now().UTC(),
+		RequestID:    requestID,
+		IsDir:        info.IsDir(),
+		Size:         fsutil.DiskSize(absPath),
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	b := binFor(absPath)
+	if _, err := os.Stat(b.dir); err != nil && b.root != "" {
+		if err := createBin(b.dir); err != nil {
+			// Copied to the data directory instead, which is slower
+			logger.Error("Failed to create trash directory", "path", b.dir, "error", err)
+			b = bins[len(bins)-1]
+		}
+	}
+	entryDir := filepath.Join(b.dir, entry.ID)
+	if err := os.Mkdir(entryDir, 0o700); err != nil {
+		return Entry{}, err
+	}
+	if err := writeMeta(entryDir, entry); err != nil {
+		os.RemoveAll(entryDir)
+		return Entry{}, err
+	}
+	if _, err := fsutil.Move(absPath, filepath.Join(entryDir, itemName), false); err != nil {
+		os.RemoveAll(entryDir)
+		return Entry{}, err
+	}
+
+	prune()
+	return entry, nil
+}
+
+// List returns the entries in the trash, most recently deleted first.
+func List() ([]Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	prune()
+	return list()
+}
+
+// Get returns the entry with the given ID.
+func Get(id string) (Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return load(id)
+}
+
+// Item returns where the content of the entry with the given ID is kept.
+func Item(id string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, err := load(id); err != nil {
+		return "", err
+	}
+	return filepath.Join(entryDir(id), itemName), nil
+}
+
+// Restore moves the entry back to its original path, or to destination when
+// set, and returns the restored path. Missing parent directories are created.
+func Restore(id, destination string, overwrite bool) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entry, err := load(id)
+	if err != nil {
+		return "", err
+	}
+	if destination == "" {
+		destination = entry.OriginalPath
+	}
+
+	if err := workspace.MkdirAll(filepath.Dir(destination), 0o755); err != nil {
+		return "", err
+	}
+	dir := entryDir(entry.ID)
+	if _, err := fsutil.Move(filepath.Join(dir, itemName), destination, overwrite); err != nil {
+		return "", err
+	}
+	os.RemoveAll(dir)
+	return destination, nil
+}
+
+// Purge permanently deletes the given entries, or every entry when ids is
+// empty, and returns how many were deleted and the bytes freed.
+func Purge(ids []string) (int, int64, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var entries []Entry
+	if len(ids) == 0 {
+		all, err := list()
+		if err != nil {
+			return 0, 0, err
+		}
+		entries = all
+	} else {
+		for _, id := range ids {
+			entry, err := load(id)
+			if err != nil {
+				return 0, 0, err
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	purged := 0
+	var freed int64
+	for _, entry := range entries {
+		if err := os.RemoveAll(entryDir(entry.ID)); err != nil {
+			return purged, freed, err
+		}
+		purged++
+		freed += entry.Size
+	}
+	return purged, freed, nil
+}
+
+// Contains reports whether path is a trash bin, lies inside one or encloses one.
+func Contains(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, b := range bins {
+		if absPath == b.dir || isBelow(absPath, b.dir) || isBelow(b.dir, absPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// Within reports whether path is a trash bin or lies inside one. Bins are
+// not meant to be listed, read or changed other than through the trash.
+func Within(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, b := range bins {
+		if absPath == b.dir || isBelow(absPath, b.dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBelow(path, dir string) bool {
+	return strings.HasPrefix(path, dir+string(filepath.Separator)) || (dir == string(filepath.Separator) && path != dir)
+}
+
+func list() ([]Entry, error) {
+	entries := []Entry{}
+	for _, b := range bins {
+		names, err := os.ReadDir(b.dir)
+		if err != nil {
+			if b.root != "" && os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, name := range names {
+			entry, err := loadFrom(b.dir, name.Name())
+			if err != nil {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].DeletedAt.After(entries[j].DeletedAt) })
+	return entries, nil
+}
+
+// prune applies the retention limits. Callers hold mu.
+func prune() {
+	entries, err := list()
+	if err != nil {
+		return
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	// Oldest first. The newest entry is kept even if it alone exceeds the
+	// size limit, so the delete that just happened can still be undone.
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		expired := maxAge > 0 && time.Since(entry.DeletedAt) > maxAge
+		if !expired && (total <= maxBytes || i == 0) {
+			break
+		}
+		if err := os.RemoveAll(entryDir(entry.ID)); err != nil {
+			logger.Error("Failed to purge trash entry", "id", entry.ID, "error", err)
+			return
+		}
+		total -= entry.Size
+	}
+}
+
+// load finds the entry with the given ID in any bin.
+func load(id string) (Entry, error) {
+	// IDs are generated by newID; anything else could escape the trash
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return Entry{}, ErrNotFound
+	}
+	for _, b := range bins {
+		if entry, err := loadFrom(b.dir, id); err == nil {
+			return entry, nil
+		}
+	}
+	return Entry{}, ErrNotFound
+}
+
+func loadFrom(binDir, id string) (Entry, error) {
+	data, err := os.ReadFile(filepath.Join(binDir, id, metaName))
+	if err != nil {
+		return Entry{}, ErrNotFound
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, ErrNotFound
+	}
+	return entry, nil
+}
+
+// entryDir returns the directory holding the entry with the given ID, which
+// load found.
+func entryDir(id string) string {
+	for _, b := range bins {
+		dir := filepath.Join(b.dir, id)
+		if _, err := os.Lstat(dir); err == nil {
+			return dir
+		}
+	}
+	return filepath.Join(bins[len(bins)-1].dir, id)
+}
+
+func writeMeta(entryDir string, entry Entry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(entryDir, metaName), data, 0o600)
+}
+
+// newID sorts by deletion time and stays unique within the same instant.
+func newID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
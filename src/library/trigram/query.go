@@ -0,0 +1,51 @@
+package trigram
+
+import (
+	"regexp/syntax"
+	"strings"
+)
+
+// requiredLiterals returns strings that every match of the pattern must
+// contain. An empty result means the pattern cannot be narrowed down, for
+// example because it is an alternation or only uses character classes.
+func requiredLiterals(pattern string) []string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	return literals(re.Simplify())
+}
+
+func literals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+	case syntax.OpCapture, syntax.OpPlus:
+		return literals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return literals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var result []string
+		var run strings.Builder
+		flush := func() {
+			if run.Len() > 0 {
+				result = append(result, run.String())
+				run.Reset()
+			}
+		}
+		// Adjacent literals form one longer run, which yields more trigrams
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral {
+				run.WriteString(string(sub.Rune))
+				continue
+			}
+			flush()
+			result = append(result, literals(sub)...)
+		}
+		flush()
+		return result
+	}
+	return nil
+}
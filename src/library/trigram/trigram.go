@@ -0,0 +1,586 @@
+package trigram
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/fsutil"
+	"agent-dev-environment/src/library/logger"
+	"agent-dev-environment/src/library/walk"
+	"agent-dev-environment/src/library/watch"
+)
+
+const (
+	ModeOff     = "off"
+	ModeStartup = "startup"
+	ModeLazy    = "lazy"
+)
+
+const (
+	StateDisabled = "disabled"
+	StatePending  = "pending"
+	StateBuilding = "building"
+	StateReady    = "ready"
+	StateFailed   = "failed"
+)
+
+// maxIndexedSize is the largest file whose trigrams are stored. Larger files
+// are tracked but always returned as candidates.
+const maxIndexedSize = 4 << 20
+
+// refreshInterval bounds how old the index may be when a query narrows
+// with it and the tree cannot be watched. Queries re-stat the tree first
+// once it is older.
+const refreshInterval = time.Second
+
+var walkOptions = walk.Options{RespectIgnore: true}
+
+type file struct {
+	path    string
+	size    int64
+	modTime time.Time
+	indexed bool
+	live    bool
+}
+
+// Index maps trigrams of lowercased file content to the files containing them.
+type Index struct {
+	root    string
+	filter  *walk.Filter
+	updates *events.Async
+	watcher *watch.Watcher // Nil when the tree cannot be watched
+
+	mu       sync.RWMutex
+	files    []file
+	byPath   map[string]uint32
+	postings map[uint32][]uint32
+	dead     int
+
+	state         string
+	err           error
+	builtAt       time.Time
+	buildDuration time.Duration
+	lastRefresh   time.Time
+	stale         bool
+	refreshMu     sync.Mutex
+}
+
+type Stats struct {
+	Enabled         bool
+	State           string
+	Root            string
+	Files           int
+	Trigrams        int
+	Postings        int
+	MemoryBytes     int64
+	BuildDuration   time.Duration
+	BuiltAt         time.Time
+	LastRefreshedAt time.Time
+	Error           string
+}
+
+var (
+	active  *Index
+	mode    = ModeOff
+	trigger sync.Once
+)
+
+// Init configures the process-wide index. "startup" builds it in the
+// background right away, "lazy" on the first search, and "off" disables it.
+func Init(indexMode string, root string) {
+	switch strings.ToLower(indexMode) {
+	case ModeOff:
+		return
+	case ModeStartup, ModeLazy:
+		mode = strings.ToLower(indexMode)
+	default:
+		panic(fmt.Sprintf("invalid SEARCH_INDEX: %q. Must be 'off', 'startup' or 'lazy'", indexMode))
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		panic(fmt.Sprintf("invalid SEARCH_INDEX_ROOT: %v", err))
+	}
+
+	active = &Index{
+		root:     absRoot,
+		filter:   walk.NewFilter(absRoot, walkOptions),
+		byPath:   map[string]uint32{},
+		postings: map[uint32][]uint32{},
+		state:    StatePending,
+	}
+	active.updates = events.SubscribeAsync(active.handleEvent)
+
+	if mode == ModeStartup {
+		trigger.Do(func() { go active.build() })
+	}
+}
+
+// Candidates returns the files below path that may contain a match for re.
+// The second result is false when the index cannot answer, in which case
+// the caller must scan the tree itself.
+func Candidates(path string, re *regexp.Regexp) ([]string, bool) {
+	if active == nil {
+		return nil, false
+	}
+	trigger.Do(func() { go active.build() })
+
+	absPath, err := filepath.Abs(path)
+	if err != nil || !active.covers(absPath) {
+		return nil, false
+	}
+
+	grams := queryTrigrams(re.String())
+	if len(grams) == 0 {
+		return nil, false
+	}
+
+	active.mu.RLock()
+	ready := active.state == StateReady
+	active.mu.RUnlock()
+	if !ready {
+		return nil, false
+	}
+
+	active.updates.Wait()
+	active.refresh()
+	return active.candidates(absPath, grams), true
+}
+
+// Covers reports whether the index can serve searches below path.
+func Covers(path string) bool {
+	if active == nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	return err == nil && active.covers(absPath)
+}
+
+// MarkStale forces the next search to re-stat the tree, for callers that
+// know files may have changed and cannot wait for the watcher to see it.
+func MarkStale() {
+	if active == nil {
+		return
+	}
+	active.mu.Lock()
+	active.stale = true
+	active.mu.Unlock()
+}
+
+// CurrentStats describes the process-wide index.
+func CurrentStats() Stats {
+	if active == nil {
+		return Stats{State: StateDisabled}
+	}
+	return active.stats()
+}
+
+func (idx *Index) covers(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	return idx.filter.Included(path, true)
+}
+
+func (idx *Index) build() {
+	idx.mu.Lock()
+	idx.state = StateBuilding
+	idx.mu.Unlock()
+
+	// Watched first, so files changed while the tree is read are not missed
+	watcher, err := watch.New(idx.root, watch.Options{RespectIgnore: walkOptions.RespectIgnore})
+	if err != nil {
+		logger.Error("Search index cannot watch for changes; re-stating the tree before searches instead", "root", idx.root, "error", err)
+	} else {
+		idx.mu.Lock()
+		idx.watcher = watcher
+		idx.mu.Unlock()
+		go idx.follow(watcher)
+	}
+
+	start := time.Now()
+	err = walk.Walk(idx.root, walkOptions, func(path string, d fs.DirEntry) error {
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		idx.add(path, info)
+		return nil
+	})
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if err != nil {
+		if idx.watcher != nil {
+			idx.watcher.Close()
+			idx.watcher = nil
+		}
+		idx.state = StateFailed
+		idx.err = err
+		logger.Error("Search index build failed", "error", err)
+		return
+	}
+	idx.state = StateReady
+	idx.builtAt = time.Now()
+	idx.lastRefresh = idx.builtAt
+	idx.buildDuration = time.Since(start)
+	logger.Printf("Search index built for %s: %d files in %s", idx.root, len(idx.byPath), idx.buildDuration)
+}
+
+// add indexes the file at path, replacing any previous version of it.
+func (idx *Index) add(path string, info fs.FileInfo) {
+	entry := file{path: path, size: info.Size(), modTime: info.ModTime(), live: true}
+
+	var grams []uint32
+	if info.Size() <= maxIndexedSize {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			// Whatever was indexed for it no longer holds
+			idx.mu.Lock()
+			idx.removeFileLocked(path)
+			idx.mu.Unlock()
+			return
+		}
+		entry.indexed = true
+		// Binary files are tracked without trigrams since search skips them
+		if !fsutil.IsBinary(content) {
+			grams = extract(content)
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeFileLocked(path)
+	id := uint32(len(idx.files))
+	idx.files = append(idx.files, entry)
+	idx.byPath[path] = id
+	for _, g := range grams {
+		idx.postings[g] = append(idx.postings[g], id)
+	}
+}
+
+// removeLocked drops path and everything below it.
+func (idx *Index) removeLocked(path string) {
+	idx.removeFileLocked(path)
+	prefix := path + string(filepath.Separator)
+	for p := range idx.byPath {
+		if strings.HasPrefix(p, prefix) {
+			idx.removeFileLocked(p)
+		}
+	}
+}
+
+func (idx *Index) removeFileLocked(path string) {
+	id, ok := idx.byPath[path]
+	if !ok {
+		return
+	}
+	idx.files[id].live = false
+	delete(idx.byPath, path)
+	idx.dead++
+
+	if idx.dead > 1000 && idx.dead > len(idx.files)/4 {
+		idx.compactLocked()
+	}
+}
+
+// compactLocked renumbers live files and rewrites the posting lists without
+// the ids of removed files.
+func (idx *Index) compactLocked() {
+	remap := make([]uint32, len(idx.files))
+	var files []file
+	for id, f := range idx.files {
+		if f.live {
+			remap[id] = uint32(len(files))
+			idx.byPath[f.path] = uint32(len(files))
+			files = append(files, f)
+		}
+	}
+
+	for g, ids := range idx.postings {
+		kept := ids[:0]
+		for _, id := range ids {
+			if idx.files[id].live {
+				kept = append(kept, remap[id])
+			}
+		}
+		if len(kept) == 0 {
+			delete(idx.postings, g)
+		} else {
+			idx.postings[g] = kept
+		}
+	}
+
+	idx.files = files
+	idx.dead = 0
+}
+
+func (idx *Index) update(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		idx.mu.Lock()
+		idx.removeLocked(path)
+		idx.mu.Unlock()
+		return
+	}
+	if !idx.filter.Included(path, info.IsDir()) {
+		return
+	}
+
+	if !info.IsDir() {
+		if info.Mode().IsRegular() {
+			idx.add(path, info)
+		}
+		return
+	}
+	walk.Walk(path, walkOptions, func(child string, d fs.DirEntry) error {
+		if childInfo, err := d.Info(); err == nil {
+			idx.add(child, childInfo)
+		}
+		return nil
+	})
+}
+
+func (idx *Index) handleEvent(event events.Event) {
+	idx.mu.RLock()
+	ready := idx.state == StateReady || idx.state == StateBuilding
+	idx.mu.RUnlock()
+	if !ready {
+		return
+	}
+
+	if walk.IgnoreFile(event.Path) || event.Op == events.Remove || event.Op == events.Rename {
+		// Ignore rules may have changed or moved with a directory
+		idx.filter.Reset()
+	}
+	if event.OldPath != "" && idx.contains(event.OldPath) {
+		idx.mu.Lock()
+		idx.removeLocked(event.OldPath)
+		idx.mu.Unlock()
+	}
+	if !idx.contains(event.Path) {
+		return
+	}
+	if event.Op == events.Remove {
+		idx.mu.Lock()
+		idx.removeLocked(event.Path)
+		idx.mu.Unlock()
+		return
+	}
+	idx.update(event.Path)
+}
+
+// follow applies the changes the watcher sees, including those made
+// outside the API.
+func (idx *Index) follow(watcher *watch.Watcher) {
+	for batch := range watcher.Events() {
+		for _, ev := range batch {
+			if walk.IgnoreFile(ev.Path) || ev.Op == events.Remove || ev.Op == events.Rename {
+				idx.filter.Reset()
+			}
+			switch ev.Op {
+			case watch.Overflow:
+				// Changes were dropped, so only a re-stat can tell what they were
+				idx.mu.Lock()
+				idx.stale = true
+				idx.mu.Unlock()
+			case events.Remove, events.Rename:
+				// A rename is reported for the old path; the new one is created
+				idx.mu.Lock()
+				idx.removeLocked(ev.Path)
+				idx.mu.Unlock()
+			default:
+				idx.update(ev.Path)
+			}
+		}
+	}
+}
+
+func (idx *Index) contains(path string) bool {
+	return path == idx.root || strings.HasPrefix(path, idx.root+string(filepath.Separator))
+}
+
+// refresh re-stats the tree before a query narrows with the index, when it
+// was marked stale or, without a watcher, is older than refreshInterval.
+func (idx *Index) refresh() {
+	idx.mu.Lock()
+	stale := idx.stale || (idx.watcher == nil && time.Since(idx.lastRefresh) >= refreshInterval)
+	idx.stale = false
+	idx.mu.Unlock()
+
+	if stale {
+		idx.rescan()
+	}
+}
+
+// rescan re-stats the tree and reindexes files whose size or modification
+// time changed.
+func (idx *Index) rescan() {
+	idx.refreshMu.Lock()
+	defer idx.refreshMu.Unlock()
+
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	var changed []string
+	walk.Walk(idx.root, walkOptions, func(path string, d fs.DirEntry) error {
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		idx.mu.RLock()
+		id, ok := idx.byPath[path]
+		stale := !ok || idx.files[id].size != info.Size() || !idx.files[id].modTime.Equal(info.ModTime())
+		idx.mu.RUnlock()
+
+		mu.Lock()
+		seen[path] = true
+		if stale {
+			changed = append(changed, path)
+		}
+		mu.Unlock()
+		return nil
+	})
+
+	idx.mu.Lock()
+	for path := range idx.byPath {
+		if !seen[path] {
+			idx.removeFileLocked(path)
+		}
+	}
+	idx.mu.Unlock()
+
+	for _, path := range changed {
+		if info, err := os.Stat(path); err == nil {
+			idx.add(path, info)
+		}
+	}
+
+	idx.mu.Lock()
+	idx.lastRefresh = time.Now()
+	idx.mu.Unlock()
+}
+
+func (idx *Index) candidates(path string, grams []uint32) []string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	// Intersect starting from the rarest trigram
+	sort.Slice(grams, func(i, j int) bool { return len(idx.postings[grams[i]]) < len(idx.postings[grams[j]]) })
+
+	set := map[uint32]bool{}
+	for _, id := range idx.postings[grams[0]] {
+		set[id] = true
+	}
+	for _, g := range grams[1:] {
+		if len(set) == 0 {
+			break
+		}
+		next := map[uint32]bool{}
+		for _, id := range idx.postings[g] {
+			if set[id] {
+				next[id] = true
+			}
+		}
+		set = next
+	}
+
+	prefix := path + string(filepath.Separator)
+	var result []string
+	for id, f := range idx.files {
+		if !f.live || (f.indexed && !set[uint32(id)]) {
+			continue
+		}
+		if f.path == path || strings.HasPrefix(f.path, prefix) {
+			result = append(result, f.path)
+		}
+	}
+	return result
+}
+
+func (idx *Index) stats() Stats {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	postings := 0
+	for _, ids := range idx.postings {
+		postings += len(ids)
+	}
+	var pathBytes int64
+	for _, f := range idx.files {
+		pathBytes += int64(len(f.path))
+	}
+
+	// Rough estimate: posting ids, map buckets per trigram and file records
+	memory := int64(postings)*4 + int64(len(idx.postings))*48 + int64(len(idx.files))*80 + pathBytes*2
+
+	stats := Stats{
+		Enabled:         true,
+		State:           idx.state,
+		Root:            idx.root,
+		Files:           len(idx.byPath),
+		Trigrams:        len(idx.postings),
+		Postings:        postings,
+		MemoryBytes:     memory,
+		BuildDuration:   idx.buildDuration,
+		BuiltAt:         idx.builtAt,
+		LastRefreshedAt: idx.lastRefresh,
+	}
+	if idx.err != nil {
+		stats.Error = idx.err.Error()
+	}
+	return stats
+}
+
+// extract returns the distinct trigrams of the lowercased content, skipping
+// those spanning a newline since searches match line by line.
+func extract(content []byte) []uint32 {
+	lower := bytes.ToLower(content)
+	seen := map[uint32]struct{}{}
+	for i := 0; i+2 < len(lower); i++ {
+		if lower[i] == '\n' || lower[i+1] == '\n' || lower[i+2] == '\n' {
+			continue
+		}
+		seen[pack(lower[i], lower[i+1], lower[i+2])] = struct{}{}
+	}
+
+	grams := make([]uint32, 0, len(seen))
+	for g := range seen {
+		grams = append(grams, g)
+	}
+	return grams
+}
+
+func queryTrigrams(pattern string) []uint32 {
+	seen := map[uint32]bool{}
+	var grams []uint32
+	for _, literal := range requiredLiterals(pattern) {
+		lower := bytes.ToLower([]byte(literal))
+		for i := 0; i+2 < len(lower); i++ {
+			if bytes.IndexByte(lower[i:i+3], '\n') >= 0 {
+				continue
+			}
+			g := pack(lower[i], lower[i+1], lower[i+2])
+			if !seen[g] {
+				seen[g] = true
+				grams = append(grams, g)
+			}
+		}
+	}
+	return grams
+}
+
+func pack(a, b, c byte) uint32 {
+	return uint32(a)<<16 | uint32(b)<<8 | uint32(c)
+}
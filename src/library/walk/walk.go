@@ -0,0 +1,267 @@
+package walk
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"agent-dev-environment/src/library/gitignore"
+	"agent-dev-environment/src/library/trash"
+)
+
+// Options controls which entries Walk visits.
+type Options struct {
+	// RespectIgnore skips entries excluded by .gitignore/.ignore files.
+	RespectIgnore bool
+	// Hidden includes entries whose name starts with a dot.
+	Hidden bool
+	// Workers bounds the number of directories read and of calls to fn
+	// running at once. Defaults to GOMAXPROCS.
+	Workers int
+	// Dirs also calls fn for every directory below root, before its contents.
+	Dirs bool
+}
+
+// FileFunc is called for every regular file found, and with Options.Dirs for
+// every directory. It may be called from up to Options.Workers goroutines at
+// once. Returning an error stops the walk.
+type FileFunc func(path string, d fs.DirEntry) error
+
+// SkipAll can be returned by a FileFunc to stop the walk without an error.
+var SkipAll = errors.New("skip all")
+
+// Walk visits every regular file below root in parallel. The ".git" directory
+// and trash bins are never entered. Paths are built by appending names to root as given, so a
+// relative root yields relative paths. If root is a file, fn is called once
+// for it regardless of ignore rules.
+func Walk(root string, opts Options, fn FileFunc) error {
+	info, err := os.Lstat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return fn(root, fs.FileInfoToDirEntry(info))
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var matcher *gitignore.Matcher
+	if opts.RespectIgnore {
+		matcher = gitignore.New(root)
+	}
+
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	w := &walker{root: abs, opts: opts, fn: fn, sem: make(chan struct{}, workers)}
+	w.wg.Add(1)
+	go w.dir(root, abs, matcher)
+	w.wg.Wait()
+
+	if errors.Is(w.err, SkipAll) {
+		return nil
+	}
+	return w.err
+}
+
+type walker struct {
+	root string
+	opts Options
+	fn   FileFunc
+	sem  chan struct{}
+	wg   sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+func (w *walker) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *walker) stopped() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err != nil
+}
+
+func (w *walker) dir(path, abs string, matcher *gitignore.Matcher) {
+	defer w.wg.Done()
+	if w.stopped() {
+		return
+	}
+
+	w.sem <- struct{}{}
+	entries, err := os.ReadDir(abs)
+	<-w.sem
+	if err != nil {
+		// Unreadable directories are skipped, like ripgrep does
+		return
+	}
+
+	// The root's own ignore files were already loaded by gitignore.New
+	if matcher != nil && abs != w.root {
+		matcher = matcher.Descend(abs)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == ".git" || (!w.opts.Hidden && strings.HasPrefix(name, ".")) {
+			continue
+		}
+
+		child := join(path, name)
+		childAbs := filepath.Join(abs, name)
+		if matcher != nil && matcher.Ignored(childAbs, entry.IsDir()) {
+			continue
+		}
+		if entry.IsDir() && trash.Within(childAbs) {
+			continue
+		}
+
+		if entry.IsDir() {
+			if w.opts.Dirs {
+				if err := w.call(child, entry); err != nil {
+					w.fail(err)
+					return
+				}
+			}
+			w.wg.Add(1)
+			go w.dir(child, childAbs, matcher)
+			continue
+		}
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		if err := w.call(child, entry); err != nil {
+			w.fail(err)
+			return
+		}
+	}
+}
+
+// call runs fn under the same limit as directory reads, so callbacks that
+// open files cannot run out of file descriptors however wide the tree is.
+func (w *walker) call(path string, d fs.DirEntry) error {
+	w.sem <- struct{}{}
+	defer func() { <-w.sem }()
+	return w.fn(path, d)
+}
+
+// join appends name to dir without cleaning, so "./src" stays "./src/name".
+func join(dir, name string) string {
+	if strings.HasSuffix(dir, "/") {
+		return dir + name
+	}
+	return dir + "/" + name
+}
+
+// Included reports whether Walk would visit path when walking root with opts.
+// It lets incremental consumers apply the same filtering to single changes.
+// Those checking many paths under one root use a Filter instead.
+func Included(root, path string, isDir bool, opts Options) bool {
+	return NewFilter(root, opts).Included(path, isDir)
+}
+
+// Filter is Included for one root, keeping the ignore rules it has read so
+// that checking many paths does not parse the same ignore files again.
+type Filter struct {
+	root string
+	opts Options
+
+	mu       sync.Mutex
+	matchers map[string]*gitignore.Matcher // By directory, holding its own rules
+}
+
+// NewFilter returns a Filter for walking root with opts.
+func NewFilter(root string, opts Options) *Filter {
+	return &Filter{root: root, opts: opts, matchers: map[string]*gitignore.Matcher{}}
+}
+
+// Reset forgets the rules read so far, for when an ignore file changed.
+func (f *Filter) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clear(f.matchers)
+}
+
+// Included reports whether Walk would visit path when walking the root.
+func (f *Filter) Included(path string, isDir bool) bool {
+	rel, err := filepath.Rel(f.root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	if rel == "." {
+		return true
+	}
+	if trash.Within(path) {
+		return false
+	}
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for _, part := range parts {
+		if part == ".git" || (!f.opts.Hidden && strings.HasPrefix(part, ".")) {
+			return false
+		}
+	}
+	if !f.opts.RespectIgnore {
+		return true
+	}
+
+	// Check every ancestor below root, as an ignored directory hides its contents
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	matcher := f.matcher(f.root, nil)
+	dir := f.root
+	for i, part := range parts {
+		dir = filepath.Join(dir, part)
+		last := i == len(parts)-1
+		if matcher.Ignored(dir, !last || isDir) {
+			return false
+		}
+		if !last {
+			matcher = f.matcher(dir, matcher)
+		}
+	}
+	return true
+}
+
+// matcher returns the rules that apply in dir, whose parent's are given.
+// Callers hold mu.
+func (f *Filter) matcher(dir string, parent *gitignore.Matcher) *gitignore.Matcher {
+	if m, ok := f.matchers[dir]; ok {
+		return m
+	}
+	var m *gitignore.Matcher
+	if dir == f.root {
+		m = gitignore.New(f.root)
+	} else {
+		m = parent.Descend(dir)
+	}
+	f.matchers[dir] = m
+	return m
+}
+
+// IgnoreFile reports whether path is an ignore file, whose change calls for
+// a Reset.
+func IgnoreFile(path string) bool {
+	return slices.Contains(gitignore.IgnoreFiles, filepath.Base(path))
+}
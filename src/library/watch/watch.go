@@ -0,0 +1,336 @@
+package watch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"agent-dev-environment/src/library/events"
+	"agent-dev-environment/src/library/gitignore"
+	"agent-dev-environment/src/library/glob"
+	"agent-dev-environment/src/library/logger"
+	"agent-dev-environment/src/library/trash"
+	"agent-dev-environment/src/library/walk"
+)
+
+// Overflow is reported, for the watched root, when the kernel dropped
+// events because they came faster than they were read. Consumers that keep
+// state about the tree should rescan it.
+const Overflow events.Op = "overflow"
+
+// Options controls which changes a Watcher reports.
+type Options struct {
+	// Include, if not empty, limits events to paths matching one of these
+	// globs, relative to the root.
+	Include []string
+	// Exclude drops events for matching paths. Excluded directories are not
+	// watched at all.
+	Exclude []string
+	// RespectIgnore skips entries excluded by .gitignore/.ignore files.
+	// Ignored directories are not watched at all.
+	RespectIgnore bool
+	// Debounce is how long changes are collected before they are reported.
+	// Several changes to one path within it are reported once.
+	Debounce time.Duration
+}
+
+// Event is a change to one path. Changes made by anything, not only the
+// API, are seen.
+type Event struct {
+	Op    events.Op
+	Path  string
+	IsDir bool
+}
+
+// Watcher reports changes below a directory. The ".git" directory and trash
+// bins are never watched. Symlinks are reported but not followed.
+type Watcher struct {
+	root   string
+	opts   Options
+	filter *walk.Filter
+	fsw    *fsnotify.Watcher
+	dirs   map[string]bool // Watched directories
+
+	pending map[string]Event
+	order   []string // Pending paths in the order they first changed
+
+	events    chan []Event
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New starts watching root and everything below it.
+func New(root string, opts Options) (*Watcher, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		root:    abs,
+		opts:    opts,
+		filter:  walk.NewFilter(abs, walk.Options{RespectIgnore: opts.RespectIgnore, Hidden: true}),
+		fsw:     fsw,
+		dirs:    map[string]bool{},
+		pending: map[string]Event{},
+		events:  make(chan []Event),
+		done:    make(chan struct{}),
+	}
+	var matcher *gitignore.Matcher
+	if opts.RespectIgnore {
+		matcher = gitignore.New(abs)
+	}
+	if err := w.addTree(abs, matcher, false); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+// Events delivers the changes collected in each debounce window, oldest
+// first. It is closed when the watcher stops.
+func (w *Watcher) Events() <-chan []Event {
+	return w.events
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.fsw.Close()
+	})
+	return err
+}
+
+func (w *Watcher) run() {
+	defer close(w.events)
+
+	var flush <-chan time.Time
+	for {
+		select {
+		case ev, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			w.handle(ev)
+			if len(w.order) > 0 && flush == nil {
+				flush = time.After(w.opts.Debounce)
+			}
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				w.record(Event{Op: Overflow, Path: w.root, IsDir: true})
+				if flush == nil {
+					flush = time.After(w.opts.Debounce)
+				}
+				continue
+			}
+			logger.Error("Watch failed", "root", w.root, "error", err)
+		case <-flush:
+			flush = nil
+			batch := w.take()
+			if len(batch) == 0 {
+				continue
+			}
+			select {
+			case w.events <- batch:
+			case <-w.done:
+				return
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *Watcher) handle(ev fsnotify.Event) {
+	path := ev.Name
+	if walk.IgnoreFile(path) || ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename) {
+		// Ignore rules may have changed or moved with a directory
+		w.filter.Reset()
+	}
+	switch {
+	case ev.Has(fsnotify.Create):
+		info, err := os.Lstat(path)
+		if err != nil {
+			// Already gone again; nothing observable happened
+			return
+		}
+		if !w.watched(path, info.IsDir()) {
+			return
+		}
+		if w.included(path, info.IsDir()) {
+			w.record(Event{Op: events.Create, Path: path, IsDir: info.IsDir()})
+		}
+		// Directories are watched even when Include leaves them out, as
+		// files matching it may be created inside
+		if info.IsDir() {
+			// Entries created before the watch was added would be missed otherwise
+			if err := w.addTree(path, w.matcher(filepath.Dir(path)), true); err != nil {
+				logger.Error("Failed to watch directory", "path", path, "error", err)
+			}
+		}
+	case ev.Has(fsnotify.Write):
+		if w.included(path, false) {
+			w.record(Event{Op: events.Write, Path: path})
+		}
+	case ev.Has(fsnotify.Remove), ev.Has(fsnotify.Rename):
+		isDir := w.dirs[path]
+		w.forget(path)
+		if !w.included(path, isDir) {
+			return
+		}
+		op := events.Remove
+		if ev.Has(fsnotify.Rename) {
+			op = events.Rename
+		}
+		w.record(Event{Op: op, Path: path, IsDir: isDir})
+	}
+}
+
+// record adds ev to the pending changes, merging it with an earlier change
+// to the same path so only the net effect is reported.
+func (w *Watcher) record(ev Event) {
+	prev, ok := w.pending[ev.Path]
+	if !ok {
+		w.pending[ev.Path] = ev
+		w.order = append(w.order, ev.Path)
+		return
+	}
+
+	switch {
+	case prev.Op == events.Create && ev.Op == events.Write:
+		return
+	case prev.Op == events.Create && (ev.Op == events.Remove || ev.Op == events.Rename):
+		// Created and gone within one window, like an editor's temporary file
+		delete(w.pending, ev.Path)
+		return
+	case ev.Op == events.Create:
+		// Something was there before, as when a file is replaced by a rename
+		ev.Op = events.Write
+	}
+	w.pending[ev.Path] = ev
+}
+
+func (w *Watcher) take() []Event {
+	batch := []Event{}
+	for _, path := range w.order {
+		if ev, ok := w.pending[path]; ok {
+			batch = append(batch, ev)
+			delete(w.pending, path)
+		}
+	}
+	w.order = w.order[:0]
+	return batch
+}
+
+// addTree watches dir and every directory below it that is not ignored or
+// excluded. If created is set, the entries found are recorded as created.
+// matcher holds the ignore rules of dir's parent.
+func (w *Watcher) addTree(dir string, matcher *gitignore.Matcher, created bool) error {
+	if err := w.fsw.Add(dir); err != nil {
+		return err
+	}
+	w.dirs[dir] = true
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		// Removed again already; its remove event follows
+		return nil
+	}
+	if matcher != nil && dir != w.root {
+		matcher = matcher.Descend(dir)
+	}
+
+	for _, entry := range entries {
+		child := filepath.Join(dir, entry.Name())
+		if entry.Name() == ".git" || trash.Within(child) || (matcher != nil && matcher.Ignored(child, entry.IsDir())) {
+			continue
+		}
+		if created && w.included(child, entry.IsDir()) {
+			w.record(Event{Op: events.Create, Path: child, IsDir: entry.IsDir()})
+		}
+		if entry.IsDir() && !w.excluded(child) {
+			if err := w.addTree(child, matcher, created); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// forget stops watching path and the directories below it, which are gone
+// or were moved to where their events would carry stale names.
+func (w *Watcher) forget(path string) {
+	for dir := range w.dirs {
+		if dir == path || strings.HasPrefix(dir, path+string(filepath.Separator)) {
+			w.fsw.Remove(dir)
+			delete(w.dirs, dir)
+		}
+	}
+}
+
+// matcher returns the ignore rules that apply to entries of dir.
+func (w *Watcher) matcher(dir string) *gitignore.Matcher {
+	if !w.opts.RespectIgnore {
+		return nil
+	}
+	m := gitignore.New(w.root)
+	rel, err := filepath.Rel(w.root, dir)
+	if err != nil || rel == "." {
+		return m
+	}
+	current := w.root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		m = m.Descend(current)
+	}
+	return m
+}
+
+// watched reports whether path is neither ignored nor excluded, which
+// decides whether a directory is watched.
+func (w *Watcher) watched(path string, isDir bool) bool {
+	return w.filter.Included(path, isDir) && !w.excluded(path)
+}
+
+// included reports whether changes to path are reported.
+func (w *Watcher) included(path string, isDir bool) bool {
+	if !w.watched(path, isDir) {
+		return false
+	}
+	if len(w.opts.Include) == 0 {
+		return true
+	}
+	rel, _ := filepath.Rel(w.root, path)
+	return glob.MatchAnyPath(w.opts.Include, filepath.ToSlash(rel))
+}
+
+// excluded reports whether path or a directory containing it below the
+// root matches an exclude pattern.
+func (w *Watcher) excluded(path string) bool {
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil || rel == "." || len(w.opts.Exclude) == 0 {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := range parts {
+		if glob.MatchAnyPath(w.opts.Exclude, strings.Join(parts[:i+1], "/")) {
+			return true
+		}
+	}
+	return false
+}
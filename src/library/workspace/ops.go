@@ -0,0 +1,259 @@
+package workspace
+
+import (
+	"errors"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The functions below change the filesystem like their os namesakes, but
+// reach the directory holding each path through the allowed root it lies in,
+// as OpenFile does. A symlink swapped in after Check cannot lead them outside
+// that root, and the last component is acted on itself, never followed.
+
+// exempt are directories the server keeps for itself, such as the trash,
+// whose paths are used as they are.
+var exempt []string
+
+// Exempt lets the functions below use paths inside dir as they are, even
+// outside every allowed root. It is meant for directories the server keeps
+// for itself; request paths must be kept out of them by the caller.
+func Exempt(dir string) {
+	if abs, err := filepath.Abs(dir); err == nil {
+		exempt = append(exempt, abs)
+	}
+}
+
+// Remove is os.Remove confined to the root holding path.
+func Remove(path string) error {
+	r, name, err := openParent(path)
+	if err != nil {
+		return pathError("remove", path, err)
+	}
+	defer r.Close()
+	return pathError("remove", path, r.Remove(name))
+}
+
+// RemoveAll is os.RemoveAll confined to the root holding path.
+func RemoveAll(path string) error {
+	r, name, err := openParent(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return pathError("removeall", path, err)
+	}
+	defer r.Close()
+	return pathError("removeall", path, r.RemoveAll(name))
+}
+
+// Mkdir is os.Mkdir confined to the root holding path.
+func Mkdir(path string, perm fs.FileMode) error {
+	r, name, err := openParent(path)
+	if err != nil {
+		return pathError("mkdir", path, err)
+	}
+	defer r.Close()
+	return pathError("mkdir", path, r.Mkdir(name, perm))
+}
+
+// MkdirAll is os.MkdirAll confined to the root holding path.
+func MkdirAll(path string, perm fs.FileMode) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if len(roots) == 0 || isExempt(abs) {
+		return os.MkdirAll(abs, perm)
+	}
+	r, rel, err := openRoot(abs)
+	if err != nil {
+		return pathError("mkdir", path, err)
+	}
+	defer r.Close()
+	return pathError("mkdir", path, r.MkdirAll(rel, perm))
+}
+
+// Symlink is os.Symlink confined to the root holding path. The target is
+// stored as given and may lead anywhere.
+func Symlink(target, path string) error {
+	r, name, err := openParent(path)
+	if err != nil {
+		return &os.LinkError{Op: "symlink", Old: target, New: path, Err: unwrap(err)}
+	}
+	defer r.Close()
+	if err := r.Symlink(target, name); err != nil {
+		return &os.LinkError{Op: "symlink", Old: target, New: path, Err: unwrap(err)}
+	}
+	return nil
+}
+
+// Chmod is os.Chmod confined to the root holding path.
+func Chmod(path string, mode fs.FileMode) error {
+	r, name, err := openParent(path)
+	if err != nil {
+		return pathError("chmod", path, err)
+	}
+	defer r.Close()
+	return pathError("chmod", path, r.Chmod(name, mode))
+}
+
+// Chtimes is os.Chtimes confined to the root holding path.
+func Chtimes(path string, atime, mtime time.Time) error {
+	r, name, err := openParent(path)
+	if err != nil {
+		return pathError("chtimes", path, err)
+	}
+	defer r.Close()
+	return pathError("chtimes", path, r.Chtimes(name, atime, mtime))
+}
+
+// Rename is os.Rename confined to the roots holding oldpath and newpath,
+// which may differ.
+func Rename(oldpath, newpath string) error {
+	return rename(oldpath, newpath, false)
+}
+
+// RenameNoReplace is Rename failing with an error satisfying
+// errors.Is(err, fs.ErrExist) if newpath already exists.
+func RenameNoReplace(oldpath, newpath string) error {
+	return rename(oldpath, newpath, true)
+}
+
+func rename(oldpath, newpath string, noReplace bool) error {
+	oldDir, oldName, err := openParentDir(oldpath)
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: unwrap(err)}
+	}
+	defer oldDir.Close()
+	newDir, newName, err := openParentDir(newpath)
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: unwrap(err)}
+	}
+	defer newDir.Close()
+
+	if err := renameat(oldDir, oldName, newDir, newName, noReplace); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+	return nil
+}
+
+// CreateTemp is os.CreateTemp confined to the root holding dir. The file's
+// Name is dir once its symlinks are resolved, joined with the new name.
+func CreateTemp(dir, pattern string) (*os.File, error) {
+	prefix, suffix, _ := strings.Cut(pattern, "*")
+	r, err := openDir(dir)
+	if err != nil {
+		return nil, pathError("createtemp", dir, err)
+	}
+	defer r.Close()
+	for range 10000 {
+		name := prefix + strconv.FormatUint(uint64(rand.Uint32()), 10) + suffix
+		f, err := r.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, pathError("createtemp", filepath.Join(dir, name), err)
+		}
+		return f, nil
+	}
+	return nil, &fs.PathError{Op: "createtemp", Path: filepath.Join(dir, pattern), Err: fs.ErrExist}
+}
+
+// openParent opens the directory holding path and returns path's last
+// component, for the caller to act on without following it.
+func openParent(path string) (*os.Root, string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, "", err
+	}
+	dir, name := filepath.Split(abs)
+	r, err := openDir(dir)
+	return r, name, err
+}
+
+// openParentDir is openParent returning the directory as a file, for the
+// *at system calls os.Root has no counterpart for.
+func openParentDir(path string) (*os.File, string, error) {
+	r, name, err := openParent(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Close()
+	dir, err := r.Open(".")
+	return dir, name, err
+}
+
+// openDir opens dir through the allowed root it lies in, or as it is when
+// paths are unconfined or dir is exempt.
+func openDir(dir string) (*os.Root, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 || isExempt(abs) {
+		return os.OpenRoot(abs)
+	}
+	r, rel, err := openRoot(abs)
+	if err != nil || rel == "." {
+		return r, err
+	}
+	defer r.Close()
+	return r.OpenRoot(rel)
+}
+
+// openRoot opens the allowed root that path resolves into, and returns
+// path's resolved location relative to it. Like OpenFile, symlinks are
+// resolved here because the root refuses absolute ones.
+func openRoot(path string) (*os.Root, string, error) {
+	resolved, err := Resolve(path)
+	if err != nil {
+		return nil, "", err
+	}
+	root := rootOf(resolved)
+	if root == "" {
+		return nil, "", ErrOutside
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil {
+		return nil, "", err
+	}
+	r, err := os.OpenRoot(root)
+	return r, rel, err
+}
+
+func isExempt(path string) bool {
+	for _, dir := range exempt {
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathError reports err against the path the caller gave rather than the
+// name used within the root.
+func pathError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &fs.PathError{Op: op, Path: path, Err: unwrap(err)}
+}
+
+func unwrap(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	var linkErr *os.LinkError
+	if errors.As(err, &linkErr) {
+		return linkErr.Err
+	}
+	return err
+}
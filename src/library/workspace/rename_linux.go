@@ -0,0 +1,30 @@
+//go:build linux
+
+package workspace
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// renameat renames oldName in oldDir to newName in newDir, atomically
+// refusing to replace an existing newName when noReplace is set.
+func renameat(oldDir *os.File, oldName string, newDir *os.File, newName string, noReplace bool) error {
+	oldFd, newFd := int(oldDir.Fd()), int(newDir.Fd())
+	if !noReplace {
+		return unix.Renameat(oldFd, oldName, newFd, newName)
+	}
+	err := unix.Renameat2(oldFd, oldName, newFd, newName, unix.RENAME_NOREPLACE)
+	if errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EINVAL) {
+		// Old kernels and some filesystems lack the flag; a name created
+		// between the check and the rename is replaced
+		var st unix.Stat_t
+		if unix.Fstatat(newFd, newName, &st, unix.AT_SYMLINK_NOFOLLOW) == nil {
+			return unix.EEXIST
+		}
+		return unix.Renameat(oldFd, oldName, newFd, newName)
+	}
+	return err
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package workspace
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// renameat renames oldName in oldDir to newName in newDir, refusing to
+// replace an existing newName when noReplace is set. The directories are
+// named again on this platform, so neither step is safe from a swap.
+func renameat(oldDir *os.File, oldName string, newDir *os.File, newName string, noReplace bool) error {
+	oldpath, newpath := filepath.Join(oldDir.Name(), oldName), filepath.Join(newDir.Name(), newName)
+	if noReplace {
+		if _, err := os.Lstat(newpath); err == nil {
+			return fs.ErrExist
+		}
+	}
+	return unwrap(os.Rename(oldpath, newpath))
+}
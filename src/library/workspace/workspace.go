@@ -0,0 +1,218 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// maxSymlinks bounds how many symlinks are followed while resolving a path,
+// like the kernel's ELOOP limit.
+const maxSymlinks = 40
+
+// ErrOutside is returned for paths that resolve outside every allowed root.
+var ErrOutside = errors.New("path is outside the workspace")
+
+// roots are the resolved allowed directories. Empty disables confinement.
+var roots []string
+
+// Init confines path arguments to root and the comma-separated extra roots,
+// and moves into root unless the working directory is already allowed. An
+// empty root leaves paths unconfined.
+func Init(root, extra string) {
+	roots = nil
+	if root == "" {
+		return
+	}
+
+	all := []string{root}
+	for _, r := range strings.Split(extra, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			all = append(all, r)
+		}
+	}
+	for _, r := range all {
+		resolved, err := Resolve(r)
+		if err != nil {
+			panic(fmt.Sprintf("invalid workspace root %q: %v", r, err))
+		}
+		if info, err := os.Stat(resolved); err != nil || !info.IsDir() {
+			panic(fmt.Sprintf("invalid workspace root %q: must be an existing directory", r))
+		}
+		roots = append(roots, resolved)
+	}
+
+	// Relative paths resolve against the working directory, so start inside
+	if cwd, err := os.Getwd(); err == nil && Check(cwd) != nil {
+		if err := os.Chdir(roots[0]); err != nil {
+			panic(fmt.Sprintf("could not enter workspace root: %v", err))
+		}
+	}
+}
+
+// Allow adds dir, which must exist, to the allowed roots. It has no effect
+// when paths are unconfined.
+func Allow(dir string) error {
+	if len(roots) == 0 {
+		return nil
+	}
+	resolved, err := Resolve(dir)
+	if err != nil {
+		return err
+	}
+	roots = append(roots, resolved)
+	return nil
+}
+
+// Roots returns the allowed roots, or nil when paths are unconfined.
+func Roots() []string {
+	return slices.Clone(roots)
+}
+
+// Check returns ErrOutside unless path lies within an allowed root once
+// every symlink and ".." in it has been resolved. Missing trailing
+// components are allowed, so paths about to be created can be checked.
+//
+// The answer holds only until a symlink along the path is swapped; opening
+// through OpenFile instead cannot be redirected outside the root.
+func Check(path string) error {
+	if len(roots) == 0 {
+		return nil
+	}
+	resolved, err := Resolve(path)
+	if err != nil {
+		return ErrOutside
+	}
+	if rootOf(resolved) == "" {
+		return ErrOutside
+	}
+	return nil
+}
+
+// CheckLink is Check for operations on path itself rather than what it
+// leads to, like lstat: the directory holding path must lie within an
+// allowed root, but a symlink at path may point anywhere.
+func CheckLink(path string) error {
+	if len(roots) == 0 {
+		return nil
+	}
+	dir, name := filepath.Split(path)
+	if name == "" || name == "." || name == ".." {
+		return Check(path)
+	}
+	if dir == "" {
+		dir = "."
+	}
+	resolved, err := Resolve(dir)
+	if err != nil {
+		return ErrOutside
+	}
+	if rootOf(filepath.Join(resolved, name)) == "" {
+		return ErrOutside
+	}
+	return nil
+}
+
+// OpenFile is os.OpenFile confined to the allowed root holding path. Each
+// component is opened relative to the one before, so a symlink swapped in
+// after Check cannot lead outside that root.
+func OpenFile(path string, flag int, perm fs.FileMode) (*os.File, error) {
+	if len(roots) == 0 {
+		return os.OpenFile(path, flag, perm)
+	}
+
+	// The root refuses absolute symlinks even when they stay inside, so
+	// links are resolved here and the root only guards against a swap.
+	// O_EXCL never follows the last component, so it is left as is.
+	dir, name := path, "."
+	if flag&os.O_EXCL != 0 {
+		dir, name = filepath.Split(path)
+		if dir == "" {
+			dir = "."
+		}
+	}
+	resolved, err := Resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+	root := rootOf(resolved)
+	if root == "" {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: ErrOutside}
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := os.OpenRoot(root)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return r.OpenFile(filepath.Join(rel, name), flag, perm)
+}
+
+// rootOf returns the allowed root that resolved lies within, or "".
+func rootOf(resolved string) string {
+	for _, root := range roots {
+		if resolved == root || strings.HasPrefix(resolved, root+string(filepath.Separator)) || root == string(filepath.Separator) {
+			return root
+		}
+	}
+	return ""
+}
+
+// Resolve returns the absolute path that path refers to. Unlike
+// filepath.Clean, ".." is applied after the symlink before it is followed,
+// so "link/.." means the parent of the link's target.
+func Resolve(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		path = cwd + string(filepath.Separator) + path
+	}
+
+	resolved := string(filepath.Separator)
+	pending := strings.Split(path, string(filepath.Separator))
+	followed := 0
+	for len(pending) > 0 {
+		part := pending[0]
+		pending = pending[1:]
+
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+
+		next := filepath.Join(resolved, part)
+		info, err := os.Lstat(next)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			// Missing components will be created as plain directories or files
+			resolved = next
+			continue
+		}
+
+		followed++
+		if followed > maxSymlinks {
+			return "", errors.New("too many levels of symbolic links")
+		}
+		target, err := os.Readlink(next)
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) {
+			resolved = string(filepath.Separator)
+		}
+		pending = append(strings.Split(target, string(filepath.Separator)), pending...)
+	}
+	return resolved, nil
+}
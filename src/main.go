@@ -5,24 +5,65 @@ import (
 	"agent-dev-environment/src/features/filesystem/create_file"
 	"agent-dev-environment/src/features/filesystem/delete"
 	"agent-dev-environment/src/features/filesystem/ls"
+	"agent-dev-environment/src/features/filesystem/archive"
+	"agent-dev-environment/src/features/filesystem/batch"
+	"agent-dev-environment/src/features/filesystem/changes"
 	"agent-dev-environment/src/features/filesystem/chdir"
+	"agent-dev-environment/src/features/filesystem/codemod"
+	"agent-dev-environment/src/features/filesystem/copy"
 	"agent-dev-environment/src/features/filesystem/getwd"
+	"agent-dev-environment/src/features/filesystem/link"
 	"agent-dev-environment/src/features/filesystem/mkdir"
 	"agent-dev-environment/src/features/filesystem/move"
+	"agent-dev-environment/src/features/filesystem/raw"
 	"agent-dev-environment/src/features/filesystem/read"
 	"agent-dev-environment/src/features/filesystem/replace"
 	"agent-dev-environment/src/features/filesystem/search"
+	"agent-dev-environment/src/features/filesystem/search_index"
+	"agent-dev-environment/src/features/filesystem/trash"
+	"agent-dev-environment/src/features/filesystem/tree"
+	"agent-dev-environment/src/features/filesystem/usage"
+	"agent-dev-environment/src/features/filesystem/watch"
+	"agent-dev-environment/src/features/journal"
+	"agent-dev-environment/src/features/project/overview"
 	"agent-dev-environment/src/features/shell/reload_env"
+	"agent-dev-environment/src/features/scratch"
+	"agent-dev-environment/src/features/session/end"
 	"agent-dev-environment/src/features/shell/run"
+	"agent-dev-environment/src/features/snapshots"
+	symbols_search "agent-dev-environment/src/features/symbols/search"
 	"agent-dev-environment/src/library/api"
+	changes_store "agent-dev-environment/src/library/changes"
+	archive_store "agent-dev-environment/src/library/archive"
 	"agent-dev-environment/src/library/config"
+	journal_store "agent-dev-environment/src/library/journal"
 	"agent-dev-environment/src/library/logger"
+	"agent-dev-environment/src/library/policy"
+	"agent-dev-environment/src/library/quota"
+	scratch_store "agent-dev-environment/src/library/scratch"
+	"agent-dev-environment/src/library/session"
+	"agent-dev-environment/src/library/snapshot"
+	trash_store "agent-dev-environment/src/library/trash"
+	"agent-dev-environment/src/library/trigram"
+	"agent-dev-environment/src/library/workspace"
 	"net/http"
 )
 
 func main() {
 	logFormat := config.GetValue("LOGGING_TYPE")
 	logger.Init(logFormat)
+	workspace.Init(config.GetValueOrDefault("WORKSPACE_ROOT", ""), config.GetValueOrDefault("ALLOWED_ROOTS", ""))
+	quota.Init(config.GetValueOrDefault("WORKSPACE_ROOT", "."), config.DataDir(), config.GetValueOrDefault("QUOTA_MAX_BYTES", "0"), config.GetValueOrDefault("QUOTA_MAX_FILE_BYTES", "0"), config.GetValueOrDefault("QUOTA_MAX_FILES", "0"))
+	session.Init(config.GetValueOrDefault("SESSION_IDLE_TIMEOUT", "24h"), config.GetValueOrDefault("SESSION_MAX_COUNT", "1000"))
+	policy.Init(config.GetValueOrDefault("PROTECTED_PATHS", ".git/hooks/**=readonly,.git/config=readonly,.github/workflows/**=readonly"))
+	search.Init(config.GetValueOrDefault("SEARCH_ENGINE", "auto"))
+	trash_store.Init(config.DataDir(), workspace.Roots(), config.GetValueOrDefault("TRASH_MAX_BYTES", "1073741824"), config.GetValueOrDefault("TRASH_MAX_AGE", "168h"))
+	journal_store.Init(config.DataDir(), config.GetValueOrDefault("JOURNAL_MAX_ENTRIES", "1000"))
+	archive_store.Init(config.DataDir(), config.GetValueOrDefault("ARCHIVE_MAX_BYTES", "1073741824"))
+	snapshot.Init(config.DataDir(), config.GetValueOrDefault("SNAPSHOT_MAX_COUNT", "100"))
+	changes_store.Init(config.DataDir(), config.GetValueOrDefault("CHANGES_MAX_CURSORS", "100"))
+	scratch_store.Init(config.DataDir())
+	trigram.Init(config.GetValueOrDefault("SEARCH_INDEX", "off"), config.GetValueOrDefault("SEARCH_INDEX_ROOT", "."))
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /health", healthHandler)
@@ -30,16 +71,46 @@ func main() {
 	mux.HandleFunc("POST /api/v1/filesystem/create_file", api.WrappedHandler(create_file.Handler))
 	mux.HandleFunc("POST /api/v1/filesystem/mkdir", api.WrappedHandler(mkdir.Handler))
 	mux.HandleFunc("POST /api/v1/filesystem/delete", api.WrappedHandler(delete.Handler))
+	mux.HandleFunc("POST /api/v1/filesystem/trash/list", api.WrappedHandler(trash.ListHandler))
+	mux.HandleFunc("POST /api/v1/filesystem/trash/restore", api.WrappedHandler(trash.RestoreHandler))
+	mux.HandleFunc("POST /api/v1/filesystem/trash/purge", api.WrappedHandler(trash.PurgeHandler))
 	mux.HandleFunc("POST /api/v1/filesystem/move", api.WrappedHandler(move.Handler))
+	mux.HandleFunc("POST /api/v1/filesystem/copy", api.WrappedHandler(copy.Handler))
 	mux.HandleFunc("POST /api/v1/filesystem/ls", api.WrappedHandler(ls.Handler))
+	mux.HandleFunc("GET /api/v1/filesystem/archive/export", api.WrappedStreamHandler(archive.ExportHandler))
+	mux.HandleFunc("POST /api/v1/filesystem/archive/import", api.WrappedStreamHandler(archive.ImportHandler))
+	mux.HandleFunc("GET /api/v1/filesystem/raw", api.WrappedStreamHandler(raw.DownloadHandler))
+	mux.HandleFunc("PUT /api/v1/filesystem/raw", api.WrappedStreamHandler(raw.UploadHandler))
+	mux.HandleFunc("POST /api/v1/filesystem/changes", api.WrappedHandler(changes.Handler))
+	mux.HandleFunc("GET /api/v1/filesystem/watch", api.WrappedStreamHandler(watch.Handler))
+	mux.HandleFunc("POST /api/v1/filesystem/batch", api.WrappedHandler(batch.Handler))
+	mux.HandleFunc("POST /api/v1/filesystem/link/create", api.WrappedHandler(link.CreateHandler))
+	mux.HandleFunc("POST /api/v1/filesystem/link/read", api.WrappedHandler(link.ReadHandler))
 	mux.HandleFunc("POST /api/v1/filesystem/chdir", api.WrappedHandler(chdir.Handler))
 	mux.HandleFunc("POST /api/v1/filesystem/getwd", api.WrappedHandler(getwd.Handler))
+	mux.HandleFunc("POST /api/v1/filesystem/usage", api.WrappedHandler(usage.Handler))
 	mux.HandleFunc("POST /api/v1/filesystem/search", api.WrappedHandler(search.Handler))
+	mux.HandleFunc("POST /api/v1/filesystem/search_index/status", api.WrappedHandler(search_index.StatusHandler))
 	mux.HandleFunc("POST /api/v1/filesystem/replace", api.WrappedHandler(replace.Handler))
+	mux.HandleFunc("POST /api/v1/filesystem/tree", api.WrappedHandler(tree.Handler))
+	mux.HandleFunc("POST /api/v1/filesystem/codemod/preview", api.WrappedHandler(codemod.PreviewHandler))
+	mux.HandleFunc("POST /api/v1/filesystem/codemod/apply", api.WrappedHandler(codemod.ApplyHandler))
+	mux.HandleFunc("POST /api/v1/symbols/search", api.WrappedHandler(symbols_search.Handler))
+	mux.HandleFunc("POST /api/v1/project/overview", api.WrappedHandler(overview.Handler))
+	mux.HandleFunc("POST /api/v1/journal/list", api.WrappedHandler(journal.ListHandler))
+	mux.HandleFunc("POST /api/v1/journal/undo", api.WrappedHandler(journal.UndoHandler))
+	mux.HandleFunc("POST /api/v1/snapshots/create", api.WrappedHandler(snapshots.CreateHandler))
+	mux.HandleFunc("POST /api/v1/snapshots/list", api.WrappedHandler(snapshots.ListHandler))
+	mux.HandleFunc("POST /api/v1/snapshots/restore", api.WrappedHandler(snapshots.RestoreHandler))
+	mux.HandleFunc("POST /api/v1/snapshots/delete", api.WrappedHandler(snapshots.DeleteHandler))
+	mux.HandleFunc("POST /api/v1/scratch/create", api.WrappedHandler(scratch.CreateHandler))
+	mux.HandleFunc("POST /api/v1/scratch/list", api.WrappedHandler(scratch.ListHandler))
+	mux.HandleFunc("POST /api/v1/scratch/delete", api.WrappedHandler(scratch.DeleteHandler))
+	mux.HandleFunc("POST /api/v1/session/end", api.WrappedHandler(end.Handler))
 	mux.HandleFunc("POST /api/v1/shell/reload_env", api.WrappedHandler(reload_env.Handler))
 	mux.HandleFunc("POST /api/v1/shell/run", api.WrappedHandler(run.Handler))
 
-	handler := middleware.PanicRecovery(mux)
+	handler := middleware.PanicRecovery(middleware.RequestID(middleware.Session(middleware.ApprovedRules(mux))))
 
 	port := "8080"
 	logger.Printf("Starting server on port %s...", port)
@@ -47,3 +118,4 @@ func main() {
 		logger.Fatalf("Server failed: %v", err)
 	}
 }
+